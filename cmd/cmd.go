@@ -3,35 +3,142 @@ package cmd
 import (
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	"cas.mod/errorlog"
 	"cas.mod/internal/app"
+	"cas.mod/internal/i18n"
 	"golang.org/x/text/encoding/simplifiedchinese"
 	"golang.org/x/text/transform"
 )
 
-func generateChemicalURL(cas string) string {
-	return fmt.Sprintf("http://www.ichemistry.cn/chemistry/" + cas + ".htm")
+// 各数据源的默认地址
+const (
+	defaultChemicalBaseURL = app.DefaultChemicalBaseURL
+	defaultDensityBaseURL  = "https://www.chemsrc.com"
+)
+
+func generateChemicalURL(baseURL, cas string) string {
+	return fmt.Sprintf("%s/chemistry/%s.htm", strings.TrimSuffix(baseURL, "/"), cas)
 }
 
-func generatedensityURL(cas string) string {
+func generatedensityURL(baseURL, cas string) string {
 	// https://www.chemsrc.com/cas/343952-33-0_1186924.html
-	return fmt.Sprintf("https://www.chemsrc.com/cas/" + cas + ".html")
+	return fmt.Sprintf("%s/cas/%s.html", strings.TrimSuffix(baseURL, "/"), cas)
+}
+
+// httpClient 返回运行参数中的客户端，未指定时使用 http.DefaultClient，响应状态码计入监控指标
+func httpClient(opts Options) *http.Client {
+	return app.InstrumentClient(opts.Client)
+}
+
+// newResultWriter 根据运行参数创建写回目标，预演模式下只输出差异
+func newResultWriter(opts Options) (app.ResultWriter, error) {
+	if !opts.DryRun {
+		return newExcelWriter(opts)
+	}
+
+	if opts.DiffOut == "" || opts.DiffOut == "-" {
+		dw := app.NewDiffWriter(opts.FilePath, os.Stdout)
+		dw.Force = opts.Force
+		return dw, nil
+	}
+
+	out, err := os.Create(opts.DiffOut)
+	if err != nil {
+		return nil, i18n.Errorf("创建差异文件失败: %v", err)
+	}
+	dw := app.NewDiffWriter(opts.FilePath, out)
+	dw.Force = opts.Force
+	return &diffFile{DiffWriter: dw, file: out}, nil
+}
+
+// closeWriter 关闭写回目标，Excel 文件在此时保存
+func closeWriter(writer app.ResultWriter) {
+	if err := writer.Close(); err != nil {
+		slog.Error("保存文件失败", "err", err)
+	}
+}
+
+// reportConflicts 汇总因单元格已有值而跳过的写入
+func reportConflicts(writer app.ResultWriter, opts Options) {
+	cw, ok := writer.(interface{ Conflicts() []app.CellUpdate })
+	if !ok || len(cw.Conflicts()) == 0 {
+		return
+	}
+
+	conflicts := cw.Conflicts()
+	slog.Warn("单元格已有值，未覆盖 (使用 --force 强制覆盖)", "count", len(conflicts))
+	if err := app.SaveConflicts(conflicts, opts.ConflictOut); err != nil {
+		slog.Error("保存冲突报告失败", "file", opts.ConflictOut, "err", err)
+		return
+	}
+	slog.Info("冲突报告已保存", "file", opts.ConflictOut)
+}
+
+// newExcelWriter 写入副本时先复制原文件，写回原文件时先做带时间戳的备份
+func newExcelWriter(opts Options) (*app.ExcelWriter, error) {
+	if opts.Output != "" && opts.Output != opts.FilePath {
+		if err := app.CopyFile(opts.FilePath, opts.Output); err != nil {
+			return nil, i18n.Errorf("创建输出文件失败: %v", err)
+		}
+		slog.Info("结果将写入副本", "file", opts.Output)
+		return &app.ExcelWriter{FilePath: opts.Output, Provenance: opts.Provenance, Force: opts.Force}, nil
+	}
+
+	backupPath, err := app.BackupFile(opts.FilePath, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("已备份原文件", "file", backupPath)
+	return &app.ExcelWriter{FilePath: opts.FilePath, Provenance: opts.Provenance, Force: opts.Force}, nil
+}
+
+// diffFile 输出到文件的差异写入器，关闭时一并关闭文件
+type diffFile struct {
+	*app.DiffWriter
+	file *os.File
+}
+
+func (d *diffFile) Close() error {
+	if err := d.DiffWriter.Close(); err != nil {
+		d.file.Close()
+		return err
+	}
+	return d.file.Close()
 }
 
 // ChemicalRun 化学式查询
-func ChemicalRun() {
-	filePath := "./docs/ReagentModules.xlsx"
-	rowNumberAndCas := app.ParseExcel(filePath)
-	var notExist int
+func ChemicalRun(opts Options) {
+	rowNumberAndCas := app.ParseExcel(opts.FilePath)
 
-	for number, cas := range rowNumberAndCas {
-		url := generateChemicalURL(cas)
+	writer, err := newResultWriter(opts)
+	if err != nil {
+		fatal("创建写回目标失败", "err", err)
+	}
+	defer closeWriter(writer)
+	if opts.DryRun {
+		slog.Info("预演模式: 不会修改Excel文件")
+	}
+
+	db := localDB(opts)
+	// fetch 查询并写入一行，写入成功时返回 true
+	fetch := func(number int, cas string) bool {
+		// 本地试剂库中已有化学式时不再请求网站
+		if info, ok := db.Get(cas); ok && info.ChemicalFormula != "" {
+			return writeLocal(writer, number, app.FieldFormula, info.ChemicalFormula, info)
+		}
+
+		url := generateChemicalURL(opts.ChemicalBaseURL, cas)
+		logger := slog.With("row", number, "cas", cas, "url", url)
 		req, err := http.NewRequest("GET", url, nil)
 		if err != nil {
-			log.Println(err)
+			logger.Error("创建请求失败", "err", err)
+			return false
 		}
 
 		// 设置请求头
@@ -40,21 +147,20 @@ func ChemicalRun() {
 		req.Header.Set("Accept-Language", "zh-CN,zh;q=0.8,en-US;q=0.5,en;q=0.3")
 		req.Header.Set("Connection", "keep-alive")
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := httpClient(opts).Do(req)
 		if err != nil {
-			log.Println("请求失败: ", err)
+			logger.Warn("请求失败", "err", err)
+			return false
 		}
 		defer resp.Body.Close()
 
 		// 检查状态码
 		if resp.StatusCode != http.StatusOK {
-			log.Printf("状态码错误: %d %s", resp.StatusCode, resp.Status)
-			notExist++
-			log.Println("404次数:", notExist)
+			logger.Warn("状态码错误", "status", resp.StatusCode)
 			if err := errorlog.LogError(resp.StatusCode, url); err != nil {
-				log.Println("写入URL到文件失败!")
+				logger.Error("写入错误日志失败", "err", err)
 			}
-			continue
+			return false
 		}
 
 		// 创建GBK解码器Reader
@@ -63,27 +169,51 @@ func ChemicalRun() {
 		// 读取解码后的内容
 		body, err := io.ReadAll(reader)
 		if err != nil {
-			log.Fatal("读取响应失败: ", err)
+			logger.Error("读取响应失败", "err", err)
+			return false
 		}
 
 		// 解析HTML
-		app.ParseChemical(string(body), 1, number)
+		return app.ParseChemical(string(body), number, writer, url)
+	}
+
+	tracker, finish := trackProgress(len(rowNumberAndCas), opts)
+	for number, cas := range rowNumberAndCas {
+		tracker.Add(fetch(number, cas))
 	}
+	finish()
+
+	reportConflicts(writer, opts)
 }
 
-// DensityRun 密度查询
-func DensityRun() {
-	filePath := "ReagentModules.xlsx"
+// DensityRun 密度查询，只处理相对密度为空的行
+func DensityRun(opts Options) {
+	processor := &app.ExcelProcessor{FilePath: opts.FilePath}
+	rowNumberAndCas, err := processor.GetCASByEmptyColumn(app.ColumnDensity)
+	if err != nil {
+		fatal("处理失败", "file", opts.FilePath, "err", err)
+	}
+
+	writer, err := newResultWriter(opts)
+	if err != nil {
+		fatal("创建写回目标失败", "err", err)
+	}
+	defer closeWriter(writer)
 
-	rowNumberAndCas := app.ParseExcel(filePath)
+	db := localDB(opts)
+	// fetch 查询并写入一行，写入成功时返回 true
+	fetch := func(number int, cas string) bool {
+		if info, ok := db.Get(cas); ok && info.Density != "" {
+			return writeLocal(writer, number, app.ColumnDensity, info.Density, info)
+		}
 
-	for _, cas := range rowNumberAndCas {
-		url := generateChemicalURL(cas)
+		url := generatedensityURL(opts.DensityBaseURL, cas)
+		logger := slog.With("row", number, "cas", cas, "url", url)
 		req, err := http.NewRequest("GET", url, nil)
 		if err != nil {
-			log.Println(err)
+			logger.Error("创建请求失败", "err", err)
+			return false
 		}
-		log.Println(generatedensityURL(cas))
 
 		// 设置请求头
 		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
@@ -91,31 +221,38 @@ func DensityRun() {
 		req.Header.Set("Accept-Language", "zh-CN,zh;q=0.8,en-US;q=0.5,en;q=0.3")
 		req.Header.Set("Connection", "keep-alive")
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := httpClient(opts).Do(req)
 		if err != nil {
-			log.Println("请求失败: ", err)
+			logger.Warn("请求失败", "err", err)
+			return false
 		}
 		defer resp.Body.Close()
 
 		// 检查状态码
 		if resp.StatusCode != http.StatusOK {
-			log.Printf("状态码错误: %d %s", resp.StatusCode, resp.Status)
-
+			logger.Warn("状态码错误", "status", resp.StatusCode)
 			if err := errorlog.LogError(resp.StatusCode, url); err != nil {
-				log.Println("写入URL到文件失败!")
+				logger.Error("写入错误日志失败", "err", err)
 			}
-			continue
+			return false
 		}
-		// 创建GBK解码器Reader
-		reader := transform.NewReader(resp.Body, simplifiedchinese.GBK.NewDecoder())
 
-		// 读取解码后的内容
-		body, err := io.ReadAll(reader)
+		// chemsrc 页面为 UTF-8 编码
+		body, err := io.ReadAll(resp.Body)
 		if err != nil {
-			log.Fatal("读取响应失败: ", err)
+			logger.Error("读取响应失败", "err", err)
+			return false
 		}
 
 		// 解析HTML
-		app.Density(string(body))
+		return app.ParseDensity(string(body), number, writer, url)
+	}
+
+	tracker, finish := trackProgress(len(rowNumberAndCas), opts)
+	for number, cas := range rowNumberAndCas {
+		tracker.Add(fetch(number, cas))
 	}
+	finish()
+
+	reportConflicts(writer, opts)
 }
@@ -0,0 +1,933 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cas.mod/internal/app"
+	"cas.mod/internal/standin"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/language"
+)
+
+// header 与 docs/ReagentModules_simple.xlsx 相同的表头
+var header = []interface{}{
+	"常用名称", "CAS号", "批号", "规格", "纯度", "相对密度(水=1)", "生产商", "供应商", "单价", "过期时间",
+	"储存条件", "用法", "标签", "类别", "别名", "英文名", "化学式", "物化性质", "禁配物", "预防措施",
+	"危险特性", "灭火方式", "事故响应", "安全存储", "废弃处置", "结构图片", "msds链接",
+}
+
+// fakeSite 在本地替身的基础上增加 503 和乱码页面
+func fakeSite() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/", standin.Handler())
+	mux.HandleFunc("GET /chemistry/55555-55-5.htm", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("GET /chemistry/11111-11-1.htm", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte{0x3c, 0x74, 0x61, 0x62, 0xff, 0xfe, 0x81, 0x00, 0x9f, 0x3e, 0x3c})
+	})
+	return httptest.NewServer(mux)
+}
+
+// newWorkbook 在临时目录中创建小型试剂表，rows 为 {CAS号, 化学式}
+func newWorkbook(t *testing.T, rows [][2]string) string {
+	t.Helper()
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetRow("Sheet1", "A1", &header); err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range rows {
+		cell, _ := excelize.CoordinatesToCellName(2, i+2)
+		if err := f.SetSheetRow("Sheet1", cell, &[]interface{}{r[0]}); err != nil {
+			t.Fatal(err)
+		}
+		cell, _ = excelize.CoordinatesToCellName(17, i+2)
+		if err := f.SetCellValue("Sheet1", cell, r[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "ReagentModules.xlsx")
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestChemicalRun(t *testing.T) {
+	server := fakeSite()
+	defer server.Close()
+
+	path := newWorkbook(t, [][2]string{
+		{"7664-93-9", ""},    // 200，写入
+		{"1002-16-0", ""},    // 200，写入
+		{"10049-21-5", ""},   // 200，页面中没有分子式
+		{"99999-99-9", ""},   // 404
+		{"55555-55-5", ""},   // 503
+		{"11111-11-1", ""},   // 乱码
+		{"64-17-5", "C2H6O"}, // 已有化学式，不查询
+		{"7664-93-9", "待补充"}, // 视为空值，写入
+	})
+
+	opts := Options{
+		FilePath:        path,
+		ConflictOut:     filepath.Join(t.TempDir(), "conflicts.csv"),
+		ChemicalBaseURL: server.URL,
+		Client:          server.Client(),
+	}
+	ChemicalRun(opts)
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	want := []string{"H2SO4", "C5H11NO3", "", "", "", "", "C2H6O", "H2SO4"}
+	for i, w := range want {
+		cell, _ := excelize.CoordinatesToCellName(17, i+2)
+		got, err := f.GetCellValue("Sheet1", cell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != w {
+			t.Errorf("%s = %q, want %q", cell, got, w)
+		}
+	}
+}
+
+func TestChemicalRunDryRun(t *testing.T) {
+	server := fakeSite()
+	defer server.Close()
+
+	path := newWorkbook(t, [][2]string{{"7664-93-9", ""}})
+	diffOut := filepath.Join(t.TempDir(), "diff.csv")
+
+	opts := Options{
+		FilePath:        path,
+		DryRun:          true,
+		DiffOut:         diffOut,
+		ConflictOut:     filepath.Join(t.TempDir(), "conflicts.csv"),
+		ChemicalBaseURL: server.URL,
+		Client:          server.Client(),
+	}
+	ChemicalRun(opts)
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if got, _ := f.GetCellValue("Sheet1", "Q2"); got != "" {
+		t.Errorf("预演模式不应修改文件，Q2 = %q", got)
+	}
+
+	diff, err := os.ReadFile(diffOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(diff), "Sheet1,2,化学式,,H2SO4,") {
+		t.Errorf("差异中缺少第 2 行的变更:\n%s", diff)
+	}
+}
+
+func TestImagesRun(t *testing.T) {
+	server := fakeSite()
+	defer server.Close()
+
+	path := newWorkbook(t, [][2]string{
+		{"7664-93-9", ""},  // 详情页有结构式图片
+		{"1002-16-0", ""},  // 没有图片，根据SMILES绘制
+		{"10049-21-5", ""}, // 没有图片也没有SMILES
+		{"99999-99-9", ""}, // 404
+	})
+
+	opts := Options{
+		FilePath:        path,
+		ConflictOut:     filepath.Join(t.TempDir(), "conflicts.csv"),
+		ChemicalBaseURL: server.URL,
+		SearchBaseURL:   server.URL + "/",
+		Client:          server.Client(),
+		ImageDir:        filepath.Join(t.TempDir(), "images"),
+		DepictFormat:    "png",
+	}
+	ImagesRun(opts)
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	cells, err := f.GetPictureCells("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cells) != 2 || cells[0] != "Z2" || cells[1] != "Z3" {
+		t.Fatalf("图片单元格 = %v, want [Z2 Z3]", cells)
+	}
+
+	for _, ext := range []string{"*.gif", "*.png"} {
+		stored, err := filepath.Glob(filepath.Join(opts.ImageDir, "*", ext))
+		if err != nil || len(stored) != 1 {
+			t.Fatalf("图片目录中的 %s 文件 = %v", ext, stored)
+		}
+	}
+}
+
+func TestIdentifiersRun(t *testing.T) {
+	server := fakeSite()
+	defer server.Close()
+
+	path := newWorkbook(t, [][2]string{
+		{"1002-16-0", ""}, // 页面列出全部标识符
+		{"7664-93-9", ""}, // 没有标识符
+	})
+	output := filepath.Join(t.TempDir(), "enriched.xlsx")
+
+	opts := Options{
+		FilePath:        path,
+		Output:          output,
+		ConflictOut:     filepath.Join(t.TempDir(), "conflicts.csv"),
+		DupOut:          filepath.Join(t.TempDir(), "dup.csv"),
+		ChemicalBaseURL: server.URL,
+		SearchBaseURL:   server.URL + "/",
+		Client:          server.Client(),
+	}
+	IdentifiersRun(opts)
+
+	f, err := excelize.OpenFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	rows, err := f.GetRows("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"SMILES":      "CCCCCO[N+](=O)[O-]",
+		"InChI":       "InChI=1S/C5H11NO3/c1-2-3-4-5-9-6(7)8/h2-5H2,1H3",
+		"InChIKey":    "HSNWZBCBUUSSQD-UHFFFAOYSA-N",
+		"PubChem CID": "13836",
+	}
+	for i, h := range rows[0] {
+		if w, ok := want[h]; ok {
+			if i >= len(rows[1]) || rows[1][i] != w {
+				t.Errorf("%s = %q, want %q", h, rows[1], w)
+			}
+			delete(want, h)
+		}
+	}
+	if len(want) != 0 {
+		t.Errorf("缺少列 %v", want)
+	}
+}
+
+func TestHazardsRun(t *testing.T) {
+	server := fakeSite()
+	defer server.Close()
+
+	path := newWorkbook(t, [][2]string{
+		{"7664-93-9", ""}, // 完整的 GHS 分类
+		{"1002-16-0", ""}, // 只有象形图、信号词和危险声明
+		{"10049-21-5", ""},
+	})
+	output := filepath.Join(t.TempDir(), "enriched.xlsx")
+
+	opts := Options{
+		FilePath:        path,
+		Output:          output,
+		ConflictOut:     filepath.Join(t.TempDir(), "conflicts.csv"),
+		ChemicalBaseURL: server.URL,
+		SearchBaseURL:   server.URL + "/",
+		Client:          server.Client(),
+		HazardLang:      "zh",
+	}
+	HazardsRun(opts)
+
+	f, err := excelize.OpenFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	want := map[string]string{
+		"U2": "H290 可能腐蚀金属\nH314 造成严重皮肤灼伤和眼损伤",
+		"T2": "P280 戴防护手套/穿防护服/戴防护眼罩/戴防护面具。",
+		"W2": "P301+P330+P331 如误吞咽：漱口。不得诱导呕吐。\n" +
+			"P303+P361+P353 如皮肤（或头发）沾染：立即脱掉所有沾染的衣服。用水清洗皮肤或淋浴。\n" +
+			"P305+P351+P338 如进入眼睛：用水小心冲洗几分钟。取出隐形眼镜（如戴了并可方便地取出）。继续冲洗。\n" +
+			"P310 立即呼叫解毒中心/医生。",
+		"X2": "P405 存放处须加锁。",
+		"Y2": "P501 按照地方/区域/国家/国际规章处置内装物/容器。",
+		"M2": "GHS05 腐蚀\n危险",
+		"U3": "H226 易燃液体和蒸气\nH302 吞咽有害",
+		"T3": "",
+		"M3": "GHS02 火焰\nGHS07 感叹号\n警告",
+		"U4": "",
+	}
+	for cell, w := range want {
+		got, err := f.GetCellValue("Sheet1", cell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != w {
+			t.Errorf("%s = %q, want %q", cell, got, w)
+		}
+	}
+}
+
+func TestExpiryRun(t *testing.T) {
+	path := newWorkbook(t, [][2]string{{"7664-93-9", ""}, {"64-17-5", ""}, {"108-88-3", ""}})
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// J 列为过期时间，日期单元格以序列号保存
+	f.SetCellValue("Sheet1", "J2", time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC))
+	f.SetCellValue("Sheet1", "J3", "2026年11月")
+	f.SetCellValue("Sheet1", "J4", "见瓶身")
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	out := filepath.Join(t.TempDir(), "expiry.csv")
+	ExpiryRun(Options{FilePath: path, ExpiryDays: 60, AsOf: "2026-10-18", ExpiryOut: out})
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"已过期,,,2,,7664-93-9,46266,2026-09-01,-47",
+		"即将过期,,,3,,64-17-5,2026年11月,2026-11-30,43",
+		"无法识别,,,4,,108-88-3,见瓶身,,",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("报告缺少 %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestLabelsRun(t *testing.T) {
+	path := newWorkbook(t, [][2]string{{"7664-93-9", "H2SO4"}, {"64-17-5", "C2H6O"}, {"108-88-3", "C7H8"}})
+	out := filepath.Join(t.TempDir(), "labels.svg")
+
+	LabelsRun(Options{FilePath: path, Layout: "l7163", Rows: "2,4", LabelOut: out})
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"CAS 7664-93-9", "H2SO4  MW 98.07", "CAS 108-88-3", "C7H8  MW 92.14"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("标签缺少 %q", want)
+		}
+	}
+	if strings.Contains(string(data), "64-17-5") {
+		t.Error("未选中的第 3 行也生成了标签")
+	}
+}
+
+func TestSDSRun(t *testing.T) {
+	server := fakeSite()
+	defer server.Close()
+
+	path := newWorkbook(t, [][2]string{
+		{"7664-93-9", ""}, // 没有链接，页面中的链接可用
+		{"1002-16-0", ""}, // 没有链接，页面中的链接 404
+		{"64-17-5", ""},   // 已有可用链接
+		{"108-88-3", ""},  // 已有链接失效，数据源中也没有
+		{"7664-93-9", ""}, // 已有链接失效，数据源中有可用的新链接，只在 -force 时替换
+	})
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// AA 列为 msds链接
+	f.SetCellValue("Sheet1", "AA4", server.URL+"/msds/7664-93-9.pdf")
+	f.SetCellValue("Sheet1", "AA5", server.URL+"/msds/gone.pdf")
+	f.SetCellValue("Sheet1", "AA6", server.URL+"/msds/gone.pdf")
+	// 上次检查的状态应被刷新，而不是作为冲突跳过
+	f.SetCellValue("Sheet1", "AB1", "msds状态")
+	f.SetCellValue("Sheet1", "AB4", "404 Not Found")
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	output := filepath.Join(t.TempDir(), "enriched.xlsx")
+	conflicts := filepath.Join(t.TempDir(), "conflicts.csv")
+	SDSRun(Options{
+		FilePath:        path,
+		Output:          output,
+		ConflictOut:     conflicts,
+		ChemicalBaseURL: server.URL,
+		SearchBaseURL:   server.URL + "/",
+		Client:          server.Client(),
+	})
+
+	f, err = excelize.OpenFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	today := time.Now().Format("2006-01-02")
+	want := map[string]string{
+		"AA2": server.URL + "/msds/7664-93-9.pdf", "AB2": "200 OK", "AC2": today,
+		"AA3": "", "AB3": "未找到",
+		"AB4": "200 OK",
+		"AA5": server.URL + "/msds/gone.pdf", "AB5": "404 Not Found", "AC5": today,
+		"AA6": server.URL + "/msds/gone.pdf", "AB6": "404 Not Found", "AC6": today,
+		"AB1": "msds状态", "AC1": "msds检查日期",
+	}
+	for cell, w := range want {
+		got, err := f.GetCellValue("Sheet1", cell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != w {
+			t.Errorf("%s = %q, want %q", cell, got, w)
+		}
+	}
+	// 未指定 -force 时找到的新链接记入冲突报告
+	report, err := os.ReadFile(conflicts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(report), "Sheet1,6,msds链接,"+server.URL+"/msds/gone.pdf,"+server.URL+"/msds/7664-93-9.pdf,") {
+		t.Errorf("冲突报告缺少第 6 行的新链接:\n%s", report)
+	}
+
+	forced := filepath.Join(t.TempDir(), "forced.xlsx")
+	SDSRun(Options{
+		FilePath:        output,
+		Output:          forced,
+		ConflictOut:     filepath.Join(t.TempDir(), "conflicts.csv"),
+		Force:           true,
+		ChemicalBaseURL: server.URL,
+		SearchBaseURL:   server.URL + "/",
+		Client:          server.Client(),
+	})
+	g, err := excelize.OpenFile(forced)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	for cell, w := range map[string]string{"AA6": server.URL + "/msds/7664-93-9.pdf", "AB6": "200 OK", "AA5": server.URL + "/msds/gone.pdf"} {
+		if got, _ := g.GetCellValue("Sheet1", cell); got != w {
+			t.Errorf("-force: %s = %q, want %q", cell, got, w)
+		}
+	}
+}
+
+func TestIngestSDSRun(t *testing.T) {
+	dir := t.TempDir()
+	// 文件名不含CAS号，从第1部分中识别
+	sheet := strings.Join([]string{
+		"化学品安全技术说明书",
+		"第一部分 化学品及企业标识",
+		"化学品中文名：硫酸 CAS号：7664-93-9",
+		"第五部分 消防措施",
+		"本品不燃。禁止用水。",
+		"第七部分 操作处置与储存",
+		"操作注意事项：密闭操作。",
+		"储存注意事项：储存于阴凉、通风的库房。",
+		"第九部分 理化特性",
+		"纯品为无色透明油状液体。",
+		"第十三部分 废弃处置",
+		"中和后排放。",
+	}, "\n")
+	if err := os.WriteFile(filepath.Join(dir, "sulfuric.txt"), []byte(sheet), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	path := newWorkbook(t, [][2]string{
+		{"7664-93-9", ""},
+		{"64-17-5", ""}, // 没有SDS文件
+	})
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 已有值的单元格不覆盖
+	f.SetCellValue("Sheet1", "V2", "干粉")
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	output := filepath.Join(t.TempDir(), "enriched.xlsx")
+	IngestSDSRun(Options{
+		FilePath:    path,
+		Output:      output,
+		ConflictOut: filepath.Join(t.TempDir(), "conflicts.csv"),
+		SDSDir:      dir,
+	})
+
+	f, err = excelize.OpenFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	want := map[string]string{
+		"R2": "纯品为无色透明油状液体。",
+		"V2": "干粉",
+		"X2": "储存注意事项：储存于阴凉、通风的库房。",
+		"Y2": "中和后排放。",
+		"R3": "",
+	}
+	for cell, w := range want {
+		got, err := f.GetCellValue("Sheet1", cell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != w {
+			t.Errorf("%s = %q, want %q", cell, got, w)
+		}
+	}
+}
+
+func TestSeedRun(t *testing.T) {
+	server := fakeSite()
+	defer server.Close()
+
+	curated := newWorkbook(t, [][2]string{
+		{"64-17-5", "C2H6O"},   // 替身站点中没有该页面
+		{"7664-93-9", "H2SO4"}, // 没有密度
+	})
+	f, err := excelize.OpenFile(curated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetCellValue("Sheet1", "F2", "0.789")
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	db := filepath.Join(t.TempDir(), "reagents.jsonl")
+	SeedRun(Options{FilePath: curated, DBPath: db})
+
+	path := newWorkbook(t, [][2]string{
+		{"64-17-5", ""},
+		{"1002-16-0", ""}, // 本地没有，查询替身站点
+	})
+	opts := Options{
+		FilePath:        path,
+		ConflictOut:     filepath.Join(t.TempDir(), "conflicts.csv"),
+		ChemicalBaseURL: server.URL,
+		DensityBaseURL:  server.URL,
+		Client:          server.Client(),
+		DBPath:          db,
+	}
+	// 本地试剂库中有值时不再查询站点
+	DensityRun(opts)
+	ChemicalRun(opts)
+
+	f, err = excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	want := map[string]string{"Q2": "C2H6O", "F2": "0.789", "Q3": "C5H11NO3"}
+	for cell, w := range want {
+		got, err := f.GetCellValue("Sheet1", cell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != w {
+			t.Errorf("%s = %q, want %q", cell, got, w)
+		}
+	}
+}
+
+func TestDensityRun(t *testing.T) {
+	server := fakeSite()
+	defer server.Close()
+
+	path := newWorkbook(t, [][2]string{
+		{"64-17-5", "C2H6O"}, // 已有化学式，密度为空
+		{"108-88-3", ""},     // 已有密度，不再查询
+	})
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetCellValue("Sheet1", "F3", "0.87")
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	conflicts := filepath.Join(t.TempDir(), "conflicts.csv")
+	DensityRun(Options{
+		FilePath:       path,
+		ConflictOut:    conflicts,
+		DensityBaseURL: server.URL,
+		Client:         server.Client(),
+	})
+
+	f, err = excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for cell, w := range map[string]string{"F2": "0.789", "F3": "0.87"} {
+		if got, _ := f.GetCellValue("Sheet1", cell); got != w {
+			t.Errorf("%s = %q, want %q", cell, got, w)
+		}
+	}
+	if _, err := os.Stat(conflicts); err == nil {
+		t.Error("已有密度的行不应产生冲突")
+	}
+}
+
+func TestExportImportRun(t *testing.T) {
+	source := newWorkbook(t, [][2]string{
+		{"7664-93-9", "H2SO4"},
+		{"64-17-5", "C2H6O"},
+	})
+	f, err := excelize.OpenFile(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetCellValue("Sheet1", "A2", "硫酸")
+	f.SetCellValue("Sheet1", "A3", "乙醇")
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	for _, ext := range []string{".jsonl", ".csv", ".sdf"} {
+		t.Run(ext, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "export"+ext)
+			ExportRun(Options{FilePath: source, ExportOut: out})
+
+			target := newWorkbook(t, [][2]string{
+				{"64-17-5", ""},
+			})
+			ImportRun(Options{
+				FilePath:    target,
+				Output:      target,
+				ConflictOut: filepath.Join(t.TempDir(), "conflicts.csv"),
+				ImportIn:    out,
+			})
+
+			f, err := excelize.OpenFile(target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			want := map[string]string{
+				"A2": "乙醇", "B2": "64-17-5", "Q2": "C2H6O",
+				"A3": "硫酸", "B3": "7664-93-9", "Q3": "H2SO4", // 追加的行
+			}
+			for cell, w := range want {
+				got, err := f.GetCellValue("Sheet1", cell)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != w {
+					t.Errorf("%s = %q, want %q", cell, got, w)
+				}
+			}
+		})
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := newLogger(&buf, "warn", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("忽略")
+	logger.With("row", 2, "cas", "64-17-5").Warn("查询失败")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("输出不是单条 JSON 记录: %v\n%s", err, buf.String())
+	}
+	if entry["level"] != "WARN" || entry["msg"] != "查询失败" || entry["cas"] != "64-17-5" || entry["row"] != float64(2) {
+		t.Errorf("entry = %v", entry)
+	}
+
+	if _, err := newLogger(&buf, "verbose", "text"); err == nil {
+		t.Error("无效的日志级别应返回错误")
+	}
+	if _, err := newLogger(&buf, "info", "xml"); err == nil {
+		t.Error("无效的日志格式应返回错误")
+	}
+}
+
+func TestCommandFlags(t *testing.T) {
+	if len(commands) != len(commandNames) {
+		t.Errorf("命令 %d 个，帮助文本列出 %d 个", len(commands), len(commandNames))
+	}
+	for _, name := range commandNames {
+		cmd, ok := commands[name]
+		if !ok {
+			t.Errorf("缺少命令 %s", name)
+			continue
+		}
+		// 同一参数定义两次时 flag 包会 panic
+		fs := newFlagSet(name, cmd, &Options{})
+		if fs.Lookup("log-level") == nil || fs.Lookup("lang") == nil {
+			t.Errorf("%s 缺少共用参数", name)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		has, not []string
+	}{
+		{"chemical", []string{"file", "output", "dry-run", "force", "chemical-base-url", "metrics-out", "progress-interval"}, []string{"label-out", "addr"}},
+		{"labels", []string{"file", "layout", "label-out"}, []string{"dry-run", "force", "db", "metrics-out"}},
+		{"serve", []string{"addr", "mark-comment", "db", "cache-ttl"}, []string{"file", "dry-run", "metrics-out"}},
+		{"watch", []string{"watch-dir", "once", "columns", "metrics-out"}, []string{"file", "output"}},
+	}
+	for _, tt := range tests {
+		fs := newFlagSet(tt.name, commands[tt.name], &Options{})
+		for _, f := range tt.has {
+			if fs.Lookup(f) == nil {
+				t.Errorf("%s 缺少参数 -%s", tt.name, f)
+			}
+		}
+		for _, f := range tt.not {
+			if fs.Lookup(f) != nil {
+				t.Errorf("%s 不应有参数 -%s", tt.name, f)
+			}
+		}
+	}
+
+	var opts Options
+	fs := newFlagSet("labels", commands["labels"], &opts)
+	if err := fs.Parse([]string{"-rows", "2-5", "-log-format", "json"}); err != nil || opts.Rows != "2-5" || opts.LogFormat != "json" || opts.FilePath != "./docs/ReagentModules.xlsx" {
+		t.Errorf("解析参数: %v %+v", err, opts)
+	}
+}
+
+func TestLangOf(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "en_US.UTF-8")
+	tests := []struct {
+		args []string
+		want language.Tag
+	}{
+		{nil, language.English},
+		{[]string{"-lang", "zh"}, language.SimplifiedChinese},
+		{[]string{"chemical", "--lang=zh-CN", "-dry-run"}, language.SimplifiedChinese},
+		{[]string{"-file", "x.xlsx", "-lang=en"}, language.English},
+	}
+	for _, tt := range tests {
+		if got := langOf(tt.args); got != tt.want {
+			t.Errorf("langOf(%q) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestServeLocalFirst(t *testing.T) {
+	var requests atomic.Int32
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		standin.Handler().ServeHTTP(w, r)
+	}))
+	defer site.Close()
+
+	path := filepath.Join(t.TempDir(), "reagents.jsonl")
+	db, _ := app.OpenLocalDB(path)
+	db.Put(&app.ChemicalInfo{CASNumber: "7664-93-9", ChineseName: "硫酸", ChemicalFormula: "H2SO4", Density: "1.84"})
+	db.Put(&app.ChemicalInfo{CASNumber: "64-17-5", ChineseName: "乙醇"})
+	if err := db.Save(); err != nil {
+		t.Fatal(err)
+	}
+	handler := serveHandler(Options{DBPath: path, ChemicalBaseURL: site.URL, SearchBaseURL: site.URL, CacheTTL: time.Hour})
+
+	// 本地记录有查询接口的全部字段，不在线查询，即使缺少 SDS 等其他列
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/cas/7664-93-9", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"ChemicalFormula":"H2SO4"`) || requests.Load() != 0 {
+		t.Errorf("GET /cas/7664-93-9 = %d，在线请求 %d 次: %s", rec.Code, requests.Load(), rec.Body)
+	}
+
+	// 缺少化学式时在线查询
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/cas/64-17-5", nil))
+	if rec.Code != http.StatusOK || requests.Load() == 0 {
+		t.Errorf("GET /cas/64-17-5 = %d，在线请求 %d 次: %s", rec.Code, requests.Load(), rec.Body)
+	}
+}
+
+func TestMetricsEndpointAndDump(t *testing.T) {
+	site := standin.NewServer()
+	defer site.Close()
+
+	handler := serveHandler(Options{ChemicalBaseURL: site.URL, SearchBaseURL: site.URL, CacheTTL: time.Hour})
+	for _, path := range []string{"/cas/7664-93-9", "/cas/7664-93-9"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /metrics = %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		`cas_cache_lookups_total{result="hit"}`,
+		`cas_provider_requests_total{provider="ichemistry",result="found"}`,
+		"# TYPE cas_write_duration_seconds histogram",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("/metrics 缺少 %s:\n%s", want, body)
+		}
+	}
+
+	out := filepath.Join(t.TempDir(), "metrics.prom")
+	if err := saveMetrics(out); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "# TYPE cas_http_responses_total counter") {
+		t.Errorf("保存的指标:\n%s", data)
+	}
+}
+
+func TestWatchRun(t *testing.T) {
+	site := standin.NewServer()
+	defer site.Close()
+
+	inbox, out := t.TempDir(), filepath.Join(t.TempDir(), "enriched")
+	metricsOut := filepath.Join(t.TempDir(), "metrics.prom")
+	input := filepath.Join(inbox, "supplier.xlsx")
+	if err := os.Rename(newWorkbook(t, [][2]string{{"7664-93-9", ""}, {"99999-99-9", ""}}), input); err != nil {
+		t.Fatal(err)
+	}
+	// Excel 的锁文件不处理
+	if err := os.WriteFile(filepath.Join(inbox, "~$supplier.xlsx"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := Options{
+		WatchDir:        inbox,
+		WatchOut:        out,
+		WatchOnce:       true,
+		Columns:         "化学式",
+		ChemicalBaseURL: site.URL,
+		SearchBaseURL:   site.URL,
+		MetricsOut:      metricsOut,
+	}
+	WatchRun(opts)
+
+	// 只运行一次时退出前也保存监控指标
+	if _, err := os.Stat(metricsOut); err != nil {
+		t.Errorf("未保存监控指标: %v", err)
+	}
+
+	f, err := excelize.OpenFile(filepath.Join(out, "supplier.enriched.xlsx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := f.GetCellValue("Sheet1", "Q2")
+	f.Close()
+	if got != "H2SO4" {
+		t.Errorf("Q2 = %q, want H2SO4", got)
+	}
+
+	report, err := os.ReadFile(filepath.Join(out, "supplier.report.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"2,7664-93-9,化学式,,H2SO4,", ",written\n", "3,99999-99-9,化学式,,,,still_empty\n"} {
+		if !strings.Contains(string(report), want) {
+			t.Errorf("报告缺少 %q:\n%s", want, report)
+		}
+	}
+	entries, _ := os.ReadDir(out)
+	if len(entries) != 2 {
+		t.Errorf("输出目录应只有副本和报告: %v", entries)
+	}
+
+	// 原文件未修改时不重复处理，修改后重新处理
+	if pending := pendingWorkbooks(opts, nil, time.Now()); len(pending) != 0 {
+		t.Errorf("已处理的文件不应再处理: %v", pending)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(input, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if pending := pendingWorkbooks(opts, nil, later); len(pending) != 1 {
+		t.Errorf("修改过的文件应重新处理: %v", pending)
+	}
+	opts.WatchSettle = time.Hour
+	if pending := pendingWorkbooks(opts, nil, later); len(pending) != 0 {
+		t.Errorf("刚修改的文件应等待: %v", pending)
+	}
+}
+
+func TestWatchRunSheetName(t *testing.T) {
+	site := standin.NewServer()
+	defer site.Close()
+
+	// 供应商工作表的第一个工作表不叫 Sheet1
+	inbox, out := t.TempDir(), t.TempDir()
+	input := filepath.Join(inbox, "supplier.xlsx")
+	f, err := excelize.OpenFile(newWorkbook(t, [][2]string{{"7664-93-9", ""}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetSheetName("Sheet1", "库存")
+	if err := f.SaveAs(input); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	WatchRun(Options{
+		WatchDir:        inbox,
+		WatchOut:        out,
+		WatchOnce:       true,
+		Columns:         "化学式,SMILES",
+		ChemicalBaseURL: site.URL,
+		SearchBaseURL:   site.URL,
+	})
+
+	f, err = excelize.OpenFile(filepath.Join(out, "supplier.enriched.xlsx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if sheets := f.GetSheetList(); len(sheets) != 1 || sheets[0] != "库存" {
+		t.Errorf("工作表 = %v", sheets)
+	}
+	for cell, want := range map[string]string{"Q2": "H2SO4", "AB1": "SMILES"} {
+		if got, _ := f.GetCellValue("库存", cell); got != want {
+			t.Errorf("%s = %q, want %q", cell, got, want)
+		}
+	}
+}
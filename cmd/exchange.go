@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"cas.mod/internal/app"
+)
+
+// ExportRun 将工作表或本地试剂库导出为 JSON Lines、CSV 或 SDF，格式由输出文件扩展名决定
+func ExportRun(opts Options) {
+	var table app.Table
+	switch opts.ExportFrom {
+	case "workbook", "":
+		processor := &app.ExcelProcessor{FilePath: opts.FilePath}
+		_, headers, records, err := processor.HeadersAndRecords()
+		if err != nil {
+			fatal("处理失败", "file", opts.FilePath, "err", err)
+		}
+		table = app.RecordsTable(headers, records)
+	case "db":
+		db, err := app.OpenLocalDB(opts.DBPath)
+		if err != nil {
+			fatal("打开本地试剂库失败", "file", opts.DBPath, "err", err)
+		}
+		table = app.DBTable(db)
+	default:
+		fatal("未知的导出来源 (workbook|db)", "from", opts.ExportFrom)
+	}
+
+	if err := app.SaveTable(table, opts.ExportOut); err != nil {
+		fatal("导出失败", "file", opts.ExportOut, "err", err)
+	}
+	slog.Info("导出完成", "count", len(table.Rows), "file", opts.ExportOut)
+}
+
+// ImportRun 从 JSON Lines、CSV 或 SDF 导入记录：按CAS号更新工作表中已有的行，
+// 没有的CAS号追加到末尾。字段名按表头或常用英文名映射，已有值的单元格按冲突处理
+func ImportRun(opts Options) {
+	if opts.ImportIn == "" {
+		fatal("未指定导入文件 (-import-in)")
+	}
+	table, err := app.LoadTable(opts.ImportIn)
+	if err != nil {
+		fatal("读取导入文件失败", "file", opts.ImportIn, "err", err)
+	}
+
+	processor := &app.ExcelProcessor{FilePath: opts.FilePath}
+	sheet, headers, records, err := processor.HeadersAndRecords()
+	if err != nil {
+		fatal("处理失败", "file", opts.FilePath, "err", err)
+	}
+
+	writer, err := newResultWriter(opts)
+	if err != nil {
+		fatal("创建写回目标失败", "err", err)
+	}
+	defer closeWriter(writer)
+
+	columns := importColumns(sheet, table.Columns, headers, writer)
+
+	rowsByCAS := make(map[string][]app.Record)
+	next := 2
+	for _, r := range records {
+		if cas := r.Get(app.ColumnCAS); cas != "" {
+			rowsByCAS[cas] = append(rowsByCAS[cas], r)
+		}
+		if r.Row >= next {
+			next = r.Row + 1
+		}
+	}
+
+	var matched, appended, skipped, written int
+	for _, row := range table.Rows {
+		cas := row[app.ColumnCAS]
+		if cas == "" {
+			skipped++
+			continue
+		}
+		targets, ok := rowsByCAS[cas]
+		if ok {
+			matched++
+		} else {
+			targets = []app.Record{{Row: next, Values: map[string]string{}}}
+			rowsByCAS[cas] = targets
+			next++
+			appended++
+		}
+
+		for _, target := range targets {
+			for _, column := range columns {
+				value := row[column]
+				if value == "" || value == target.Get(column) {
+					continue
+				}
+				err := writer.WriteCell(app.CellUpdate{
+					Sheet:    sheet,
+					Column:   column,
+					Row:      target.Row,
+					NewValue: value,
+					Source:   opts.ImportIn,
+					Provider: "import",
+				})
+				if err != nil {
+					slog.Warn("写入失败", "row", target.Row, "column", column, "err", err)
+					continue
+				}
+				target.Values[column] = value
+				written++
+			}
+		}
+	}
+	slog.Info("导入完成",
+		"count", len(table.Rows),
+		"updated", matched,
+		"appended", appended,
+		"skipped", skipped,
+		"written", written,
+	)
+
+	reportConflicts(writer, opts)
+}
+
+// importColumns 返回可以写入的列：工作表已有的列，以及追加到表头末尾的标准列
+func importColumns(sheet string, columns, headers []string, writer app.ResultWriter) []string {
+	existing := make(map[string]bool, len(headers))
+	for _, h := range headers {
+		existing[h] = true
+	}
+	standard := make(map[string]bool, len(app.ExchangeColumns))
+	for _, c := range app.ExchangeColumns {
+		standard[c] = true
+	}
+
+	var usable, missing []string
+	for _, column := range columns {
+		switch {
+		case existing[column]:
+			usable = append(usable, column)
+		case standard[column]:
+			missing = append(missing, column)
+		default:
+			slog.Warn("工作表中没有该列，已跳过", "column", column)
+		}
+	}
+	if len(missing) > 0 {
+		cw, ok := writer.(app.ColumnWriter)
+		if !ok {
+			slog.Warn("写回目标不支持追加列，已跳过", "columns", missing)
+			return usable
+		}
+		if err := cw.EnsureColumns(sheet, missing); err != nil {
+			slog.Error("追加列失败", "columns", missing, "err", err)
+			return usable
+		}
+		usable = append(usable, missing...)
+	}
+	return usable
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"log/slog"
+	"time"
+
+	"cas.mod/internal/app"
+)
+
+// ExpiryRun 检查过期时间列，按供应商和储存条件汇总已过期、即将过期和无法识别的记录并保存报告
+func ExpiryRun(opts Options) {
+	now := time.Now()
+	if opts.AsOf != "" {
+		t, err := time.ParseInLocation("2006-01-02", opts.AsOf, time.Local)
+		if err != nil {
+			fatal("检查日期格式错误", "as_of", opts.AsOf, "err", err)
+		}
+		now = t
+	}
+
+	processor := &app.ExcelProcessor{FilePath: opts.FilePath}
+	records, err := processor.RawRecords()
+	if err != nil {
+		fatal("处理失败", "file", opts.FilePath, "err", err)
+	}
+
+	entries := app.CheckExpiry(records, now, opts.ExpiryDays)
+	slog.Info("过期检查", "date", now.Format("2006-01-02"), "records", len(records), "entries", len(entries))
+
+	// 结果已按状态、供应商和储存条件排序，逐组汇总
+	for i := 0; i < len(entries); {
+		j := i
+		for j < len(entries) && sameExpiryGroup(entries[i], entries[j]) {
+			j++
+		}
+		e := entries[i]
+		slog.Info("过期记录", "status", e.Status, "supplier", orUnset(e.Supplier), "storage", orUnset(e.Storage), "count", j-i)
+		i = j
+	}
+
+	if len(entries) == 0 {
+		return
+	}
+	if err := app.SaveExpiry(entries, opts.ExpiryOut); err != nil {
+		slog.Error("保存过期报告失败", "file", opts.ExpiryOut, "err", err)
+		return
+	}
+	slog.Info("过期报告已保存", "file", opts.ExpiryOut)
+}
+
+// sameExpiryGroup 两条记录属于同一状态、供应商和储存条件
+func sameExpiryGroup(a, b app.ExpiryEntry) bool {
+	return a.Status == b.Status && a.Supplier == b.Supplier && a.Storage == b.Storage
+}
+
+// orUnset 空值显示为 "未注明"
+func orUnset(s string) string {
+	if s == "" {
+		return "未注明"
+	}
+	return s
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"flag"
+	"time"
+
+	"cas.mod/internal/app"
+	"cas.mod/internal/i18n"
+	"cas.mod/internal/progress"
+)
+
+// 以下函数定义多个子命令共用的参数
+
+// commonFlags 所有命令共用的日志和语言参数
+func commonFlags(fs *flag.FlagSet, opts *Options) {
+	fs.StringVar(&opts.LogLevel, "log-level", "info", i18n.T("日志级别 (debug|info|warn|error)，debug 级别会输出未找到字段时的页面内容"))
+	fs.StringVar(&opts.LogFormat, "log-format", "text", i18n.T("日志格式 (text|json)"))
+	fs.StringVar(&opts.Lang, "lang", "", i18n.T("消息语言 (zh|en)，默认按 LANG 环境变量选择"))
+}
+
+// fileFlag 待处理的工作表
+func fileFlag(fs *flag.FlagSet, opts *Options) {
+	fs.StringVar(&opts.FilePath, "file", "./docs/ReagentModules.xlsx", i18n.T("待处理的Excel文件"))
+}
+
+// writeFlags 写回工作表的命令共用的参数：输出副本、预演、覆盖和来源标记
+func writeFlags(fs *flag.FlagSet, opts *Options) {
+	fileFlag(fs, opts)
+	fs.BoolVar(&opts.DryRun, "dry-run", false, i18n.T("只查询并输出差异，不修改Excel文件"))
+	fs.StringVar(&opts.DiffOut, "diff-out", "-", i18n.T("预演模式的差异输出文件，\"-\" 表示标准输出"))
+	fs.StringVar(&opts.Output, "output", "", i18n.T("将结果写入该副本，原文件保持不变；为空时写回原文件并自动备份"))
+	fs.BoolVar(&opts.Enriched, "enriched", false, i18n.T("将结果写入 <文件名>.enriched.xlsx"))
+	fs.BoolVar(&opts.Force, "force", false, i18n.T("允许覆盖已有值的单元格"))
+	fs.StringVar(&opts.ConflictOut, "conflict-out", "./docs/conflict_report.csv", i18n.T("单元格已有值而跳过写入时的冲突报告"))
+	provenanceFlags(fs, opts)
+}
+
+// provenanceFlags 自动填充值的来源标记
+func provenanceFlags(fs *flag.FlagSet, opts *Options) {
+	fs.BoolVar(&opts.Provenance.Comment, "mark-comment", false, i18n.T("为自动填充的单元格添加来源批注"))
+	fs.StringVar(&opts.Provenance.Fill, "mark-fill", "", i18n.T("为自动填充的单元格设置填充色，如 #FFF2CC"))
+	fs.BoolVar(&opts.Provenance.Sheet, "provenance-sheet", false, i18n.Sprintf("在隐藏工作表 %s 中记录每个值的来源", app.ProvenanceSheet))
+}
+
+// lookupFlags 按数据源链查询的命令共用的数据源地址和本地试剂库
+func lookupFlags(fs *flag.FlagSet, opts *Options) {
+	fs.StringVar(&opts.ChemicalBaseURL, "chemical-base-url", defaultChemicalBaseURL, i18n.T("化学式数据源地址"))
+	fs.StringVar(&opts.SearchBaseURL, "search-base-url", app.DefaultSearchBaseURL, i18n.T("搜索站点地址"))
+	dbFlag(fs, opts)
+}
+
+// dbFlag 本地试剂库
+func dbFlag(fs *flag.FlagSet, opts *Options) {
+	fs.StringVar(&opts.DBPath, "db", "./docs/reagents.jsonl", i18n.T("本地试剂库 (JSON Lines)，查询时优先使用，为空时不使用"))
+}
+
+// progressFlag 非终端时输出进度日志的间隔
+func progressFlag(fs *flag.FlagSet, opts *Options) {
+	fs.DurationVar(&opts.ProgressInterval, "progress-interval", progress.DefaultInterval, i18n.T("不在终端中运行时输出进度日志的间隔"))
+}
+
+// cacheFlag 长时间运行的命令中查询结果的缓存有效期
+func cacheFlag(fs *flag.FlagSet, opts *Options) {
+	fs.DurationVar(&opts.CacheTTL, "cache-ttl", time.Hour, i18n.T("查询结果的缓存有效期，0 表示不过期"))
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"cas.mod/internal/app"
+)
+
+// HazardsRun 查询 GHS 危险性分类，将危险性说明、防范说明和象形图/警示词的
+// 标准文本写入危险特性、预防措施、事故响应、安全存储、废弃处置和标签列
+func HazardsRun(opts Options) {
+	processor := &app.ExcelProcessor{FilePath: opts.FilePath}
+	rowNumberAndCas, err := processor.GetCASByEmptyColumn(app.ColumnHazards)
+	if err != nil {
+		fatal("处理失败", "file", opts.FilePath, "err", err)
+	}
+
+	writer, err := newResultWriter(opts)
+	if err != nil {
+		fatal("创建写回目标失败", "err", err)
+	}
+	defer closeWriter(writer)
+
+	chain := providers(opts, func(info *app.ChemicalInfo) bool { return !info.Hazard.Empty() })
+	for _, number := range sortedRows(rowNumberAndCas) {
+		cas := rowNumberAndCas[number]
+
+		info, err := chain.Lookup(cas)
+		if err != nil {
+			slog.Warn("查询失败", "row", number, "cas", cas, "err", err)
+			continue
+		}
+		if info.Hazard.Empty() {
+			slog.Info("没有 GHS 分类信息", "row", number, "cas", cas)
+			continue
+		}
+
+		cells := app.HazardCells(info.Hazard, opts.HazardLang)
+		written := 0
+		for _, column := range app.HazardColumns {
+			if cells[column] == "" {
+				continue
+			}
+			err := writer.WriteCell(app.CellUpdate{
+				Sheet:    "Sheet1",
+				Column:   column,
+				Row:      number,
+				NewValue: cells[column],
+				Source:   info.SourceURL,
+				Provider: info.Provider,
+			})
+			if err != nil {
+				slog.Warn("写入失败", "row", number, "column", column, "err", err)
+				continue
+			}
+			written++
+		}
+		slog.Info("写入安全信息", "row", number, "cas", cas, "written", written)
+	}
+
+	reportConflicts(writer, opts)
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"cas.mod/internal/app"
+)
+
+// IdentifiersRun 查询 SMILES、InChI、InChIKey 和 PubChem CID 并写入对应列，
+// 完成后按 InChIKey 报告以不同CAS号或名称重复录入的试剂
+func IdentifiersRun(opts Options) {
+	processor := &app.ExcelProcessor{FilePath: opts.FilePath}
+	rowNumberAndCas, err := processor.GetCASByEmptyColumn(app.FieldInChIKey)
+	if err != nil {
+		fatal("处理失败", "file", opts.FilePath, "err", err)
+	}
+
+	writer, err := newResultWriter(opts)
+	if err != nil {
+		fatal("创建写回目标失败", "err", err)
+	}
+	defer closeWriter(writer)
+
+	cw, ok := writer.(app.ColumnWriter)
+	if !ok {
+		fatal("写回目标不支持追加列")
+	}
+	if err := cw.EnsureColumns("Sheet1", app.IdentifierColumns); err != nil {
+		fatal("追加标识符列失败", "err", err)
+	}
+
+	chain := providers(opts, func(info *app.ChemicalInfo) bool { return info.InChIKey != "" || info.SMILES != "" })
+	for _, number := range sortedRows(rowNumberAndCas) {
+		cas := rowNumberAndCas[number]
+
+		info, err := chain.Lookup(cas)
+		if err != nil {
+			slog.Warn("查询失败", "row", number, "cas", cas, "err", err)
+			continue
+		}
+
+		ids := info.Identifiers()
+		written := 0
+		for _, column := range app.IdentifierColumns {
+			if ids[column] == "" {
+				continue
+			}
+			err := writer.WriteCell(app.CellUpdate{
+				Sheet:    "Sheet1",
+				Column:   column,
+				Row:      number,
+				NewValue: ids[column],
+				Source:   info.SourceURL,
+				Provider: info.Provider,
+			})
+			if err != nil {
+				slog.Warn("写入失败", "row", number, "column", column, "err", err)
+				continue
+			}
+			written++
+		}
+		slog.Info("写入标识符", "row", number, "cas", cas, "written", written)
+	}
+
+	reportConflicts(writer, opts)
+
+	// 预演模式下文件未修改，重复检查没有意义
+	if opts.DryRun {
+		return
+	}
+	closeWriter(writer)
+	reportDuplicates(writtenPath(opts), opts.DupOut)
+}
+
+// writtenPath 返回结果实际写入的文件
+func writtenPath(opts Options) string {
+	if opts.Output != "" {
+		return opts.Output
+	}
+	return opts.FilePath
+}
+
+// reportDuplicates 按 InChIKey 查找重复录入的试剂并保存报告
+func reportDuplicates(filePath, out string) {
+	processor := &app.ExcelProcessor{FilePath: filePath}
+	groups, err := processor.FindDuplicates(app.FieldInChIKey)
+	if err != nil {
+		slog.Error("检查重复失败", "err", err)
+		return
+	}
+	if len(groups) == 0 {
+		slog.Info("没有发现 InChIKey 相同的记录")
+		return
+	}
+
+	for _, g := range groups {
+		slog.Warn("InChIKey 重复", "inchikey", g.Key, "rows", g.Rows, "cas", g.CAS)
+	}
+	if err := app.SaveDuplicates(groups, out); err != nil {
+		slog.Error("保存重复报告失败", "file", out, "err", err)
+		return
+	}
+	slog.Info("重复记录已保存", "groups", len(groups), "file", out)
+}
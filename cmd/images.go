@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"log/slog"
+	"sort"
+
+	"cas.mod/internal/app"
+	"cas.mod/internal/depict"
+	"cas.mod/internal/i18n"
+)
+
+// structureColumn 结构图片所在的列
+const structureColumn = "结构图片"
+
+// providers 按运行参数创建数据源查询链，本地试剂库排在最前，记录缺少 need 要求的字段时继续查询在线数据源
+func providers(opts Options, need func(*app.ChemicalInfo) bool) app.ProviderChain {
+	return append(app.ProviderChain{&app.LocalProvider{DB: localDB(opts), Need: need}}, onlineProviders(opts)...)
+}
+
+// onlineProviders 在线数据源查询链
+func onlineProviders(opts Options) app.ProviderChain {
+	return app.ProviderChain{
+		&app.IchemistryProvider{BaseURL: opts.ChemicalBaseURL, Client: httpClient(opts)},
+		&app.SearchProvider{BaseURL: opts.SearchBaseURL, Client: httpClient(opts)},
+	}
+}
+
+// enrichProvider 按列填充使用的查询：本地试剂库缺少所选列的值时再在线查询，两者合并
+func enrichProvider(opts Options, columns []string) app.Provider {
+	return &app.MergedProvider{
+		DB:     localDB(opts),
+		Online: onlineProviders(opts),
+		Need:   app.NeedColumns(columns),
+	}
+}
+
+// sortedRows 按行号排序，使处理顺序和日志稳定
+func sortedRows(rows map[int]string) []int {
+	numbers := make([]int, 0, len(rows))
+	for number := range rows {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	return numbers
+}
+
+// ImagesRun 下载结构式图片并嵌入结构图片列
+func ImagesRun(opts Options) {
+	processor := &app.ExcelProcessor{FilePath: opts.FilePath}
+	rowNumberAndCas, err := processor.GetCASByEmptyColumn(structureColumn)
+	if err != nil {
+		fatal("处理失败", "file", opts.FilePath, "err", err)
+	}
+
+	writer, err := newResultWriter(opts)
+	if err != nil {
+		fatal("创建写回目标失败", "err", err)
+	}
+	defer closeWriter(writer)
+
+	pw, ok := writer.(app.PictureWriter)
+	if !ok {
+		fatal("写回目标不支持嵌入图片")
+	}
+
+	chain := providers(opts, func(info *app.ChemicalInfo) bool { return info.StructureImage != "" || info.SMILES != "" })
+	store := &app.ImageStore{Dir: opts.ImageDir, Client: httpClient(opts)}
+
+	for _, number := range sortedRows(rowNumberAndCas) {
+		cas := rowNumberAndCas[number]
+
+		info, err := chain.Lookup(cas)
+		if err != nil {
+			slog.Warn("查询失败", "row", number, "cas", cas, "err", err)
+			continue
+		}
+
+		imagePath, source, err := structureImage(info, store, opts.DepictFormat)
+		if err != nil {
+			slog.Warn("获取结构式图片失败", "row", number, "cas", cas, "err", err)
+			continue
+		}
+
+		err = pw.WritePicture(app.CellUpdate{
+			Sheet:    "Sheet1",
+			Column:   structureColumn,
+			Row:      number,
+			NewValue: imagePath,
+			Source:   source,
+			Provider: info.Provider,
+		}, imagePath)
+		if err != nil {
+			slog.Warn("写入失败", "row", number, "err", err)
+			continue
+		}
+		slog.Info("已嵌入结构式图片", "row", number, "cas", cas, "file", imagePath)
+	}
+
+	reportConflicts(writer, opts)
+}
+
+// structureImage 优先下载数据源提供的结构式图片，没有图片或下载失败时根据SMILES绘制
+// 返回本地图片路径和来源说明
+func structureImage(info *app.ChemicalInfo, store *app.ImageStore, format string) (string, string, error) {
+	if info.StructureImage != "" {
+		imagePath, err := store.Download(info.StructureImage)
+		if err == nil {
+			return imagePath, info.StructureImage, nil
+		}
+		if info.SMILES == "" {
+			return "", "", i18n.Errorf("下载图片失败: %v", err)
+		}
+		slog.Warn("下载图片失败，改为根据SMILES绘制", "cas", info.CASNumber, "url", info.StructureImage, "err", err)
+	}
+
+	if info.SMILES == "" {
+		return "", "", i18n.Errorf("没有结构式图片和SMILES")
+	}
+
+	data, ext, err := depict.Render(info.SMILES, format)
+	if err != nil {
+		return "", "", err
+	}
+	imagePath, err := store.Save(data, ext)
+	if err != nil {
+		return "", "", err
+	}
+	return imagePath, "SMILES: " + info.SMILES, nil
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"cas.mod/internal/app"
+)
+
+// IncompatibleRun 按储存位置分组检查同处储存的试剂是否相容，保存不相容试剂对报告
+func IncompatibleRun(opts Options) {
+	processor := &app.ExcelProcessor{FilePath: opts.FilePath}
+	records, err := processor.Records()
+	if err != nil {
+		fatal("处理失败", "file", opts.FilePath, "err", err)
+	}
+
+	reagents := make([]app.Reagent, 0, len(records))
+	classified, unlocated := 0, 0
+	for _, r := range records {
+		reagent := app.NewReagent(r, opts.GroupBy)
+		if len(reagent.Classes) > 0 || len(reagent.Avoid) > 0 || reagent.AvoidText != "" {
+			classified++
+			if reagent.Group == "" {
+				unlocated++
+			}
+		}
+		reagents = append(reagents, reagent)
+	}
+	slog.Info("相容性检查", "reagents", len(reagents), "classified", classified)
+	if unlocated > 0 {
+		slog.Warn("储存位置未填写的危险试剂不参与检查", "column", opts.GroupBy, "count", unlocated)
+	}
+
+	pairs := app.FindIncompatible(reagents)
+	if len(pairs) == 0 {
+		slog.Info("没有发现同处储存的不相容试剂")
+		return
+	}
+
+	// 结果已按分组排序，逐组汇总
+	for i := 0; i < len(pairs); {
+		j := i
+		for j < len(pairs) && pairs[j].Group == pairs[i].Group {
+			j++
+		}
+		slog.Warn("不相容试剂", "column", opts.GroupBy, "group", pairs[i].Group, "pairs", j-i)
+		i = j
+	}
+
+	if err := app.SaveIncompatible(pairs, opts.IncompatibleOut); err != nil {
+		slog.Error("保存不相容报告失败", "file", opts.IncompatibleOut, "err", err)
+		return
+	}
+	slog.Info("不相容试剂报告已保存", "pairs", len(pairs), "file", opts.IncompatibleOut)
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"errors"
+	"log/slog"
+
+	"cas.mod/internal/app"
+)
+
+// IngestSDSRun 从本地SDS文件(PDF或文本)中按16部分结构提取理化特性、消防措施、
+// 储存注意事项和废弃处置，写入物化性质、灭火方式、安全存储和废弃处置列
+func IngestSDSRun(opts Options) {
+	processor := &app.ExcelProcessor{FilePath: opts.FilePath}
+	records, err := processor.Records()
+	if err != nil {
+		fatal("处理失败", "file", opts.FilePath, "err", err)
+	}
+
+	writer, err := newResultWriter(opts)
+	if err != nil {
+		fatal("创建写回目标失败", "err", err)
+	}
+	defer closeWriter(writer)
+
+	provider := &app.SDSProvider{Dir: opts.SDSDir}
+	for _, r := range records {
+		cas := r.Get(app.ColumnCAS)
+		if cas == "" {
+			continue
+		}
+
+		info, err := provider.Lookup(cas)
+		if err != nil {
+			if !errors.Is(err, app.ErrNotFound) {
+				slog.Warn("读取SDS失败", "row", r.Row, "cas", cas, "err", err)
+			}
+			continue
+		}
+
+		cells := app.SDSCells(info)
+		written := 0
+		for _, column := range app.SDSFileColumns {
+			if cells[column] == "" {
+				continue
+			}
+			err := writer.WriteCell(app.CellUpdate{
+				Sheet:    "Sheet1",
+				Column:   column,
+				Row:      r.Row,
+				NewValue: cells[column],
+				Source:   info.SourceURL,
+				Provider: info.Provider,
+			})
+			if err != nil {
+				slog.Warn("写入失败", "row", r.Row, "column", column, "err", err)
+				continue
+			}
+			written++
+		}
+		slog.Info("写入SDS内容", "row", r.Row, "cas", cas, "file", info.SourceURL, "written", written)
+	}
+
+	reportConflicts(writer, opts)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"cas.mod/internal/app"
+	"cas.mod/internal/label"
+)
+
+// columnMW 工作表中有分子量列时优先使用，否则由化学式计算
+const columnMW = "分子量"
+
+// LabelsRun 将选定行排版为试剂瓶标签，按输出文件扩展名生成PDF或SVG。
+// PDF 不嵌入中文字体，使用阅读器的 STSong-Light（Adobe 简体中文字体包），无法安装时请输出 SVG
+func LabelsRun(opts Options) {
+	layout, ok := label.Layouts[opts.Layout]
+	if !ok {
+		fatal("未知的标签纸规格", "layout", opts.Layout, "choices", strings.Join(label.LayoutNames(), ", "))
+	}
+	selected, err := parseRows(opts.Rows)
+	if err != nil {
+		fatal("行号格式错误", "rows", opts.Rows, "err", err)
+	}
+
+	processor := &app.ExcelProcessor{FilePath: opts.FilePath}
+	records, err := processor.RawRecords()
+	if err != nil {
+		fatal("处理失败", "file", opts.FilePath, "err", err)
+	}
+
+	var labels []label.Label
+	for _, r := range records {
+		if !selected(r.Row) || r.Get(app.ColumnName) == "" && r.Get(app.ColumnCAS) == "" {
+			continue
+		}
+		labels = append(labels, newLabel(r))
+	}
+	if len(labels) == 0 {
+		slog.Info("没有需要打印的标签")
+		return
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(opts.LabelOut)); ext {
+	case ".pdf":
+		if err := os.WriteFile(opts.LabelOut, label.PDF(labels, layout), 0644); err != nil {
+			fatal("保存标签失败", "file", opts.LabelOut, "err", err)
+		}
+		slog.Info("标签已保存", "labels", len(labels), "file", opts.LabelOut)
+	case ".svg":
+		pages := label.SVG(labels, layout)
+		for i, page := range pages {
+			path := opts.LabelOut
+			if len(pages) > 1 {
+				path = strings.TrimSuffix(path, filepath.Ext(path)) + fmt.Sprintf("-%03d", i+1) + filepath.Ext(path)
+			}
+			if err := os.WriteFile(path, page, 0644); err != nil {
+				fatal("保存标签失败", "file", path, "err", err)
+			}
+		}
+		slog.Info("标签已保存", "labels", len(labels), "pages", len(pages), "file", opts.LabelOut)
+	default:
+		fatal("不支持的标签格式，请使用 .pdf 或 .svg", "ext", ext)
+	}
+}
+
+// newLabel 由一行记录生成标签内容
+func newLabel(r app.Record) label.Label {
+	h := app.RecordHazard(r)
+	l := label.Label{
+		Name:       r.Get(app.ColumnName),
+		CAS:        r.Get(app.ColumnCAS),
+		Formula:    r.Get(app.FieldFormula),
+		MW:         r.Get(columnMW),
+		Pictograms: h.Pictograms,
+		SignalWord: h.SignalWord,
+		Expiry:     r.Get(app.ColumnExpiry),
+	}
+	if l.MW == "" && l.Formula != "" {
+		if mw, err := app.MolecularWeight(l.Formula); err == nil {
+			l.MW = strconv.FormatFloat(mw, 'f', 2, 64)
+		}
+	}
+	if date, ok := app.ParseExpiry(l.Expiry); ok {
+		l.Expiry = date.Format("2006-01-02")
+	}
+	return l
+}
+
+// parseRows 解析 "2-20,25" 形式的行号列表，为空时选中所有行
+func parseRows(spec string) (func(int) bool, error) {
+	if strings.TrimSpace(spec) == "" {
+		return func(int) bool { return true }, nil
+	}
+
+	type span struct{ from, to int }
+	var spans []span
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		from, to, isRange := strings.Cut(part, "-")
+		a, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			return nil, fmt.Errorf("%q: %v", part, err)
+		}
+		b := a
+		if isRange {
+			if b, err = strconv.Atoi(strings.TrimSpace(to)); err != nil {
+				return nil, fmt.Errorf("%q: %v", part, err)
+			}
+		}
+		spans = append(spans, span{a, b})
+	}
+	return func(row int) bool {
+		for _, s := range spans {
+			if row >= s.from && row <= s.to {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"strings"
+
+	"cas.mod/internal/i18n"
+	"golang.org/x/text/language"
+)
+
+// langOf 从参数中取出 -lang 的值并匹配到支持的语言，未指定时按环境变量选择
+func langOf(args []string) language.Tag {
+	for i, arg := range args {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "lang" {
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			value = args[i+1]
+		}
+		if value != "" {
+			return i18n.Parse(value)
+		}
+	}
+	return i18n.FromEnv()
+}
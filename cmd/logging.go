@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+
+	"cas.mod/internal/i18n"
+)
+
+// logOutput 日志的输出目标，显示进度条时临时替换为在进度条上方输出的写入器
+var logOutput = &swapWriter{w: os.Stderr}
+
+// swapWriter 可在运行中替换目标的写入器
+type swapWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *swapWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+// Writer 返回当前的写入目标
+func (s *swapWriter) Writer() io.Writer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w
+}
+
+// Swap 替换写入目标并返回原来的目标
+func (s *swapWriter) Swap(w io.Writer) io.Writer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old := s.w
+	s.w = w
+	return old
+}
+
+// newLogger 创建指定级别 (debug|info|warn|error) 和格式 (text|json) 的日志记录器
+func newLogger(w io.Writer, level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, i18n.Errorf("无效的日志级别 %q: %v", level, err)
+	}
+
+	hopts := &slog.HandlerOptions{Level: lvl}
+	switch format {
+	case "text", "":
+		return slog.New(i18n.Handler(slog.NewTextHandler(w, hopts))), nil
+	case "json":
+		return slog.New(i18n.Handler(slog.NewJSONHandler(w, hopts))), nil
+	default:
+		return nil, i18n.Errorf("无效的日志格式 %q，应为 text 或 json", format)
+	}
+}
+
+// setupLogging 设置默认日志记录器，标准库 log 的输出也经由它记录
+func setupLogging(level, format string) error {
+	logger, err := newLogger(logOutput, level, format)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// fatal 记录错误并退出
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"cas.mod/internal/i18n"
+	"cas.mod/internal/metrics"
+)
+
+// batchCommands 结束时保存监控指标的批量命令
+var batchCommands = map[string]bool{
+	"chemical": true, "density": true, "images": true, "identifiers": true, "hazards": true,
+	"sds": true, "ingest-sds": true, "seed": true, "import": true, "watch": true,
+}
+
+// saveMetrics 按 Prometheus 文本格式保存监控指标，path 为 "-" 时输出到标准错误，
+// 为空时不保存。先写临时文件再改名，供 node_exporter 的 textfile 收集器读取
+func saveMetrics(path string) error {
+	switch path {
+	case "":
+		return nil
+	case "-":
+		_, err := metrics.Default.WriteTo(os.Stderr)
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return i18n.Errorf("创建临时文件失败: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := metrics.Default.WriteTo(tmp); err != nil {
+		tmp.Close()
+		return i18n.Errorf("写入监控指标失败: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return i18n.Errorf("写入监控指标失败: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return i18n.Errorf("写入监控指标失败: %v", err)
+	}
+	slog.Info("监控指标已保存", "file", path)
+	return nil
+}
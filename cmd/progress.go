@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"os"
+
+	"cas.mod/internal/progress"
+)
+
+// trackProgress 在标准错误上显示 total 行的处理进度，非终端时通过 slog 输出进度日志。
+// 在终端中逐行日志显示在进度条上方；返回的函数输出最终进度并恢复日志输出
+func trackProgress(total int, opts Options) (*progress.Tracker, func()) {
+	tracker := progress.New(total, os.Stderr)
+	tracker.Interval = opts.ProgressInterval
+	w := logOutput.Swap(tracker.LogWriter(logOutput.Writer()))
+	return tracker, func() {
+		tracker.Finish()
+		logOutput.Swap(w)
+	}
+}
@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"cas.mod/internal/app"
+	"cas.mod/internal/i18n"
+	"cas.mod/internal/label"
+)
+
+// Options 命令行运行参数
+type Options struct {
+	FilePath string // 待处理的Excel文件
+	DryRun   bool   // 只查询并输出差异，不修改Excel文件
+	DiffOut  string // 差异输出文件，"-" 表示标准输出
+	Output   string // 结果写入的副本，为空时写回原文件
+	Enriched bool   // 写入默认副本 <文件名>.enriched.xlsx
+
+	Provenance app.ProvenanceOptions // 自动填充值的来源标记
+
+	Force       bool   // 允许覆盖非空单元格
+	ConflictOut string // 冲突报告文件
+
+	ChemicalBaseURL string       // 化学式数据源地址，可指向本地替身
+	DensityBaseURL  string       // 密度数据源地址，可指向本地替身
+	SearchBaseURL   string       // 搜索站点地址，可指向本地替身
+	Client          *http.Client // 发送请求使用的客户端，为空时使用 http.DefaultClient
+
+	ImageDir     string // 结构式图片的保存目录
+	DepictFormat string // 根据SMILES绘制结构图的格式，png 或 svg
+
+	DupOut string // InChIKey 重复记录报告
+
+	HazardLang string // GHS 标准文本的语言，zh 或 en
+
+	GroupBy         string // 相容性检查按该列分组，视为同一储存位置
+	IncompatibleOut string // 不相容试剂对报告
+
+	ExpiryDays int    // 即将过期的天数
+	AsOf       string // 过期检查的日期，为空时使用当天
+	ExpiryOut  string // 过期报告
+
+	Layout   string // 标签纸规格
+	Rows     string // 打印标签的行号，如 "2-20,25"，为空时打印所有行
+	LabelOut string // 标签输出文件，扩展名为 .pdf 或 .svg
+
+	SDSDir string // 本地SDS文件目录
+
+	DBPath string // 本地试剂库文件，为空时不使用
+
+	ExportFrom string // 导出来源，workbook 或 db
+	ExportOut  string // 导出文件，扩展名为 .jsonl、.csv 或 .sdf
+	ImportIn   string // 导入文件，格式同导出
+
+	Addr     string        // serve 命令的监听地址
+	CacheTTL time.Duration // 查询结果的缓存有效期
+	WebDir   string        // 网页界面上传文件和结果的保存目录
+
+	ProgressInterval time.Duration // 不在终端中运行时输出进度日志的间隔
+
+	LogLevel  string // 日志级别 (debug|info|warn|error)
+	LogFormat string // 日志格式 (text|json)
+	Lang      string // 消息语言 (zh|en)，为空时按 LANG 环境变量选择
+
+	MetricsOut string // 批量命令结束时保存监控指标的文件，"-" 表示标准错误，为空时不保存
+
+	WatchDir      string        // watch 命令监视的目录
+	WatchOut      string        // 填充后的副本和报告的输出目录
+	WatchInterval time.Duration // 扫描目录的间隔
+	WatchSettle   time.Duration // 文件修改后等待该时长再处理，避免读取正在复制的文件
+	WatchOnce     bool          // 只扫描一次后退出，用于定时任务
+	Columns       string        // 填充的列，逗号分隔
+}
+
+// command 子命令：flags 定义该命令使用的参数，run 执行命令
+type command struct {
+	flags func(fs *flag.FlagSet, opts *Options)
+	run   func(Options)
+}
+
+// commands 所有子命令，commandNames 为帮助文本中的顺序
+var (
+	commandNames = []string{
+		"chemical", "density", "images", "identifiers", "hazards", "incompatible", "expiry", "labels",
+		"sds", "ingest-sds", "seed", "export", "import", "serve", "watch",
+	}
+	commands = map[string]command{
+		"chemical": {run: ChemicalRun, flags: func(fs *flag.FlagSet, opts *Options) {
+			writeFlags(fs, opts)
+			fs.StringVar(&opts.ChemicalBaseURL, "chemical-base-url", defaultChemicalBaseURL, i18n.T("化学式数据源地址"))
+			dbFlag(fs, opts)
+			progressFlag(fs, opts)
+		}},
+		"density": {run: DensityRun, flags: func(fs *flag.FlagSet, opts *Options) {
+			writeFlags(fs, opts)
+			fs.StringVar(&opts.DensityBaseURL, "density-base-url", defaultDensityBaseURL, i18n.T("密度数据源地址"))
+			dbFlag(fs, opts)
+			progressFlag(fs, opts)
+		}},
+		"images": {run: ImagesRun, flags: func(fs *flag.FlagSet, opts *Options) {
+			writeFlags(fs, opts)
+			lookupFlags(fs, opts)
+			fs.StringVar(&opts.ImageDir, "image-dir", "./docs/images", i18n.T("结构式图片的保存目录，按内容哈希存放"))
+			fs.StringVar(&opts.DepictFormat, "depict-format", "png", i18n.T("没有图片时根据SMILES绘制结构图的格式 (png|svg)"))
+		}},
+		"identifiers": {run: IdentifiersRun, flags: func(fs *flag.FlagSet, opts *Options) {
+			writeFlags(fs, opts)
+			lookupFlags(fs, opts)
+			fs.StringVar(&opts.DupOut, "dup-out", "./docs/duplicate_inchikey.csv", i18n.T("InChIKey 相同的重复记录报告"))
+		}},
+		"hazards": {run: HazardsRun, flags: func(fs *flag.FlagSet, opts *Options) {
+			writeFlags(fs, opts)
+			lookupFlags(fs, opts)
+			fs.StringVar(&opts.HazardLang, "hazard-lang", "zh", i18n.T("GHS 危险性说明和防范说明的语言 (zh|en)"))
+		}},
+		"incompatible": {run: IncompatibleRun, flags: func(fs *flag.FlagSet, opts *Options) {
+			fileFlag(fs, opts)
+			fs.StringVar(&opts.GroupBy, "group-by", app.ColumnStorageCondition, i18n.T("相容性检查的分组列，同组视为同处储存"))
+			fs.StringVar(&opts.IncompatibleOut, "incompatible-out", "./docs/incompatible_report.csv", i18n.T("同处储存的不相容试剂对报告"))
+		}},
+		"expiry": {run: ExpiryRun, flags: func(fs *flag.FlagSet, opts *Options) {
+			fileFlag(fs, opts)
+			fs.IntVar(&opts.ExpiryDays, "days", 30, i18n.T("过期检查中视为即将过期的天数"))
+			fs.StringVar(&opts.AsOf, "as-of", "", i18n.T("过期检查的日期，格式 2006-01-02，默认当天"))
+			fs.StringVar(&opts.ExpiryOut, "expiry-out", "./docs/expiry_report.csv", i18n.T("已过期、即将过期和无法识别的记录报告"))
+		}},
+		"labels": {run: LabelsRun, flags: func(fs *flag.FlagSet, opts *Options) {
+			fileFlag(fs, opts)
+			fs.StringVar(&opts.Layout, "layout", "l7163", i18n.Sprintf("标签纸规格 (%s)", strings.Join(label.LayoutNames(), "|")))
+			fs.StringVar(&opts.Rows, "rows", "", i18n.T("打印标签的行号，如 2-20,25，默认所有行"))
+			fs.StringVar(&opts.LabelOut, "label-out", "./docs/labels.pdf", i18n.T("标签输出文件，.pdf 或 .svg，SVG多页时按页编号"))
+		}},
+		"sds": {run: SDSRun, flags: func(fs *flag.FlagSet, opts *Options) {
+			writeFlags(fs, opts)
+			lookupFlags(fs, opts)
+		}},
+		"ingest-sds": {run: IngestSDSRun, flags: func(fs *flag.FlagSet, opts *Options) {
+			writeFlags(fs, opts)
+			fs.StringVar(&opts.SDSDir, "sds-dir", "./docs/sds", i18n.T("本地SDS文件(PDF或文本)目录，文件名或第1部分中须含CAS号"))
+		}},
+		"seed": {run: SeedRun, flags: func(fs *flag.FlagSet, opts *Options) {
+			fileFlag(fs, opts)
+			dbFlag(fs, opts)
+		}},
+		"export": {run: ExportRun, flags: func(fs *flag.FlagSet, opts *Options) {
+			fileFlag(fs, opts)
+			dbFlag(fs, opts)
+			fs.StringVar(&opts.ExportFrom, "export-from", "workbook", i18n.T("导出来源 (workbook|db)"))
+			fs.StringVar(&opts.ExportOut, "export-out", "./docs/export.jsonl", i18n.T("导出文件，按扩展名选择格式 (.jsonl|.csv|.sdf)"))
+		}},
+		"import": {run: ImportRun, flags: func(fs *flag.FlagSet, opts *Options) {
+			writeFlags(fs, opts)
+			dbFlag(fs, opts)
+			fs.StringVar(&opts.ImportIn, "import-in", "", i18n.T("导入文件 (.jsonl|.csv|.sdf)，按CAS号更新或追加行"))
+		}},
+		"serve": {run: ServeRun, flags: func(fs *flag.FlagSet, opts *Options) {
+			lookupFlags(fs, opts)
+			provenanceFlags(fs, opts)
+			fs.StringVar(&opts.Addr, "addr", "127.0.0.1:8080", i18n.T("查询接口的监听地址，默认只接受本机访问"))
+			cacheFlag(fs, opts)
+			fs.StringVar(&opts.WebDir, "web-dir", "", i18n.T("网页界面上传文件和结果的保存目录，默认系统临时目录"))
+		}},
+		"watch": {run: WatchRun, flags: func(fs *flag.FlagSet, opts *Options) {
+			lookupFlags(fs, opts)
+			provenanceFlags(fs, opts)
+			cacheFlag(fs, opts)
+			fs.StringVar(&opts.WatchDir, "watch-dir", "./docs/inbox", i18n.T("watch 命令监视的目录，处理其中新增或修改过的 .xlsx 文件"))
+			fs.StringVar(&opts.WatchOut, "watch-out", "./docs/enriched", i18n.T("填充后的副本和报告的输出目录"))
+			fs.DurationVar(&opts.WatchInterval, "watch-interval", time.Minute, i18n.T("扫描目录的间隔"))
+			fs.DurationVar(&opts.WatchSettle, "watch-settle", 10*time.Second, i18n.T("文件修改后等待该时长再处理，避免读取正在复制的文件"))
+			fs.BoolVar(&opts.WatchOnce, "once", false, i18n.T("只扫描一次后退出，用于定时任务"))
+			fs.StringVar(&opts.Columns, "columns", strings.Join(app.EnrichColumns, ","), i18n.T("填充的列，逗号分隔"))
+		}},
+	}
+)
+
+// usage 输出命令列表，fs 不为空时再输出该命令的参数
+func usage(fs *flag.FlagSet) {
+	w := io.Writer(os.Stderr)
+	if fs != nil {
+		w = fs.Output()
+	}
+	fmt.Fprint(w, i18n.Sprintf("用法: %s [%s] [参数]\n", os.Args[0], strings.Join(commandNames, "|")))
+	if fs != nil {
+		fs.PrintDefaults()
+	}
+}
+
+// newFlagSet 创建子命令的参数集，只包含该命令使用的参数和所有命令共用的日志、语言参数
+func newFlagSet(name string, cmd command, opts *Options) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	cmd.flags(fs, opts)
+	if batchCommands[name] {
+		fs.StringVar(&opts.MetricsOut, "metrics-out", "", i18n.T("批量命令结束时保存监控指标 (Prometheus 文本格式) 的文件，\"-\" 表示标准错误，为空时不保存"))
+	}
+	commonFlags(fs, opts)
+	fs.Usage = func() { usage(fs) }
+	return fs
+}
+
+// Execute 解析命令行参数并执行对应的子命令
+func Execute(args []string) {
+	name := "chemical"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	// 帮助文本在定义参数时就需要确定语言，因此先于解析取出 -lang
+	i18n.Set(langOf(args))
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprint(os.Stderr, i18n.Sprintf("未知命令: %s\n", name))
+		usage(nil)
+		os.Exit(2)
+	}
+
+	var opts Options
+	fs := newFlagSet(name, cmd, &opts)
+	fs.Parse(args)
+	if err := setupLogging(opts.LogLevel, opts.LogFormat); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		fs.Usage()
+		os.Exit(2)
+	}
+	if opts.Enriched && opts.Output == "" {
+		opts.Output = app.EnrichedPath(opts.FilePath)
+	}
+
+	cmd.run(opts)
+
+	if batchCommands[name] {
+		if err := saveMetrics(opts.MetricsOut); err != nil {
+			slog.Error("保存监控指标失败", "file", opts.MetricsOut, "err", err)
+		}
+	}
+}
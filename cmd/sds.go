@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"cas.mod/internal/app"
+	"cas.mod/internal/i18n"
+)
+
+// sdsNotFound 既没有已有链接也没有从数据源找到时写入状态列的文字
+const sdsNotFound = "未找到"
+
+// SDSRun 检查msds链接列中的已有链接，为空或失效时从数据源页面查找新链接，
+// 并将检查状态和日期写入 msds状态、msds检查日期 列
+func SDSRun(opts Options) {
+	processor := &app.ExcelProcessor{FilePath: opts.FilePath}
+	records, err := processor.Records()
+	if err != nil {
+		fatal("处理失败", "file", opts.FilePath, "err", err)
+	}
+
+	writer, err := newResultWriter(opts)
+	if err != nil {
+		fatal("创建写回目标失败", "err", err)
+	}
+	defer closeWriter(writer)
+
+	cw, ok := writer.(app.ColumnWriter)
+	if !ok {
+		fatal("写回目标不支持追加列")
+	}
+	if err := cw.EnsureColumns("Sheet1", app.SDSColumns); err != nil {
+		fatal("追加链接检查列失败", "err", err)
+	}
+
+	client := httpClient(opts)
+	chain := providers(opts, func(info *app.ChemicalInfo) bool { return info.SDSURL != "" })
+	checked := time.Now().Format("2006-01-02")
+	valid := 0
+
+	for _, r := range records {
+		cas := r.Get(app.ColumnCAS)
+		if cas == "" {
+			continue
+		}
+
+		link := r.Get(app.FieldSDS)
+		status := i18n.T(sdsNotFound)
+		var ok bool
+		if link != "" {
+			ls := app.CheckLink(client, link)
+			status, ok = ls.String(), ls.OK()
+		}
+
+		// 没有链接或链接失效时从数据源查找，失效的链接只在 -force 时替换，
+		// 否则保留原链接和失效状态，新链接记入冲突报告
+		if !ok {
+			if found, ls := discoverSDS(chain, client, cas, link); found != nil {
+				err := writer.WriteCell(app.CellUpdate{
+					Sheet:    "Sheet1",
+					Column:   app.FieldSDS,
+					Row:      r.Row,
+					NewValue: ls.URL,
+					Source:   found.SourceURL,
+					Provider: found.Provider,
+				})
+				var conflict *app.ConflictError
+				if errors.As(err, &conflict) {
+					slog.Warn("已有链接失效，新链接未写入", "row", r.Row, "cas", cas, "url", ls.URL, "old", link)
+				} else if err != nil {
+					slog.Warn("写入失败", "row", r.Row, "column", app.FieldSDS, "err", err)
+				} else {
+					slog.Info("找到新链接", "row", r.Row, "cas", cas, "url", ls.URL, "old", link)
+					status, ok = ls.String(), true
+				}
+			}
+		}
+		if ok {
+			valid++
+		} else {
+			slog.Warn("没有可用的SDS", "row", r.Row, "cas", cas, "status", status)
+		}
+
+		for _, cell := range [][2]string{{app.ColumnSDSStatus, status}, {app.ColumnSDSChecked, checked}} {
+			if r.Get(cell[0]) == cell[1] {
+				continue
+			}
+			err := writer.WriteCell(app.CellUpdate{
+				Sheet:     "Sheet1",
+				Column:    cell[0],
+				Row:       r.Row,
+				NewValue:  cell[1],
+				Overwrite: true,
+			})
+			if err != nil {
+				slog.Warn("写入失败", "row", r.Row, "column", cell[0], "err", err)
+			}
+		}
+	}
+	slog.Info("SDS 检查完成", "valid", valid)
+
+	reportConflicts(writer, opts)
+}
+
+// discoverSDS 依次从各数据源查找SDS链接，返回第一个与已有链接不同且可以访问的查询结果和检查结果
+func discoverSDS(chain app.ProviderChain, client *http.Client, cas, current string) (*app.ChemicalInfo, app.LinkStatus) {
+	var last app.LinkStatus
+	for _, p := range chain {
+		info, err := p.Lookup(cas)
+		if err != nil || info.SDSURL == "" || info.SDSURL == current {
+			continue
+		}
+		if info.Provider == "" {
+			info.Provider = p.Name()
+		}
+		ls := app.CheckLink(client, info.SDSURL)
+		if ls.OK() {
+			return info, ls
+		}
+		slog.Warn("SDS链接不可用", "cas", cas, "provider", p.Name(), "url", info.SDSURL, "status", ls.String())
+		last = ls
+	}
+	return nil, last
+}
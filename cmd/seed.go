@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"cas.mod/internal/app"
+)
+
+// localDB 打开运行参数指定的本地试剂库，未指定或读取失败时返回 nil
+func localDB(opts Options) *app.LocalDB {
+	if opts.DBPath == "" {
+		return nil
+	}
+	db, err := app.OpenLocalDB(opts.DBPath)
+	if err != nil {
+		slog.Warn("本地试剂库不可用", "file", opts.DBPath, "err", err)
+		return nil
+	}
+	return db
+}
+
+// SeedRun 将已整理好的工作表导入本地试剂库，已有记录按非空字段合并
+func SeedRun(opts Options) {
+	if opts.DBPath == "" {
+		fatal("未指定本地试剂库 (-db)")
+	}
+	db, err := app.OpenLocalDB(opts.DBPath)
+	if err != nil {
+		fatal("打开本地试剂库失败", "file", opts.DBPath, "err", err)
+	}
+	before := db.Len()
+
+	processor := &app.ExcelProcessor{FilePath: opts.FilePath}
+	records, err := processor.Records()
+	if err != nil {
+		fatal("处理失败", "file", opts.FilePath, "err", err)
+	}
+	n := db.Seed(records, opts.FilePath)
+
+	if err := db.Save(); err != nil {
+		fatal("保存本地试剂库失败", "file", opts.DBPath, "err", err)
+	}
+	slog.Info("导入本地试剂库完成",
+		"file", opts.FilePath,
+		"count", n,
+		"added", db.Len()-before,
+		"total", db.Len(),
+		"db", opts.DBPath,
+	)
+}
+
+// writeLocal 将本地试剂库中的值写入第 number 行的 column 列，写入成功时返回 true
+func writeLocal(writer app.ResultWriter, number int, column, value string, info *app.ChemicalInfo) bool {
+	err := writer.WriteCell(app.CellUpdate{
+		Sheet:    "Sheet1",
+		Column:   column,
+		Row:      number,
+		NewValue: value,
+		Source:   info.SourceURL,
+		Provider: "local-db",
+	})
+	if err != nil {
+		slog.Warn("写入失败", "row", number, "err", err)
+		return false
+	}
+	slog.Info("使用本地试剂库中的值", "row", number, "cas", info.CASNumber, "column", column, "value", value)
+	return true
+}
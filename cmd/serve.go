@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"log/slog"
+	"net/http"
+
+	"cas.mod/internal/app"
+	"cas.mod/internal/metrics"
+	"cas.mod/internal/server"
+	"cas.mod/internal/web"
+)
+
+// apiColumns 查询接口主要提供的字段，本地试剂库中都有值时不再在线查询
+var apiColumns = []string{app.ColumnName, app.FieldFormula, app.ColumnDensity}
+
+// ServeRun 启动本地HTTP服务：JSON查询接口和上传工作表的网页界面。
+// 本地试剂库缺少所需字段时合并在线数据源的结果，在线结果缓存在内存中
+func ServeRun(opts Options) {
+	slog.Info("查询接口和网页界面已启动", "url", "http://"+opts.Addr)
+	if err := http.ListenAndServe(opts.Addr, serveHandler(opts)); err != nil {
+		fatal("服务停止", "err", err)
+	}
+}
+
+// serveHandler 组合查询接口和网页界面的路由
+func serveHandler(opts Options) http.Handler {
+	// 在线结果由查询接口和所有填充任务共用一个缓存；是否需要在线查询按查询接口的字段
+	// 或任务选择的列决定
+	db := localDB(opts)
+	online := &app.CachedProvider{Provider: onlineProviders(opts), TTL: opts.CacheTTL}
+	merged := func(columns []string) app.Provider {
+		return &app.MergedProvider{DB: db, Online: online, Need: app.NeedColumns(columns)}
+	}
+	provider := merged(apiColumns)
+	api := (&server.Server{Provider: provider}).Handler()
+	ui := (&web.UI{Provider: provider, ProviderFor: merged, Dir: opts.WebDir, Provenance: opts.Provenance}).Handler()
+	slog.Info("数据源", "provider", provider.Name())
+
+	mux := http.NewServeMux()
+	mux.Handle("/health", api)
+	mux.Handle("/cas/", api)
+	mux.Handle("/batch", api)
+	mux.Handle("GET /metrics", metrics.Default.Handler())
+	mux.Handle("/", ui)
+	return mux
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"cas.mod/internal/app"
+	"cas.mod/internal/i18n"
+)
+
+// WatchRun 定期扫描目录中新增或修改过的工作表，查询所选列中的空值，
+// 将填充后的副本和报告写入输出目录。输出副本比原文件新时视为已处理，重启后不会重复处理
+func WatchRun(opts Options) {
+	if err := os.MkdirAll(opts.WatchOut, 0o755); err != nil {
+		fatal("创建输出目录失败", "dir", opts.WatchOut, "err", err)
+	}
+	columns := watchColumns(opts.Columns)
+	if len(columns) == 0 {
+		fatal("没有可以填充的列", "columns", opts.Columns)
+	}
+	provider := &app.CachedProvider{Provider: enrichProvider(opts, columns), TTL: opts.CacheTTL}
+
+	// failed 处理失败的文件及其修改时间，文件再次修改前不重试
+	failed := make(map[string]time.Time)
+	slog.Info("开始监视目录", "dir", opts.WatchDir, "out", opts.WatchOut, "interval", opts.WatchInterval, "columns", columns)
+	for {
+		processed := 0
+		for _, path := range pendingWorkbooks(opts, failed, time.Now()) {
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			if err := enrichWorkbook(path, opts, provider, columns); err != nil {
+				slog.Error("处理工作表失败", "file", path, "err", err)
+				failed[path] = info.ModTime()
+				continue
+			}
+			delete(failed, path)
+			processed++
+		}
+
+		if processed > 0 || opts.WatchOnce {
+			if err := saveMetrics(opts.MetricsOut); err != nil {
+				slog.Error("保存监控指标失败", "file", opts.MetricsOut, "err", err)
+			}
+		}
+		if opts.WatchOnce {
+			return
+		}
+		time.Sleep(opts.WatchInterval)
+	}
+}
+
+// watchColumns 解析逗号分隔的列名，只保留可以填充的列
+func watchColumns(list string) []string {
+	var columns []string
+	for _, c := range strings.Split(list, ",") {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		if !slices.Contains(app.EnrichColumns, c) {
+			slog.Warn("不支持填充该列，已跳过", "column", c)
+			continue
+		}
+		columns = append(columns, c)
+	}
+	return columns
+}
+
+// pendingWorkbooks 返回需要处理的工作表：没有输出副本或原文件更新过，
+// 且最近 WatchSettle 内没有修改（供应商可能仍在复制文件）
+func pendingWorkbooks(opts Options, failed map[string]time.Time, now time.Time) []string {
+	entries, err := os.ReadDir(opts.WatchDir)
+	if err != nil {
+		slog.Error("读取监视目录失败", "dir", opts.WatchDir, "err", err)
+		return nil
+	}
+
+	var pending []string
+	for _, e := range entries {
+		name := e.Name()
+		// 跳过 Excel 的锁文件和本程序生成的副本
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(name), ".xlsx") ||
+			strings.HasPrefix(name, "~$") || strings.HasSuffix(name, ".enriched.xlsx") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		mtime := info.ModTime()
+		if now.Sub(mtime) < opts.WatchSettle {
+			continue
+		}
+		path := filepath.Join(opts.WatchDir, name)
+		if t, ok := failed[path]; ok && t.Equal(mtime) {
+			continue
+		}
+		if out, err := os.Stat(watchOutput(opts, name)); err == nil && !out.ModTime().Before(mtime) {
+			continue
+		}
+		pending = append(pending, path)
+	}
+	return pending
+}
+
+// watchOutput 返回输出目录中的副本路径，如 inbox/a.xlsx -> out/a.enriched.xlsx
+func watchOutput(opts Options, name string) string {
+	return app.EnrichedPath(filepath.Join(opts.WatchOut, name))
+}
+
+// watchReport 返回副本旁的报告路径，如 inbox/a.xlsx -> out/a.report.csv
+func watchReport(opts Options, name string) string {
+	return filepath.Join(opts.WatchOut, strings.TrimSuffix(name, filepath.Ext(name))+".report.csv")
+}
+
+// enrichWorkbook 填充一个工作表：先写入临时副本，完成后再改名，
+// 中途退出不会留下看起来已处理的副本
+func enrichWorkbook(path string, opts Options, provider app.Provider, columns []string) error {
+	name := filepath.Base(path)
+	logger := slog.With("file", path)
+
+	processor := &app.ExcelProcessor{FilePath: path}
+	sheet, headers, records, err := processor.HeadersAndRecords()
+	if err != nil {
+		return err
+	}
+
+	out := watchOutput(opts, name)
+	tmp := strings.TrimSuffix(out, ".xlsx") + ".partial.xlsx"
+	if err := app.CopyFile(path, tmp); err != nil {
+		return i18n.Errorf("创建输出文件失败: %v", err)
+	}
+	defer os.Remove(tmp)
+
+	rw := &app.RecordingWriter{ExcelWriter: &app.ExcelWriter{FilePath: tmp, Provenance: opts.Provenance}}
+	p, err := app.Enrich(sheet, headers, records, provider, columns, rw, nil)
+	if cerr := rw.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	entries := watchEntries(records, rw, tmp, columns)
+	if err := app.SaveEnrichReport(entries, watchReport(opts, name)); err != nil {
+		return i18n.Errorf("保存填充报告失败: %v", err)
+	}
+	if err := os.Rename(tmp, out); err != nil {
+		return i18n.Errorf("创建输出文件失败: %v", err)
+	}
+
+	logger.Info("工作表处理完成",
+		"out", out,
+		"rows", p.Total,
+		"failed", p.Failed,
+		"written", p.Written,
+		"conflicts", len(rw.Conflicts()),
+	)
+	return nil
+}
+
+// watchEntries 汇总写入、冲突和填充后仍为空的单元格
+func watchEntries(records []app.Record, rw *app.RecordingWriter, output string, columns []string) []app.EnrichEntry {
+	casByRow := make(map[int]string, len(records))
+	for _, r := range records {
+		casByRow[r.Row] = r.Get(app.ColumnCAS)
+	}
+
+	var entries []app.EnrichEntry
+	for _, u := range rw.Changes {
+		entries = append(entries, app.EnrichEntry{
+			Row: u.Row, CAS: casByRow[u.Row], Column: u.Column,
+			Old: u.OldValue, New: u.NewValue, Source: u.Source, Action: app.ActionWritten,
+		})
+	}
+	for _, u := range rw.Conflicts() {
+		entries = append(entries, app.EnrichEntry{
+			Row: u.Row, CAS: casByRow[u.Row], Column: u.Column,
+			Old: u.OldValue, New: u.NewValue, Source: u.Source, Action: app.ActionConflict,
+		})
+	}
+
+	processor := &app.ExcelProcessor{FilePath: output}
+	for _, column := range columns {
+		empty, err := processor.GetCASByEmptyColumn(column)
+		if err != nil {
+			continue
+		}
+		for row, cas := range empty {
+			entries = append(entries, app.EnrichEntry{Row: row, CAS: cas, Column: column, Action: app.ActionStillEmpty})
+		}
+	}
+
+	slices.SortStableFunc(entries, func(a, b app.EnrichEntry) int {
+		if a.Row != b.Row {
+			return a.Row - b.Row
+		}
+		return slices.Index(columns, a.Column) - slices.Index(columns, b.Column)
+	})
+	return entries
+}
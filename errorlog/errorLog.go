@@ -3,8 +3,11 @@ package errorlog
 import (
 	"bufio"
 	"fmt"
+	"log/slog"
 	"os"
 	"time"
+
+	"cas.mod/internal/i18n"
 )
 
 // LogError 记录错误状态码和URL到文件
@@ -12,7 +15,7 @@ func LogError(statusCode int, url string) error {
 	// 打开文件（如果不存在则创建，以追加模式打开）
 	file, err := os.OpenFile("./errorlog/error_log.txt", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
 	if err != nil {
-		return fmt.Errorf("无法打开错误日志文件: %v", err)
+		return i18n.Errorf("无法打开错误日志文件: %v", err)
 	}
 	defer file.Close()
 
@@ -28,14 +31,16 @@ func LogError(statusCode int, url string) error {
 	// 写入文件
 	_, err = writer.WriteString(logEntry)
 	if err != nil {
-		return fmt.Errorf("写入错误日志失败: %v", err)
+		return i18n.Errorf("写入错误日志失败: %v", err)
 	}
 
 	// 确保内容刷新到磁盘
 	err = writer.Flush()
 	if err != nil {
-		return fmt.Errorf("刷新错误日志失败: %v", err)
+		return i18n.Errorf("刷新错误日志失败: %v", err)
 	}
 
+	slog.Debug("已记录错误URL", "status", statusCode, "url", url, "file", file.Name())
+
 	return nil
 }
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cas.mod/internal/i18n"
+)
+
+// EnrichedPath 生成默认的输出文件路径，如 ReagentModules.xlsx -> ReagentModules.enriched.xlsx
+func EnrichedPath(filePath string) string {
+	ext := filepath.Ext(filePath)
+	return strings.TrimSuffix(filePath, ext) + ".enriched" + ext
+}
+
+// BackupFile 在原文件旁创建带时间戳的备份，返回备份文件路径
+func BackupFile(filePath string, now time.Time) (string, error) {
+	ext := filepath.Ext(filePath)
+	backupPath := fmt.Sprintf("%s.%s.bak%s", strings.TrimSuffix(filePath, ext), now.Format("20060102-150405"), ext)
+
+	if err := CopyFile(filePath, backupPath); err != nil {
+		return "", i18n.Errorf("备份文件失败: %v", err)
+	}
+	return backupPath, nil
+}
+
+// CopyFile 复制文件，目标文件已存在时覆盖
+func CopyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	// 先写入临时文件再重命名，避免中途失败留下不完整的文件
+	tmp, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), dst)
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// CachedProvider 在内存中缓存查询结果，未找到也缓存，避免重复请求网站
+type CachedProvider struct {
+	Provider Provider
+	TTL      time.Duration // 缓存有效期，为 0 时不过期
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	hits    int
+	misses  int
+}
+
+type cacheEntry struct {
+	info    *ChemicalInfo
+	err     error
+	expires time.Time
+}
+
+// Name 实现 Provider
+func (cp *CachedProvider) Name() string {
+	return cp.Provider.Name()
+}
+
+// Lookup 实现 Provider，返回缓存结果的副本
+func (cp *CachedProvider) Lookup(casNumber string) (*ChemicalInfo, error) {
+	cp.mu.Lock()
+	entry, ok := cp.entries[casNumber]
+	if ok && (entry.expires.IsZero() || time.Now().Before(entry.expires)) {
+		cp.hits++
+		cp.mu.Unlock()
+		cacheLookups.Inc("hit")
+		return entry.copy()
+	}
+	cp.misses++
+	cp.mu.Unlock()
+	cacheLookups.Inc("miss")
+
+	info, err := cp.Provider.Lookup(casNumber)
+	// 网络错误等临时失败不缓存
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	entry = cacheEntry{info: info, err: err}
+	if cp.TTL > 0 {
+		entry.expires = time.Now().Add(cp.TTL)
+	}
+	cp.mu.Lock()
+	if cp.entries == nil {
+		cp.entries = make(map[string]cacheEntry)
+	}
+	cp.entries[casNumber] = entry
+	cp.mu.Unlock()
+	return entry.copy()
+}
+
+func (e cacheEntry) copy() (*ChemicalInfo, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	c := *e.info
+	return &c, nil
+}
+
+// Stats 返回缓存命中和未命中的次数
+func (cp *CachedProvider) Stats() (hits, misses int) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return cp.hits, cp.misses
+}
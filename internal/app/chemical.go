@@ -1,104 +1,78 @@
 package app
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"regexp"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
 )
 
-// ParseChemical 解析化学式
-func ParseChemical(htmlContent string, unknown int, number int) {
+// ParseChemical 解析化学式，找到后通过 w 写回第 number 行，写入成功时返回 true
+func ParseChemical(htmlContent string, number int, w ResultWriter, sourceURL string) bool {
+	logger := slog.With("row", number, "url", sourceURL)
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
 	if err != nil {
-		log.Fatal("解析HTML错误: ", err)
+		logger.Error("解析HTML失败", "err", err)
+		return false
 	}
 
-	// 查找分子式
-	var molecularFormula string
-	doc.Find("table.ChemicalInfo tr").Each(func(i int, s *goquery.Selection) {
-		// 查找包含"分子式"文本的单元格
-		if strings.Contains(s.Text(), "分子式") {
-			// 获取相邻单元格的内容
-			s.Find("td").Each(func(j int, td *goquery.Selection) {
-				if j == 1 { // 第二个td包含分子式
-					molecularFormula = td.Text()
-					molecularFormula = strings.TrimSpace(molecularFormula)
-				}
+	molecularFormula := ExtractFields(doc, ChemicalRules)[FieldFormula]
+	observeParse(FieldFormula, molecularFormula != "")
+	if molecularFormula == "" {
+		logger.Warn("未找到分子式")
+		// 页面结构变化时用于排查，只在调试级别输出
+		if logger.Enabled(context.Background(), slog.LevelDebug) {
+			doc.Find("table.ChemicalInfo tr").Each(func(i int, s *goquery.Selection) {
+				logger.Debug("表格行", "index", i, "text", s.Text())
 			})
 		}
-	})
-
-	if molecularFormula != "" {
-		log.Printf("number: %v", number)
-		log.Printf("找到分子式: %s\n", molecularFormula)
-		WriteToCell("ReagentModules.xlsx", "Sheet1", "化学式", number, molecularFormula)
-	} else {
-		unknown++
-		// 尝试查看实际内容（调试用）
-		log.Println("未找到分子式。可能的表格行:")
-		doc.Find("table.ChemicalInfo tr").Each(func(i int, s *goquery.Selection) {
-			log.Printf("行 %d: %s\n", i, s.Text())
-		})
-		log.Println("尝试使用CSS选择器定位分子式")
-
-		// 直接使用CSS选择器定位分子式行
-		doc.Find("tr:has(td.ltd:contains('分子式'))").Each(func(i int, s *goquery.Selection) {
-			s.Find("td").Each(func(j int, td *goquery.Selection) {
-				if j == 1 {
-					molecularFormula = td.Text()
-					molecularFormula = strings.TrimSpace(molecularFormula)
-					log.Printf("通过CSS选择器找到分子式: %s\n", molecularFormula)
-				}
-			})
-		})
+		return false
 	}
-}
 
-// ParseDensity 解析化学式
-func ParseDensity(htmlContent string, unknown int, number int) {
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+	logger.Info("找到分子式", "formula", molecularFormula)
+	err = w.WriteCell(CellUpdate{
+		Sheet:    "Sheet1",
+		Column:   FieldFormula,
+		Row:      number,
+		NewValue: molecularFormula,
+		Source:   sourceURL,
+		Provider: "ichemistry",
+	})
 	if err != nil {
-		log.Fatal("解析HTML错误: ", err)
+		logger.Warn("写入失败", "column", FieldFormula, "err", err)
+		return false
 	}
+	return true
+}
 
-	// 查找分子式
-	var molecularFormula string
-	doc.Find("table.ChemicalInfo tr").Each(func(i int, s *goquery.Selection) {
-		// 查找包含"分子式"文本的单元格
-		if strings.Contains(s.Text(), "分子式") {
-			// 获取相邻单元格的内容
-			s.Find("td").Each(func(j int, td *goquery.Selection) {
-				if j == 1 { // 第二个td包含分子式
-					molecularFormula = td.Text()
-					molecularFormula = strings.TrimSpace(molecularFormula)
-				}
-			})
-		}
-	})
+// densityNumber 匹配密度描述中的数值，如 "0.789 g/mL at 25 °C" 中的 0.789
+var densityNumber = regexp.MustCompile(`\d+(\.\d+)?`)
 
-	if molecularFormula != "" {
-		log.Printf("number: %v", number)
-		log.Printf("找到分子式: %s\n", molecularFormula)
-		WriteToCell("ReagentModules.xlsx", "Sheet1", "化学式", number, molecularFormula)
-	} else {
-		unknown++
-		// 尝试查看实际内容（调试用）
-		log.Println("未找到分子式。可能的表格行:")
-		doc.Find("table.ChemicalInfo tr").Each(func(i int, s *goquery.Selection) {
-			log.Printf("行 %d: %s\n", i, s.Text())
-		})
-		log.Println("尝试使用CSS选择器定位分子式")
+// ParseDensity 解析密度，找到后将数值通过 w 写回第 number 行的相对密度列，写入成功时返回 true
+func ParseDensity(htmlContent string, number int, w ResultWriter, sourceURL string) bool {
+	logger := slog.With("row", number, "url", sourceURL)
+	density := Density(htmlContent)
+	value := densityNumber.FindString(density)
+	observeParse(FieldDensity, value != "")
+	if value == "" {
+		logger.Warn("未找到密度")
+		return false
+	}
 
-		// 直接使用CSS选择器定位分子式行
-		doc.Find("tr:has(td.ltd:contains('分子式'))").Each(func(i int, s *goquery.Selection) {
-			s.Find("td").Each(func(j int, td *goquery.Selection) {
-				if j == 1 {
-					molecularFormula = td.Text()
-					molecularFormula = strings.TrimSpace(molecularFormula)
-					log.Printf("通过CSS选择器找到分子式: %s\n", molecularFormula)
-				}
-			})
-		})
+	logger.Info("找到密度", "density", value, "text", density)
+	err := w.WriteCell(CellUpdate{
+		Sheet:    "Sheet1",
+		Column:   ColumnDensity,
+		Row:      number,
+		NewValue: value,
+		Source:   sourceURL,
+		Provider: "chemsrc",
+	})
+	if err != nil {
+		logger.Warn("写入失败", "column", ColumnDensity, "err", err)
+		return false
 	}
+	return true
 }
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"testing"
+
+	"cas.mod/internal/standin"
+)
+
+// recordWriter 记录写入请求的 ResultWriter，用于测试
+type recordWriter struct {
+	updates []CellUpdate
+}
+
+func (rw *recordWriter) WriteCell(u CellUpdate) error {
+	rw.updates = append(rw.updates, u)
+	return nil
+}
+
+func (rw *recordWriter) Close() error {
+	return nil
+}
+
+func TestParseChemical(t *testing.T) {
+	tests := []struct {
+		cas     string
+		row     int
+		formula string // 为空表示不应写入
+	}{
+		{cas: "7664-93-9", row: 2, formula: "H2SO4"},
+		{cas: "1002-16-0", row: 3, formula: "C5H11NO3"},
+		{cas: "10049-21-5", row: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cas, func(t *testing.T) {
+			page, err := standin.Fixture(standin.Ichemistry, tt.cas)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			w := &recordWriter{}
+			ParseChemical(page, tt.row, w, "fixture://"+tt.cas)
+
+			if tt.formula == "" {
+				if len(w.updates) != 0 {
+					t.Fatalf("不应写入，实际写入 %+v", w.updates)
+				}
+				return
+			}
+
+			if len(w.updates) != 1 {
+				t.Fatalf("应写入 1 次，实际 %d 次", len(w.updates))
+			}
+			u := w.updates[0]
+			if u.Column != "化学式" || u.Row != tt.row || u.NewValue != tt.formula {
+				t.Errorf("写入 %+v，期望第 %d 行 化学式=%s", u, tt.row, tt.formula)
+			}
+			if u.Source != "fixture://"+tt.cas {
+				t.Errorf("来源 = %q", u.Source)
+			}
+		})
+	}
+}
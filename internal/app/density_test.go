@@ -0,0 +1,31 @@
+package app
+
+import (
+	"testing"
+
+	"cas.mod/internal/standin"
+)
+
+func TestDensity(t *testing.T) {
+	tests := []struct {
+		cas  string
+		want string
+	}{
+		{cas: "64-17-5", want: "0.789 g/mL at 25 °C(lit.)"},  // baseTbl 表格
+		{cas: "108-88-3", want: "0.865 g/mL at 25 °C(lit.)"}, // wuHuaDiv 中的 th/td
+		{cas: "5324-84-5", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cas, func(t *testing.T) {
+			page, err := standin.Fixture(standin.Chemsrc, tt.cas)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := Density(page); got != tt.want {
+				t.Errorf("Density() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
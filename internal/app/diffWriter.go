@@ -0,0 +1,133 @@
+package app
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"cas.mod/internal/i18n"
+	"github.com/xuri/excelize/v2"
+)
+
+// DiffWriter 预演模式的写回目标，只记录变更不修改Excel文件
+type DiffWriter struct {
+	FilePath string    // 用于读取原值的Excel文件
+	Out      io.Writer // 差异输出
+	Force    bool      // 与 ExcelWriter.Force 一致，决定已有值的单元格是否会被覆盖
+
+	f         *excelize.File
+	columns   map[string]map[string]int // 各工作表表头文字对应的列号，与 ExcelWriter 相同只读取一次
+	conflicts []CellUpdate
+	added     map[string]bool // 实际运行时会追加的列
+	csv       *csv.Writer
+	changes   int
+}
+
+// NewDiffWriter 创建预演写入器，差异以CSV格式输出到 out
+func NewDiffWriter(filePath string, out io.Writer) *DiffWriter {
+	return &DiffWriter{FilePath: filePath, Out: out}
+}
+
+// WriteCell 读取单元格原值并记录一条差异
+func (dw *DiffWriter) WriteCell(u CellUpdate) error {
+	if dw.f == nil {
+		f, err := excelize.OpenFile(dw.FilePath)
+		if err != nil {
+			return i18n.Errorf("打开文件失败: %v", err)
+		}
+		dw.f = f
+		dw.columns = make(map[string]map[string]int)
+		dw.csv = csv.NewWriter(dw.Out)
+		if err := dw.csv.Write([]string{"sheet", "row", "column", "old", "new", "source", "action"}); err != nil {
+			return i18n.Errorf("写入差异失败: %v", err)
+		}
+	}
+
+	if u.Sheet == "" {
+		u.Sheet = "Sheet1"
+	}
+
+	index, ok := dw.columns[u.Sheet]
+	if !ok {
+		var err error
+		if index, err = readHeaderIndex(dw.f, u.Sheet); err != nil {
+			return err
+		}
+		dw.columns[u.Sheet] = index
+	}
+
+	colIndex, ok := index[u.Column]
+	switch {
+	case ok:
+		cellName, err := excelize.CoordinatesToCellName(colIndex, u.Row)
+		if err != nil {
+			return err
+		}
+		u.OldValue, err = cellValueOrPicture(dw.f, u.Sheet, cellName)
+		if err != nil {
+			return err
+		}
+	case dw.added[u.Column]:
+		// 新追加的列原值为空
+	case len(index) == 0:
+		return i18n.Errorf("工作表为空")
+	default:
+		return i18n.Errorf("未找到列名: %s", u.Column)
+	}
+
+	// 与 ExcelWriter 一致，值没有变化时实际运行不会写入
+	if u.OldValue == u.NewValue {
+		return nil
+	}
+
+	// 标出实际运行时会因单元格已有值而跳过的变更
+	action := "write"
+	if !dw.Force && !u.Overwrite && !isEmptyValue(u.OldValue) {
+		action = "conflict"
+		dw.conflicts = append(dw.conflicts, u)
+	}
+
+	record := []string{u.Sheet, strconv.Itoa(u.Row), u.Column, u.OldValue, u.NewValue, u.Source, action}
+	if err := dw.csv.Write(record); err != nil {
+		return i18n.Errorf("写入差异失败: %v", err)
+	}
+	dw.csv.Flush()
+	dw.changes++
+
+	return dw.csv.Error()
+}
+
+// WritePicture 记录一条图片嵌入的差异
+func (dw *DiffWriter) WritePicture(u CellUpdate, imagePath string) error {
+	u.NewValue = "[图片] " + imagePath
+	return dw.WriteCell(u)
+}
+
+// EnsureColumns 记录实际运行时会追加的列，不修改Excel文件
+func (dw *DiffWriter) EnsureColumns(sheetName string, columns []string) error {
+	if dw.added == nil {
+		dw.added = make(map[string]bool)
+	}
+	for _, column := range columns {
+		dw.added[column] = true
+	}
+	return nil
+}
+
+// Changes 返回已记录的变更数量
+func (dw *DiffWriter) Changes() int {
+	return dw.changes
+}
+
+// Conflicts 返回实际运行时会被跳过的变更
+func (dw *DiffWriter) Conflicts() []CellUpdate {
+	return dw.conflicts
+}
+
+// Close 关闭读取的Excel文件
+func (dw *DiffWriter) Close() error {
+	if dw.f == nil {
+		return nil
+	}
+	return dw.f.Close()
+}
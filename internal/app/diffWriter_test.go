@@ -0,0 +1,57 @@
+package app
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestDiffWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reagents.xlsx")
+	f := excelize.NewFile()
+	f.SetSheetRow("Sheet1", "A1", &[]string{ColumnCAS, FieldFormula, ColumnDensity})
+	f.SetSheetRow("Sheet1", "A2", &[]string{"7664-93-9", "", "1.84"})
+	f.SetSheetRow("Sheet1", "A3", &[]string{"64-17-5", "C2H6O", ""})
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	var out bytes.Buffer
+	dw := NewDiffWriter(path, &out)
+	defer dw.Close()
+	if err := dw.EnsureColumns("Sheet1", []string{FieldInChIKey}); err != nil {
+		t.Fatal(err)
+	}
+	updates := []CellUpdate{
+		{Column: FieldFormula, Row: 2, NewValue: "H2SO4"},
+		{Column: FieldFormula, Row: 3, NewValue: "C2H6O"}, // 值相同，不记录
+		{Column: ColumnDensity, Row: 2, NewValue: "1.83"},
+		{Column: FieldInChIKey, Row: 2, NewValue: "QAOWNCQODCNURD-UHFFFAOYSA-N"},
+	}
+	for _, u := range updates {
+		if err := dw.WriteCell(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := dw.WriteCell(CellUpdate{Column: "不存在", Row: 2, NewValue: "x"}); err == nil {
+		t.Error("未知列应返回错误")
+	}
+
+	want := strings.Join([]string{
+		"sheet,row,column,old,new,source,action",
+		"Sheet1,2,化学式,,H2SO4,,write",
+		"Sheet1,2," + ColumnDensity + ",1.84,1.83,,conflict",
+		"Sheet1,2,InChIKey,,QAOWNCQODCNURD-UHFFFAOYSA-N,,write",
+		"",
+	}, "\n")
+	if out.String() != want {
+		t.Errorf("差异 =\n%s\nwant\n%s", out.String(), want)
+	}
+	if dw.Changes() != 3 || len(dw.Conflicts()) != 1 {
+		t.Errorf("Changes = %d, Conflicts = %v", dw.Changes(), dw.Conflicts())
+	}
+}
@@ -0,0 +1,169 @@
+package app
+
+import (
+	"encoding/csv"
+	"errors"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"cas.mod/internal/i18n"
+)
+
+// EnrichColumns 按列填充时可以选择的列，值取自 InfoValues
+var EnrichColumns = []string{
+	FieldFormula, FieldEnglishName, ColumnDensity,
+	FieldSMILES, FieldInChI, FieldInChIKey, FieldPubChemCID,
+	ColumnHazards, ColumnPrevention, ColumnResponse, ColumnStorage, ColumnDisposal, ColumnLabel,
+	FieldSDS,
+}
+
+// NeedColumns 返回检查查询结果中所选列是否都有值的函数，用作本地试剂库的 Need
+func NeedColumns(columns []string) func(*ChemicalInfo) bool {
+	return func(info *ChemicalInfo) bool {
+		values := InfoValues(info)
+		for _, column := range columns {
+			if values[column] == "" {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// EnrichProgress 按列填充的进度
+type EnrichProgress struct {
+	Total   int // 需要查询的行数
+	Done    int // 已处理的行数
+	Failed  int // 查询失败的行数
+	Written int // 已写入的单元格数
+}
+
+// Enrich 对有CAS号且所选列中有空值的行查询一次，将结果写入工作表 sheet 的所选列。
+// 已有值的单元格由 w 按冲突处理；表头中没有的列在 w 支持时追加到末尾。
+// progress 不为空时每处理一行调用一次
+func Enrich(sheet string, headers []string, records []Record, provider Provider, columns []string, w ResultWriter, progress func(EnrichProgress)) (EnrichProgress, error) {
+	existing := make(map[string]bool, len(headers))
+	for _, h := range headers {
+		existing[h] = true
+	}
+	var missing []string
+	for _, column := range columns {
+		if !existing[column] {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) > 0 {
+		cw, ok := w.(ColumnWriter)
+		if !ok {
+			return EnrichProgress{}, i18n.Errorf("写回目标不支持追加列")
+		}
+		if err := cw.EnsureColumns(sheet, missing); err != nil {
+			return EnrichProgress{}, err
+		}
+	}
+
+	var pending []Record
+	for _, r := range records {
+		if r.Get(ColumnCAS) == "" {
+			continue
+		}
+		for _, column := range columns {
+			if isEmptyValue(r.Get(column)) {
+				pending = append(pending, r)
+				break
+			}
+		}
+	}
+
+	p := EnrichProgress{Total: len(pending)}
+	if progress != nil {
+		progress(p)
+	}
+	for _, r := range pending {
+		cas := r.Get(ColumnCAS)
+		info, err := provider.Lookup(cas)
+		if err != nil {
+			slog.Warn("查询失败", "row", r.Row, "cas", cas, "err", err)
+			p.Failed++
+		} else {
+			values := InfoValues(info)
+			for _, column := range columns {
+				if values[column] == "" || values[column] == r.Get(column) {
+					continue
+				}
+				err := w.WriteCell(CellUpdate{
+					Sheet:    sheet,
+					Column:   column,
+					Row:      r.Row,
+					NewValue: values[column],
+					Source:   info.SourceURL,
+					Provider: info.Provider,
+				})
+				var conflict *ConflictError
+				if err != nil && !errors.As(err, &conflict) {
+					slog.Warn("写入失败", "row", r.Row, "cas", cas, "column", column, "err", err)
+				}
+				if err == nil {
+					p.Written++
+				}
+			}
+		}
+		p.Done++
+		if progress != nil {
+			progress(p)
+		}
+	}
+	return p, nil
+}
+
+// RecordingWriter 记录成功写入的单元格，用于差异展示和填充报告
+type RecordingWriter struct {
+	*ExcelWriter
+	Changes []CellUpdate
+}
+
+// WriteCell 实现 ResultWriter
+func (rw *RecordingWriter) WriteCell(u CellUpdate) error {
+	if err := rw.ExcelWriter.WriteCell(u); err != nil {
+		return err
+	}
+	rw.Changes = append(rw.Changes, u)
+	return nil
+}
+
+// 填充报告中每一行的处理结果
+const (
+	ActionWritten    = "written"     // 已写入
+	ActionConflict   = "conflict"    // 单元格已有值，未覆盖
+	ActionStillEmpty = "still_empty" // 填充后仍为空
+)
+
+// EnrichEntry 填充报告中的一行
+type EnrichEntry struct {
+	Row    int
+	CAS    string
+	Column string
+	Old    string
+	New    string
+	Source string
+	Action string
+}
+
+// SaveEnrichReport 将填充报告保存为CSV文件
+func SaveEnrichReport(entries []EnrichEntry, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"row", "cas", "column", "current", "new", "source", "action"})
+	for _, e := range entries {
+		w.Write([]string{strconv.Itoa(e.Row), e.CAS, e.Column, e.Old, e.New, e.Source, e.Action})
+	}
+	w.Flush()
+
+	return w.Error()
+}
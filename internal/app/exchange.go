@@ -0,0 +1,418 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cas.mod/internal/depict"
+	"cas.mod/internal/i18n"
+	"cas.mod/internal/sds"
+)
+
+// ExchangeColumns 导出本地试剂库时的列，列名与工作表表头一致
+var ExchangeColumns = []string{
+	ColumnName, ColumnCAS, FieldEnglishName, FieldFormula, ColumnDensity,
+	FieldSMILES, FieldInChI, FieldInChIKey, FieldPubChemCID,
+	ColumnProperties, ColumnHazards, ColumnPrevention, ColumnResponse, ColumnStorage,
+	ColumnDisposal, ColumnFireFighting, ColumnLabel, FieldSDS,
+}
+
+// columnAliases 导入时可识别的英文字段名，其余字段名按工作表表头原样使用
+var columnAliases = map[string]string{
+	"cas":               ColumnCAS,
+	"cas_number":        ColumnCAS,
+	"cas_no":            ColumnCAS,
+	"casrn":             ColumnCAS,
+	"name":              ColumnName,
+	"chinese_name":      ColumnName,
+	"english_name":      FieldEnglishName,
+	"formula":           FieldFormula,
+	"molecular_formula": FieldFormula,
+	"density":           ColumnDensity,
+	"relative_density":  ColumnDensity,
+	"smiles":            FieldSMILES,
+	"inchi":             FieldInChI,
+	"inchikey":          FieldInChIKey,
+	"cid":               FieldPubChemCID,
+	"pubchem_cid":       FieldPubChemCID,
+	"sds":               FieldSDS,
+	"sds_url":           FieldSDS,
+	"msds":              FieldSDS,
+	"hazards":           ColumnHazards,
+	"disposal":          ColumnDisposal,
+	"storage":           ColumnStorage,
+}
+
+// CanonicalColumn 将导入文件中的字段名映射为工作表表头
+func CanonicalColumn(key string) string {
+	key = strings.TrimSpace(key)
+	alias := strings.NewReplacer(" ", "_", "-", "_", ".", "").Replace(strings.ToLower(key))
+	if column, ok := columnAliases[alias]; ok {
+		return column
+	}
+	return key
+}
+
+// Table 导入导出的数据，每行为表头到值的映射，空值不出现
+type Table struct {
+	Columns []string
+	Rows    []map[string]string
+}
+
+// RecordsTable 将工作表记录转换为导出数据，列按表头顺序
+func RecordsTable(headers []string, records []Record) Table {
+	t := Table{Columns: headers}
+	for _, r := range records {
+		t.Rows = append(t.Rows, r.Values)
+	}
+	return t
+}
+
+// InfoValues 将化学信息按工作表的列展开，与 RecordInfo 互为逆操作
+func InfoValues(info *ChemicalInfo) map[string]string {
+	values := map[string]string{
+		ColumnName:         info.ChineseName,
+		ColumnCAS:          info.CASNumber,
+		FieldEnglishName:   info.EnglishName,
+		FieldFormula:       info.ChemicalFormula,
+		ColumnDensity:      info.Density,
+		FieldSMILES:        info.SMILES,
+		FieldInChI:         info.InChI,
+		FieldInChIKey:      info.InChIKey,
+		FieldPubChemCID:    info.PubChemCID,
+		ColumnProperties:   info.Properties,
+		ColumnFireFighting: info.FireFighting,
+		ColumnStorage:      info.Storage,
+		ColumnDisposal:     info.Disposal,
+		FieldSDS:           info.SDSURL,
+	}
+	// SDS 中的储存和废弃处置原文优先于 GHS 防范说明的标准文本
+	for column, text := range HazardCells(info.Hazard, "zh") {
+		if values[column] == "" {
+			values[column] = text
+		}
+	}
+	for column, value := range values {
+		if value == "" {
+			delete(values, column)
+		}
+	}
+	return values
+}
+
+// DBTable 将本地试剂库转换为导出数据，按CAS号排序
+func DBTable(db *LocalDB) Table {
+	t := Table{Columns: ExchangeColumns}
+	for _, cas := range db.CASNumbers() {
+		info, _ := db.Get(cas)
+		t.Rows = append(t.Rows, InfoValues(info))
+	}
+	return t
+}
+
+// 支持的交换格式
+const (
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+	FormatSDF   = "sdf"
+)
+
+// FormatOf 按扩展名判断交换格式
+func FormatOf(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	case ".csv":
+		return FormatCSV, nil
+	case ".sdf", ".sd":
+		return FormatSDF, nil
+	}
+	return "", i18n.Errorf("不支持的文件格式: %s (支持 .jsonl、.csv、.sdf)", path)
+}
+
+// SaveTable 按扩展名选择格式写入文件
+func SaveTable(t Table, path string) error {
+	format, err := FormatOf(path)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	switch format {
+	case FormatJSONL:
+		err = WriteJSONL(w, t)
+	case FormatCSV:
+		err = WriteCSV(w, t)
+	case FormatSDF:
+		err = WriteSDF(w, t)
+	}
+	if err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// LoadTable 按扩展名选择格式读取文件，字段名经 CanonicalColumn 映射为表头
+func LoadTable(path string) (Table, error) {
+	format, err := FormatOf(path)
+	if err != nil {
+		return Table{}, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return Table{}, err
+	}
+	defer file.Close()
+
+	switch format {
+	case FormatJSONL:
+		return ReadJSONL(file)
+	case FormatCSV:
+		return ReadCSV(file)
+	default:
+		return ReadSDF(file)
+	}
+}
+
+// WriteJSONL 每行输出一个JSON对象，键按列顺序排列，空值省略
+func WriteJSONL(w io.Writer, t Table) error {
+	for _, row := range t.Rows {
+		var buf bytes.Buffer
+		buf.WriteByte('{')
+		first := true
+		for _, column := range t.Columns {
+			value, ok := row[column]
+			if !ok || value == "" {
+				continue
+			}
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			buf.Write(jsonString(column))
+			buf.WriteByte(':')
+			buf.Write(jsonString(value))
+		}
+		buf.WriteString("}\n")
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonString 编码JSON字符串，不转义 <、>、&
+func jsonString(s string) []byte {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+// ReadJSONL 读取 JSON Lines，值为数字或布尔时按原文保存
+func ReadJSONL(r io.Reader) (Table, error) {
+	var t Table
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(scanner.Bytes(), &obj); err != nil {
+			return Table{}, i18n.Errorf("第 %d 行: %v", line, err)
+		}
+		row := make(map[string]string, len(obj))
+		for key, raw := range obj {
+			var value string
+			if err := json.Unmarshal(raw, &value); err != nil {
+				if string(raw) == "null" {
+					continue
+				}
+				value = string(raw)
+			}
+			t.addValue(row, seen, key, value)
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t, scanner.Err()
+}
+
+// addValue 向一行中加入字段，并按首次出现的顺序记录列
+func (t *Table) addValue(row map[string]string, seen map[string]bool, key, value string) {
+	column := CanonicalColumn(key)
+	value = strings.TrimSpace(value)
+	if column == "" || value == "" {
+		return
+	}
+	row[column] = value
+	if !seen[column] {
+		seen[column] = true
+		t.Columns = append(t.Columns, column)
+	}
+}
+
+// WriteCSV 第一行为表头
+func WriteCSV(w io.Writer, t Table) error {
+	cw := csv.NewWriter(w)
+	cw.Write(t.Columns)
+	for _, row := range t.Rows {
+		record := make([]string, len(t.Columns))
+		for i, column := range t.Columns {
+			record[i] = row[column]
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadCSV 读取带表头的CSV，忽略UTF-8 BOM
+func ReadCSV(r io.Reader) (Table, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return Table{}, err
+	}
+	var t Table
+	if len(records) == 0 {
+		return t, nil
+	}
+	headers := records[0]
+	if len(headers) > 0 {
+		headers[0] = strings.TrimPrefix(headers[0], "\uFEFF")
+	}
+	seen := make(map[string]bool)
+	for _, record := range records[1:] {
+		row := make(map[string]string)
+		for i, value := range record {
+			if i < len(headers) {
+				t.addValue(row, seen, headers[i], value)
+			}
+		}
+		if len(row) > 0 {
+			t.Rows = append(t.Rows, row)
+		}
+	}
+	return t, nil
+}
+
+// WriteSDF 输出 MDL SD 文件，每条记录的结构由 SMILES 列按 depict 布局生成，
+// 没有SMILES或无法解析时输出不含原子的结构；其余列写为数据项
+func WriteSDF(w io.Writer, t Table) error {
+	for _, row := range t.Rows {
+		name := row[ColumnName]
+		if name == "" {
+			name = row[ColumnCAS]
+		}
+		// 名称行不能含换行，MOL 格式限长80字符
+		name = strings.ReplaceAll(name, "\n", " ")
+		if r := []rune(name); len(r) > 80 {
+			name = string(r[:80])
+		}
+
+		m, err := depict.ParseSMILES(row[FieldSMILES])
+		if err != nil {
+			m = &depict.Molecule{}
+		}
+		m.Layout()
+
+		var buf bytes.Buffer
+		buf.Write(m.Molfile(name))
+		for _, column := range t.Columns {
+			value := row[column]
+			if value == "" {
+				continue
+			}
+			// 数据项以空行结束，值中的空行改为单个换行
+			lines := strings.Split(strings.ReplaceAll(value, "\r\n", "\n"), "\n")
+			fmt.Fprintf(&buf, "> <%s>\n", column)
+			for _, line := range lines {
+				if strings.TrimSpace(line) != "" {
+					buf.WriteString(line + "\n")
+				}
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString("$$$$\n")
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadSDF 读取 SD 文件中的数据项，结构部分只用于在缺少名称时取第一行
+func ReadSDF(r io.Reader) (Table, error) {
+	var t Table
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	row := make(map[string]string)
+	var header string // 结构的第一行
+	lineInRecord := 0
+	var field string
+	var value []string
+	flush := func() {
+		if field != "" {
+			t.addValue(row, seen, field, strings.Join(value, "\n"))
+		}
+		field, value = "", nil
+	}
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		lineInRecord++
+		if lineInRecord == 1 {
+			header = strings.TrimSpace(line)
+		}
+		switch {
+		case line == "$$$$":
+			flush()
+			if _, ok := row[ColumnName]; !ok && header != "" && !sds.ValidCAS(header) {
+				t.addValue(row, seen, ColumnName, header)
+			}
+			if len(row) > 0 {
+				t.Rows = append(t.Rows, row)
+			}
+			row, header, lineInRecord = make(map[string]string), "", 0
+		case strings.HasPrefix(line, ">"):
+			flush()
+			if start, end := strings.Index(line, "<"), strings.LastIndex(line, ">"); start >= 0 && end > start {
+				field = line[start+1 : end]
+			}
+		case field != "":
+			if line == "" {
+				flush()
+			} else {
+				value = append(value, line)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Table{}, err
+	}
+	// 最后一条记录缺少 $$$$ 时也保留
+	flush()
+	if len(row) > 0 {
+		t.Rows = append(t.Rows, row)
+	}
+	return t, nil
+}
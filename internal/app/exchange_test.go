@@ -0,0 +1,81 @@
+package app
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTableRoundTrip(t *testing.T) {
+	table := Table{
+		Columns: []string{ColumnName, ColumnCAS, FieldFormula, FieldSMILES, ColumnHazards},
+		Rows: []map[string]string{
+			{ColumnName: "乙醇", ColumnCAS: "64-17-5", FieldFormula: "C2H6O", FieldSMILES: "CCO", ColumnHazards: "H225 高度易燃液体和蒸气\nH319 造成严重眼刺激"},
+			{ColumnName: "硫酸 \"98%\", 分析纯", ColumnCAS: "7664-93-9", FieldFormula: "H2SO4"},
+		},
+	}
+	formats := map[string]struct {
+		write func(io.Writer, Table) error
+		read  func(io.Reader) (Table, error)
+	}{
+		FormatJSONL: {WriteJSONL, ReadJSONL},
+		FormatCSV:   {WriteCSV, ReadCSV},
+		FormatSDF:   {WriteSDF, ReadSDF},
+	}
+	for name, f := range formats {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := f.write(&buf, table); err != nil {
+				t.Fatal(err)
+			}
+			got, err := f.read(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Rows, table.Rows) {
+				t.Errorf("Rows = %v, want %v", got.Rows, table.Rows)
+			}
+		})
+	}
+}
+
+func TestWriteSDF(t *testing.T) {
+	var buf bytes.Buffer
+	table := Table{Columns: []string{ColumnCAS, FieldSMILES}, Rows: []map[string]string{{ColumnCAS: "64-17-5", FieldSMILES: "CCO"}}}
+	if err := WriteSDF(&buf, table); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	if lines[0] != "64-17-5" {
+		t.Errorf("名称行 = %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[3], "  3  2") {
+		t.Errorf("计数行 = %q", lines[3])
+	}
+	if !strings.Contains(buf.String(), "M  END\n> <CAS号>\n64-17-5\n\n> <SMILES>\nCCO\n\n$$$$\n") {
+		t.Errorf("数据项格式不正确:\n%s", buf.String())
+	}
+}
+
+func TestReadAliases(t *testing.T) {
+	csv := "\uFEFFCAS No.,Name,Molecular Formula,Supplier Code\n7664-93-9,硫酸,H2SO4,S-01\n"
+	table, err := ReadCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{ColumnCAS, ColumnName, FieldFormula, "Supplier Code"}; !reflect.DeepEqual(table.Columns, want) {
+		t.Errorf("Columns = %v, want %v", table.Columns, want)
+	}
+
+	jsonl := `{"cas":"64-17-5","density":0.789,"smiles":"CCO","pubchem_cid":702,"note":null}` + "\n"
+	table, err = ReadJSONL(strings.NewReader(jsonl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{ColumnCAS: "64-17-5", ColumnDensity: "0.789", FieldSMILES: "CCO", FieldPubChemCID: "702"}
+	if !reflect.DeepEqual(table.Rows[0], want) {
+		t.Errorf("Rows[0] = %v, want %v", table.Rows[0], want)
+	}
+}
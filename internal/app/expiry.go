@@ -0,0 +1,222 @@
+package app
+
+import (
+	"encoding/csv"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cas.mod/internal/i18n"
+	"github.com/xuri/excelize/v2"
+)
+
+// 过期检查使用的列
+const (
+	ColumnExpiry   = "过期时间"
+	ColumnSupplier = "供应商"
+)
+
+// ExpiryStatus 过期检查结果
+type ExpiryStatus string
+
+// 过期检查结果，有效的记录不出现在报告中
+const (
+	Expired     ExpiryStatus = "已过期"
+	Expiring    ExpiryStatus = "即将过期"
+	Unparseable ExpiryStatus = "无法识别"
+	Valid       ExpiryStatus = "有效"
+)
+
+// expiryLayouts 完整日期的格式，依次尝试
+var expiryLayouts = []string{
+	"2006-1-2",
+	"2006/1/2",
+	"2006.1.2",
+	"2006年1月2日",
+	"2006年1月2号",
+	"20060102",
+	"2006-1-2 15:04:05",
+	"2006/1/2 15:04:05",
+	"2006-1-2 15:04",
+	"2006/1/2 15:04",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2 Jan 2006",
+	"02-Jan-2006",
+	"2-Jan-06",
+	"Jan 2, 2006",
+	"January 2, 2006",
+}
+
+// expiryMonthLayouts 只到月份的格式，按该月最后一天过期
+var expiryMonthLayouts = []string{
+	"2006-1",
+	"2006/1",
+	"2006.1",
+	"2006年1月",
+	"200601",
+	"Jan 2006",
+	"January 2006",
+	"Jan-06",
+}
+
+// expiryPrefix 日期前常见的说明文字，如 "有效期至"、"EXP:"
+var expiryPrefix = regexp.MustCompile(`(?i)^(有效期至|有效期|失效日期|过期时间|到期|至|exp(iry|ires)?( date)?)[:：\s]*`)
+
+// compactDate 不带分隔符的年月或年月日
+var compactDate = regexp.MustCompile(`^\d{6}(\d{2})?$`)
+
+// dottedMonth 以点分隔的年月，如 "2025.06"，也是合法的小数
+var dottedMonth = regexp.MustCompile(`^\d{4}\.\d{1,2}$`)
+
+// yearOnly 只有年份，如 "2025" 或 "2025年"；四位整数按年份而不是Excel日期序列号处理
+var yearOnly = regexp.MustCompile(`^(\d{4})年?$`)
+
+// ParseExpiry 解析过期时间，支持常见的日期文本和Excel日期序列号，
+// 只有年月时取该月最后一天，只有年份时取该年12月31日；无法识别时返回 false
+func ParseExpiry(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	value = strings.TrimSpace(expiryPrefix.ReplaceAllString(value, ""))
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	if m := yearOnly.FindStringSubmatch(value); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		if year < 1900 {
+			return time.Time{}, false
+		}
+		return time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC), true
+	}
+
+	// Excel日期序列号，1 为 1900-01-01，2958465 为 9999-12-31；
+	// 六位和八位整数按 200601 和 20060102 处理，"2025.06" 按年月处理
+	if serial, err := strconv.ParseFloat(value, 64); err == nil && !compactDate.MatchString(value) && !dottedMonth.MatchString(value) {
+		if serial < 1 || serial > 2958465 {
+			return time.Time{}, false
+		}
+		t, err := excelize.ExcelDateToTime(serial, false)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return truncateDay(t), true
+	}
+
+	for _, layout := range expiryLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return truncateDay(t), true
+		}
+	}
+	for _, layout := range expiryMonthLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.AddDate(0, 1, -1), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// truncateDay 去掉时间部分，按UTC的日期比较
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// ExpiryEntry 过期报告中的一条记录
+type ExpiryEntry struct {
+	Status   ExpiryStatus
+	Supplier string
+	Storage  string
+	Row      int
+	Name     string
+	CAS      string
+	Value    string    // 过期时间原文
+	Date     time.Time // 解析后的日期，无法识别时为零值
+	DaysLeft int       // 距过期的天数，已过期为负数
+}
+
+// CheckExpiry 检查过期时间，返回已过期、within 天内过期和无法识别的记录，
+// 按状态、供应商、储存条件和日期排序；过期时间为空的记录不检查
+func CheckExpiry(records []Record, now time.Time, within int) []ExpiryEntry {
+	today := truncateDay(now)
+
+	var entries []ExpiryEntry
+	for _, r := range records {
+		value := r.Get(ColumnExpiry)
+		if isEmptyValue(value) {
+			continue
+		}
+		entry := ExpiryEntry{
+			Supplier: r.Get(ColumnSupplier),
+			Storage:  r.Get(ColumnStorageCondition),
+			Row:      r.Row,
+			Name:     r.Get(ColumnName),
+			CAS:      r.Get(ColumnCAS),
+			Value:    value,
+		}
+
+		date, ok := ParseExpiry(value)
+		if !ok {
+			entry.Status = Unparseable
+			entries = append(entries, entry)
+			continue
+		}
+		entry.Date = date
+		entry.DaysLeft = int(date.Sub(today).Hours() / 24)
+		switch {
+		case entry.DaysLeft < 0:
+			entry.Status = Expired
+		case entry.DaysLeft <= within:
+			entry.Status = Expiring
+		default:
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	order := map[ExpiryStatus]int{Expired: 0, Expiring: 1, Unparseable: 2}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Status != b.Status {
+			return order[a.Status] < order[b.Status]
+		}
+		if a.Supplier != b.Supplier {
+			return a.Supplier < b.Supplier
+		}
+		if a.Storage != b.Storage {
+			return a.Storage < b.Storage
+		}
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		return a.Row < b.Row
+	})
+	return entries
+}
+
+// SaveExpiry 保存过期报告
+func SaveExpiry(entries []ExpiryEntry, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"status", "supplier", "storage", "row", "name", "cas", "value", "date", "days_left"})
+	for _, e := range entries {
+		date, days := "", ""
+		if e.Status != Unparseable {
+			date = e.Date.Format("2006-01-02")
+			days = strconv.Itoa(e.DaysLeft)
+		}
+		w.Write([]string{
+			i18n.T(string(e.Status)), e.Supplier, e.Storage,
+			strconv.Itoa(e.Row), e.Name, e.CAS, e.Value, date, days,
+		})
+	}
+	w.Flush()
+
+	return w.Error()
+}
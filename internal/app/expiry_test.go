@@ -0,0 +1,83 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseExpiry(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string // 空字符串表示无法识别
+	}{
+		{"2025-06-30", "2025-06-30"},
+		{"2025/6/3", "2025-06-03"},
+		{"2025.06.30", "2025-06-30"},
+		{"2025年6月30日", "2025-06-30"},
+		{"20250630", "2025-06-30"},
+		{"2025-06-30 08:30:00", "2025-06-30"},
+		{"30 Jun 2025", "2025-06-30"},
+		{"有效期至 2025-06-30", "2025-06-30"},
+		{"EXP: 2025-06", "2025-06-30"},
+		{"2024年2月", "2024-02-29"},
+		{"202502", "2025-02-28"},
+		{"2025", "2025-12-31"},
+		{"2025年", "2025-12-31"},
+		{"EXP 2026", "2026-12-31"},
+		{"0999", ""},
+		{"2025.12", "2025-12-31"},
+		{"2026.1", "2026-01-31"},
+		{"2025.06", "2025-06-30"},
+		{"2025.13", ""},
+		{"45838", "2025-06-30"},
+		{"45838.75", "2025-06-30"},
+		{"下个月", ""},
+		{"13/45/2025", ""},
+		{"-5", ""},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseExpiry(tt.value)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("ParseExpiry(%q) = %v, want 无法识别", tt.value, got)
+			}
+			continue
+		}
+		if !ok || got.Format("2006-01-02") != tt.want {
+			t.Errorf("ParseExpiry(%q) = %v %v, want %s", tt.value, got, ok, tt.want)
+		}
+	}
+}
+
+func TestCheckExpiry(t *testing.T) {
+	records := []Record{
+		{Row: 2, Values: map[string]string{ColumnExpiry: "2026-09-30", ColumnSupplier: "乙"}},
+		{Row: 3, Values: map[string]string{ColumnExpiry: "2026-10-20", ColumnSupplier: "甲"}},
+		{Row: 4, Values: map[string]string{ColumnExpiry: "2027-01-01", ColumnSupplier: "甲"}},
+		{Row: 5, Values: map[string]string{ColumnExpiry: "长期", ColumnSupplier: "甲"}},
+		{Row: 6, Values: map[string]string{ColumnExpiry: "2026-09-01", ColumnSupplier: "甲"}},
+		{Row: 7, Values: map[string]string{ColumnSupplier: "甲"}},
+	}
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.Local)
+
+	type result struct {
+		status ExpiryStatus
+		row    int
+		days   int
+	}
+	var got []result
+	for _, e := range CheckExpiry(records, now, 30) {
+		got = append(got, result{e.Status, e.Row, e.DaysLeft})
+	}
+	want := []result{
+		{Expired, 2, -18}, // 按供应商分组，"乙" 排在 "甲" 之前
+		{Expired, 6, -47},
+		{Expiring, 3, 2},
+		{Unparseable, 5, 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckExpiry = %v, want %v", got, want)
+	}
+}
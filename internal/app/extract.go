@@ -0,0 +1,164 @@
+package app
+
+import (
+	"strings"
+
+	"cas.mod/internal/i18n"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Strategy 字段在页面中的定位方式
+type Strategy int
+
+const (
+	// TableRow 在匹配的行中查找文字包含标签的行，取第 Column 个 td
+	TableRow Strategy = iota
+	// HeaderCell 在匹配的行中查找 th 包含标签的行，取该行的 td
+	HeaderCell
+)
+
+// 提取结果中的字段名
+const (
+	FieldFormula        = "化学式"
+	FieldDensity        = "密度"
+	FieldChineseName    = "中文名"
+	FieldEnglishName    = "英文名"
+	FieldStructureImage = "结构图片"
+	FieldSMILES         = "SMILES"
+	FieldInChI          = "InChI"
+	FieldInChIKey       = "InChIKey"
+	FieldPubChemCID     = "PubChem CID"
+	FieldSDS            = "msds链接"
+
+	FieldPictograms      = "危险品标志"
+	FieldSignalWord      = "警示词"
+	FieldHazardCodes     = "危险性说明"
+	FieldPrecautionCodes = "防范说明"
+)
+
+// FieldRule 标签到字段的提取规则
+type FieldRule struct {
+	Field    string   // 结果中的字段名
+	Label    string   // 页面中的标签文字，如 "分子式"
+	Selector string   // 行选择器，如 "table.ChemicalInfo tr"
+	Strategy Strategy // 定位方式
+	Column   int      // TableRow 时值所在的 td 下标
+	Attr     string   // 不为空时取值单元格中首个带该属性元素的属性值，如 img 的 "src"
+	Attrs    []string // 不为空时取值单元格中所有元素的这些属性值，以空格连接，如象形图 img 的 "src" 和 "alt"
+	Exact    bool     // 标签单元格去掉冒号后须与 Label 完全一致，用于区分 InChI 和 InChIKey、密度和蒸汽密度
+}
+
+// ChemicalRules www.ichemistry.cn 详情页的提取规则
+var ChemicalRules = []FieldRule{
+	{Field: FieldFormula, Label: "分子式", Selector: "table.ChemicalInfo tr", Strategy: TableRow, Column: 1},
+	// 页面改版后分子式不一定在 ChemicalInfo 表格中，按标签单元格定位
+	{Field: FieldFormula, Label: "分子式", Selector: "tr:has(td.ltd)", Strategy: TableRow, Column: 1},
+	{Field: FieldChineseName, Label: "中文名称", Selector: "table.ChemicalInfo tr", Strategy: TableRow, Column: 1},
+	{Field: FieldEnglishName, Label: "英文名称", Selector: "table.ChemicalInfo tr", Strategy: TableRow, Column: 1},
+	{Field: FieldStructureImage, Label: "结构式", Selector: "table.ChemicalInfo tr", Strategy: TableRow, Column: 1, Attr: "src"},
+	{Field: FieldSMILES, Label: "SMILES", Selector: "table.ChemicalInfo tr", Strategy: TableRow, Column: 1, Exact: true},
+	{Field: FieldInChI, Label: "InChI", Selector: "table.ChemicalInfo tr", Strategy: TableRow, Column: 1, Exact: true},
+	{Field: FieldInChIKey, Label: "InChIKey", Selector: "table.ChemicalInfo tr", Strategy: TableRow, Column: 1, Exact: true},
+	{Field: FieldPubChemCID, Label: "PubChem CID", Selector: "table.ChemicalInfo tr", Strategy: TableRow, Column: 1},
+	{Field: FieldSDS, Label: "MSDS", Selector: "table.ChemicalInfo tr", Strategy: TableRow, Column: 1, Attr: "href"},
+	// 安全信息表格中的 GHS 分类，不同页面的标签写法不一
+	{Field: FieldPictograms, Label: "危险品标志", Selector: "table.ChemicalInfo tr", Strategy: TableRow, Column: 1},
+	{Field: FieldPictograms, Label: "GHS", Selector: "table.ChemicalInfo tr", Strategy: TableRow, Column: 1},
+	// 象形图一般显示为 <img src=".../ghs05.gif" alt="腐蚀">，单元格中没有文字
+	{Field: FieldPictograms, Label: "危险品标志", Selector: "table.ChemicalInfo tr", Strategy: TableRow, Column: 1, Attrs: []string{"src", "alt"}},
+	{Field: FieldPictograms, Label: "GHS", Selector: "table.ChemicalInfo tr", Strategy: TableRow, Column: 1, Attrs: []string{"src", "alt"}},
+	{Field: FieldSignalWord, Label: "警示词", Selector: "table.ChemicalInfo tr", Strategy: TableRow, Column: 1},
+	{Field: FieldSignalWord, Label: "信号词", Selector: "table.ChemicalInfo tr", Strategy: TableRow, Column: 1},
+	{Field: FieldHazardCodes, Label: "危险性说明", Selector: "table.ChemicalInfo tr", Strategy: TableRow, Column: 1},
+	{Field: FieldHazardCodes, Label: "危险声明", Selector: "table.ChemicalInfo tr", Strategy: TableRow, Column: 1},
+	{Field: FieldPrecautionCodes, Label: "防范说明", Selector: "table.ChemicalInfo tr", Strategy: TableRow, Column: 1},
+	{Field: FieldPrecautionCodes, Label: "安全声明", Selector: "table.ChemicalInfo tr", Strategy: TableRow, Column: 1},
+}
+
+// DensityRules www.chemsrc.com 详情页的提取规则
+var DensityRules = []FieldRule{
+	// 标签须完全一致，避免先匹配到 "蒸汽密度" 等行
+	{Field: FieldDensity, Label: "密度", Selector: "table#baseTbl tr", Strategy: TableRow, Column: 1, Exact: true},
+	{Field: FieldDensity, Label: "密度", Selector: "#wuHuaDiv table tr", Strategy: HeaderCell, Exact: true},
+}
+
+// ExtractFields 按规则提取字段，同一字段的多条规则按顺序尝试，取第一个非空值
+func ExtractFields(doc *goquery.Document, rules []FieldRule) map[string]string {
+	fields := make(map[string]string)
+
+	for _, rule := range rules {
+		if fields[rule.Field] != "" {
+			continue
+		}
+		if value := extractField(doc, rule); value != "" {
+			fields[rule.Field] = value
+		}
+	}
+
+	return fields
+}
+
+// ExtractFieldsFromHTML 解析HTML并按规则提取字段
+func ExtractFieldsFromHTML(htmlContent string, rules []FieldRule) (map[string]string, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+	if err != nil {
+		return nil, i18n.Errorf("解析HTML失败: %v", err)
+	}
+	return ExtractFields(doc, rules), nil
+}
+
+// extractField 按单条规则提取，返回第一个匹配行的值
+func extractField(doc *goquery.Document, rule FieldRule) string {
+	var value string
+
+	doc.Find(rule.Selector).EachWithBreak(func(i int, s *goquery.Selection) bool {
+		var cell *goquery.Selection
+		switch rule.Strategy {
+		case TableRow:
+			if !matchLabel(s.Text(), s.Find("td").First().Text(), rule) {
+				return true
+			}
+			cell = s.Find("td").Eq(rule.Column)
+		case HeaderCell:
+			th := s.Find("th").Text()
+			if !matchLabel(th, th, rule) {
+				return true
+			}
+			cell = s.Find("td")
+		}
+
+		switch {
+		case len(rule.Attrs) > 0:
+			value = attrValues(cell, rule.Attrs)
+		case rule.Attr != "":
+			value, _ = cell.Find("[" + rule.Attr + "]").First().Attr(rule.Attr)
+			value = strings.TrimSpace(value)
+		default:
+			value = strings.TrimSpace(cell.Text())
+		}
+		return value == ""
+	})
+
+	return value
+}
+
+// attrValues 按元素顺序收集单元格中所有元素的指定属性值，以空格连接
+func attrValues(cell *goquery.Selection, attrs []string) string {
+	var values []string
+	cell.Find("*").Each(func(_ int, el *goquery.Selection) {
+		for _, attr := range attrs {
+			if v, ok := el.Attr(attr); ok && strings.TrimSpace(v) != "" {
+				values = append(values, strings.TrimSpace(v))
+			}
+		}
+	})
+	return strings.Join(values, " ")
+}
+
+// matchLabel 判断行是否为规则对应的标签，Exact 时比较标签单元格，否则只要行文字包含标签
+func matchLabel(rowText, labelCell string, rule FieldRule) bool {
+	if !rule.Exact {
+		return strings.Contains(rowText, rule.Label)
+	}
+	return strings.Trim(strings.TrimSpace(labelCell), "：:") == rule.Label
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"testing"
+
+	"cas.mod/internal/standin"
+)
+
+func TestExtractFields(t *testing.T) {
+	tests := []struct {
+		name  string
+		html  string
+		rules []FieldRule
+		want  map[string]string
+	}{
+		{
+			name:  "表格行",
+			html:  `<table class="ChemicalInfo"><tr><td>分子式：</td><td> NaCl </td></tr></table>`,
+			rules: ChemicalRules,
+			want:  map[string]string{FieldFormula: "NaCl"},
+		},
+		{
+			name:  "按标签单元格兜底",
+			html:  `<table class="info"><tr><td class="ltd">分子式：</td><td>KCl</td></tr></table>`,
+			rules: ChemicalRules,
+			want:  map[string]string{FieldFormula: "KCl"},
+		},
+		{
+			name:  "第一个非空值优先",
+			html:  `<table class="ChemicalInfo"><tr><td>分子式：</td><td></td></tr><tr><td>分子式：</td><td>HCl</td></tr></table>`,
+			rules: ChemicalRules,
+			want:  map[string]string{FieldFormula: "HCl"},
+		},
+		{
+			name:  "th/td",
+			html:  `<div id="wuHuaDiv"><table><tr><th>密度</th><td>1.0 g/mL</td></tr></table></div>`,
+			rules: DensityRules,
+			want:  map[string]string{FieldDensity: "1.0 g/mL"},
+		},
+		{
+			name: "象形图图片",
+			html: `<table class="ChemicalInfo"><tr><td>危险品标志：</td><td>` +
+				`<img src="/ghs/ghs02.gif" alt="易燃"><img src="/ghs/ghs07.gif" alt="GHS07 感叹号"></td></tr></table>`,
+			rules: ChemicalRules,
+			want:  map[string]string{FieldPictograms: "/ghs/ghs02.gif 易燃 /ghs/ghs07.gif GHS07 感叹号"},
+		},
+		{
+			name: "密度标签完全一致",
+			html: `<table id="baseTbl"><tr><td>蒸汽密度</td><td>1.59 (vs air)</td></tr><tr><td>密度：</td><td>0.789 g/mL</td></tr></table>` +
+				`<div id="wuHuaDiv"><table><tr><th>蒸汽密度</th><td>1.59</td></tr></table></div>`,
+			rules: DensityRules,
+			want:  map[string]string{FieldDensity: "0.789 g/mL"},
+		},
+		{
+			name:  "未找到",
+			html:  `<p>暂无数据</p>`,
+			rules: append(ChemicalRules, DensityRules...),
+			want:  map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractFieldsFromHTML(tt.html, tt.rules)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ExtractFields() = %v, want %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("%s = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestParseDensity(t *testing.T) {
+	page, err := standin.Fixture(standin.Chemsrc, "64-17-5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := &recordWriter{}
+	ParseDensity(page, 5, w, "fixture://64-17-5")
+
+	if len(w.updates) != 1 {
+		t.Fatalf("应写入 1 次，实际 %d 次", len(w.updates))
+	}
+	if u := w.updates[0]; u.Column != "相对密度(水=1)" || u.Row != 5 || u.NewValue != "0.789" {
+		t.Errorf("写入 %+v", u)
+	}
+}
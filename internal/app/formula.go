@@ -0,0 +1,106 @@
+package app
+
+import (
+	"strings"
+	"unicode"
+
+	"cas.mod/internal/i18n"
+)
+
+// atomicWeights 标准原子量(g/mol)，放射性元素取最稳定同位素的质量数
+var atomicWeights = map[string]float64{
+	"H": 1.008, "D": 2.014, "He": 4.0026, "Li": 6.94, "Be": 9.0122, "B": 10.81, "C": 12.011, "N": 14.007,
+	"O": 15.999, "F": 18.998, "Ne": 20.180, "Na": 22.990, "Mg": 24.305, "Al": 26.982, "Si": 28.085,
+	"P": 30.974, "S": 32.06, "Cl": 35.45, "Ar": 39.948, "K": 39.098, "Ca": 40.078, "Sc": 44.956,
+	"Ti": 47.867, "V": 50.942, "Cr": 51.996, "Mn": 54.938, "Fe": 55.845, "Co": 58.933, "Ni": 58.693,
+	"Cu": 63.546, "Zn": 65.38, "Ga": 69.723, "Ge": 72.630, "As": 74.922, "Se": 78.971, "Br": 79.904,
+	"Kr": 83.798, "Rb": 85.468, "Sr": 87.62, "Y": 88.906, "Zr": 91.224, "Nb": 92.906, "Mo": 95.95,
+	"Tc": 98, "Ru": 101.07, "Rh": 102.91, "Pd": 106.42, "Ag": 107.87, "Cd": 112.41, "In": 114.82,
+	"Sn": 118.71, "Sb": 121.76, "Te": 127.60, "I": 126.90, "Xe": 131.29, "Cs": 132.91, "Ba": 137.33,
+	"La": 138.91, "Ce": 140.12, "Pr": 140.91, "Nd": 144.24, "Pm": 145, "Sm": 150.36, "Eu": 151.96,
+	"Gd": 157.25, "Tb": 158.93, "Dy": 162.50, "Ho": 164.93, "Er": 167.26, "Tm": 168.93, "Yb": 173.05,
+	"Lu": 174.97, "Hf": 178.49, "Ta": 180.95, "W": 183.84, "Re": 186.21, "Os": 190.23, "Ir": 192.22,
+	"Pt": 195.08, "Au": 196.97, "Hg": 200.59, "Tl": 204.38, "Pb": 207.2, "Bi": 208.98, "Po": 209,
+	"At": 210, "Rn": 222, "Fr": 223, "Ra": 226, "Ac": 227, "Th": 232.04, "Pa": 231.04, "U": 238.03,
+	"Np": 237, "Pu": 244, "Am": 243, "Cm": 247, "Bk": 247, "Cf": 251, "Es": 252, "Fm": 257,
+	"Md": 258, "No": 259, "Lr": 262,
+}
+
+// MolecularWeight 根据化学式计算分子量，支持括号和 "FeCl3.6H2O" 形式的结晶水
+func MolecularWeight(formula string) (float64, error) {
+	formula = strings.TrimSpace(formula)
+	if formula == "" {
+		return 0, i18n.Errorf("化学式为空")
+	}
+
+	total := 0.0
+	for _, part := range strings.FieldsFunc(formula, func(r rune) bool {
+		return r == '.' || r == '·' || r == '•' || r == '*'
+	}) {
+		// 结晶水等组分前的系数
+		runes := []rune(strings.TrimSpace(part))
+		coef, i := readCount(runes, 0)
+		w, next, err := parseGroup(runes, i)
+		if err != nil {
+			return 0, i18n.Errorf("化学式 %s: %v", formula, err)
+		}
+		if next != len(runes) {
+			return 0, i18n.Errorf("化学式 %s: 多余的 %q", formula, string(runes[next]))
+		}
+		total += float64(coef) * w
+	}
+	return total, nil
+}
+
+// parseGroup 解析到右括号或结尾为止的原子团，返回分子量和结束位置
+func parseGroup(runes []rune, i int) (float64, int, error) {
+	total := 0.0
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case r == '(' || r == '[':
+			w, next, err := parseGroup(runes, i+1)
+			if err != nil {
+				return 0, 0, err
+			}
+			if next >= len(runes) || (runes[next] != ')' && runes[next] != ']') {
+				return 0, 0, i18n.Errorf("括号不匹配")
+			}
+			n, after := readCount(runes, next+1)
+			total += w * float64(n)
+			i = after
+		case r == ')' || r == ']':
+			return total, i, nil
+		case unicode.IsUpper(r):
+			symbol := string(r)
+			i++
+			if i < len(runes) && unicode.IsLower(runes[i]) {
+				symbol += string(runes[i])
+				i++
+			}
+			w, ok := atomicWeights[symbol]
+			if !ok {
+				return 0, 0, i18n.Errorf("未知元素 %s", symbol)
+			}
+			n, after := readCount(runes, i)
+			total += w * float64(n)
+			i = after
+		default:
+			return 0, 0, i18n.Errorf("无法识别的字符 %q", string(r))
+		}
+	}
+	return total, i, nil
+}
+
+// readCount 读取下标数字，没有数字时为 1
+func readCount(runes []rune, i int) (int, int) {
+	n, start := 0, i
+	for i < len(runes) && runes[i] >= '0' && runes[i] <= '9' {
+		n = n*10 + int(runes[i]-'0')
+		i++
+	}
+	if i == start {
+		return 1, i
+	}
+	return n, i
+}
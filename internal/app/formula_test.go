@@ -0,0 +1,35 @@
+package app
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMolecularWeight(t *testing.T) {
+	tests := []struct {
+		formula string
+		want    float64 // 0 表示应当报错
+	}{
+		{"H2SO4", 98.072},
+		{"C5H11NO3", 133.147},
+		{"Ba(NO3)2", 261.332},
+		{"FeCl3.6H2O", 270.285},
+		{"Cl2Sn·2H2O", 225.642},
+		{"K4[Fe(CN)6]", 368.343},
+		{"C6H5Xx", 0},
+		{"Ba(NO3", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		got, err := MolecularWeight(tt.formula)
+		if tt.want == 0 {
+			if err == nil {
+				t.Errorf("MolecularWeight(%q) = %v, want error", tt.formula, got)
+			}
+			continue
+		}
+		if err != nil || math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("MolecularWeight(%q) = %v %v, want %v", tt.formula, got, err, tt.want)
+		}
+	}
+}
@@ -2,46 +2,74 @@ package app
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 
+	"cas.mod/internal/ghs"
+	"cas.mod/internal/i18n"
 	"github.com/PuerkitoBio/goquery"
 )
 
 // ChemicalInfo 化学信息结构体
 type ChemicalInfo struct {
-	CASNumber       string // CAS号
-	ChineseName     string // 中文名
-	EnglishName     string // 英文名
-	ChemicalFormula string // 化学式
-	StructureImage  string // 结构式图片URL
+	CASNumber       string     // CAS号
+	ChineseName     string     // 中文名
+	EnglishName     string     // 英文名
+	ChemicalFormula string     // 化学式
+	Density         string     // 相对密度(水=1)
+	StructureImage  string     // 结构式图片URL
+	SMILES          string     // SMILES，没有图片时用于绘制结构图
+	InChI           string     // InChI
+	InChIKey        string     // InChIKey，用于识别以不同CAS号或名称录入的同一试剂
+	PubChemCID      string     // PubChem CID
+	Hazard          ghs.Hazard // GHS 危险性分类
+	SDSURL          string     // 安全技术说明书(SDS/MSDS)链接
+	Properties      string     // SDS 第9部分 理化特性
+	FireFighting    string     // SDS 第5部分 消防措施
+	Storage         string     // SDS 第7部分中的储存注意事项
+	Disposal        string     // SDS 第13部分 废弃处置
+	SourceURL       string     // 数据来源页面
+	Provider        string     // 数据源名称
 }
 
+// DefaultSearchBaseURL search.ichemistry.cn 的默认地址
+const DefaultSearchBaseURL = "http://search.ichemistry.cn/"
+
 // GetChemicalInfo 根据CAS号获取化学信息
 func GetChemicalInfo(casNumber string) (*ChemicalInfo, error) {
-	// 构建URL
-	url := fmt.Sprintf("http://search.ichemistry.cn/?keys=%s&onlymy=0&types=2&tz=1", casNumber)
+	return GetChemicalInfoWithBaseURL(DefaultSearchBaseURL, casNumber)
+}
+
+// GetChemicalInfoWithBaseURL 使用指定的搜索站点地址获取化学信息，可指向本地替身
+func GetChemicalInfoWithBaseURL(baseURL, casNumber string) (*ChemicalInfo, error) {
+	return getChemicalInfo(http.DefaultClient, baseURL, casNumber)
+}
+
+// getChemicalInfo 使用指定客户端查询搜索站点
+func getChemicalInfo(client *http.Client, baseURL, casNumber string) (*ChemicalInfo, error) {
+	// 构建URL，baseURL 末尾有无斜杠均可
+	url := fmt.Sprintf("%s/?keys=%s&onlymy=0&types=2&tz=1", strings.TrimSuffix(baseURL, "/"), casNumber)
 
 	// 发送HTTP GET请求
-	resp, err := http.Get(url)
+	resp, err := client.Get(url)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP请求失败: %v", err)
+		return nil, i18n.Errorf("HTTP请求失败: %v", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("HTTP状态码错误: %d %s", resp.StatusCode, resp.Status)
+		return nil, i18n.Errorf("HTTP状态码错误: %d %s", resp.StatusCode, resp.Status)
 	}
 
 	// 使用goquery解析HTML
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("解析HTML失败: %v", err)
+		return nil, i18n.Errorf("解析HTML失败: %v", err)
 	}
 
 	// 创建化学信息对象
-	info := &ChemicalInfo{CASNumber: casNumber}
+	info := &ChemicalInfo{CASNumber: casNumber, SourceURL: url, Provider: "ichemistry-search"}
 	found := false
 
 	// 查找包含化学信息的表格
@@ -64,7 +92,7 @@ func GetChemicalInfo(casNumber string) (*ChemicalInfo, error) {
 				case 3: // 结构式图片
 					if img := td.Find("img"); img.Length() > 0 {
 						if src, exists := img.Attr("src"); exists {
-							info.StructureImage = src
+							info.StructureImage = resolveURL(url, src)
 						}
 					}
 				case 4: // 化学式
@@ -75,7 +103,7 @@ func GetChemicalInfo(casNumber string) (*ChemicalInfo, error) {
 	})
 
 	if !found {
-		return nil, fmt.Errorf("未找到CAS号 %s 的信息", casNumber)
+		return nil, i18n.Errorf("未找到CAS号 %s 的信息: %w", casNumber, ErrNotFound)
 	}
 
 	return info, nil
@@ -86,18 +114,18 @@ func GetChemicalInfoWithCustomURL(url string) (*ChemicalInfo, error) {
 	// 发送HTTP GET请求
 	resp, err := http.Get(url)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP请求失败: %v", err)
+		return nil, i18n.Errorf("HTTP请求失败: %v", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("HTTP状态码错误: %d %s", resp.StatusCode, resp.Status)
+		return nil, i18n.Errorf("HTTP状态码错误: %d %s", resp.StatusCode, resp.Status)
 	}
 
 	// 使用goquery解析HTML
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("解析HTML失败: %v", err)
+		return nil, i18n.Errorf("解析HTML失败: %v", err)
 	}
 
 	info := &ChemicalInfo{}
@@ -143,7 +171,7 @@ func GetChemicalInfoWithCustomURL(url string) (*ChemicalInfo, error) {
 	})
 
 	if !found {
-		return nil, fmt.Errorf("未找到化学信息")
+		return nil, i18n.Errorf("未找到化学信息")
 	}
 
 	return info, nil
@@ -151,15 +179,13 @@ func GetChemicalInfoWithCustomURL(url string) (*ChemicalInfo, error) {
 
 // PrintChemicalInfo 打印化学信息
 func PrintChemicalInfo(info *ChemicalInfo) {
-	log.Println("=" + strings.Repeat("=", 50))
-	log.Printf("CAS号: %s\n", info.CASNumber)
-	log.Printf("中文名: %s\n", info.ChineseName)
-	log.Printf("英文名: %s\n", info.EnglishName)
-	log.Printf("化学式: %s\n", info.ChemicalFormula)
-	if info.StructureImage != "" {
-		log.Printf("结构式图片: %s\n", info.StructureImage)
-	}
-	log.Println("=" + strings.Repeat("=", 50))
+	slog.Info("化学信息",
+		"cas", info.CASNumber,
+		"chinese_name", info.ChineseName,
+		"english_name", info.EnglishName,
+		"formula", info.ChemicalFormula,
+		"structure_image", info.StructureImage,
+	)
 }
 
 // GetChemicalFormulaOnly 仅获取化学式
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cas.mod/internal/standin"
+)
+
+var update = flag.Bool("update", false, "重新生成 testdata/golden 下的期望结果")
+
+func TestGetChemicalInfo(t *testing.T) {
+	server := standin.NewServer()
+	defer server.Close()
+
+	tests := []struct {
+		cas     string
+		wantErr bool
+	}{
+		{cas: "7664-93-9"},
+		{cas: "68583-51-7"},
+		{cas: "50-00-0", wantErr: true},    // 搜索无结果
+		{cas: "99999-99-9", wantErr: true}, // 404
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cas, func(t *testing.T) {
+			info, err := GetChemicalInfoWithBaseURL(server.URL+"/", tt.cas)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("期望出错，实际返回 %+v", info)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := json.MarshalIndent(info, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			// 替身的端口每次不同，统一替换后再比较
+			got = []byte(strings.ReplaceAll(string(got), server.URL, "http://standin"))
+			assertGolden(t, "search_"+tt.cas+".json", got)
+		})
+	}
+}
+
+func TestGetChemicalInfoBaseURL(t *testing.T) {
+	server := standin.NewServer()
+	defer server.Close()
+
+	// 末尾有无斜杠都应请求同一个地址
+	for _, baseURL := range []string{server.URL, server.URL + "/"} {
+		if _, err := GetChemicalInfoWithBaseURL(baseURL, "7664-93-9"); err != nil {
+			t.Errorf("%s: %v", baseURL, err)
+		}
+	}
+}
+
+// assertGolden 与 testdata/golden 下的期望结果比较，-update 时重新生成
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", "golden", name)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取期望结果失败 (使用 -update 生成): %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("%s 不一致\n got: %s\nwant: %s", name, got, want)
+	}
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"strings"
+
+	"cas.mod/internal/ghs"
+)
+
+// 手工维护的安全信息列，hazards 命令按 GHS 分类填充
+const (
+	ColumnHazards    = "危险特性"
+	ColumnPrevention = "预防措施"
+	ColumnResponse   = "事故响应"
+	ColumnStorage    = "安全存储"
+	ColumnDisposal   = "废弃处置"
+	ColumnLabel      = "标签"
+)
+
+// HazardColumns 按写入顺序排列的安全信息列
+var HazardColumns = []string{ColumnHazards, ColumnPrevention, ColumnResponse, ColumnStorage, ColumnDisposal, ColumnLabel}
+
+// parseHazard 从提取的字段中解析 GHS 分类
+func parseHazard(fields map[string]string) ghs.Hazard {
+	h := ghs.Parse(strings.Join([]string{
+		fields[FieldPictograms],
+		fields[FieldHazardCodes],
+		fields[FieldPrecautionCodes],
+	}, "\n"))
+	h.SignalWord = ghs.ParseSignalWord(fields[FieldSignalWord])
+	return h
+}
+
+// RecordHazard 从一行记录的危险特性和标签列中解析 GHS 分类。标签列还可能是
+// "易制毒" 等管制分类，警示词只认独占一行的 "危险"、"警告"、"Danger"、"Warning"
+func RecordHazard(r Record) ghs.Hazard {
+	label := r.Get(ColumnLabel)
+	h := ghs.Parse(r.Get(ColumnHazards) + "\n" + label)
+	for _, line := range strings.Split(label, "\n") {
+		line = strings.TrimSpace(line)
+		if word := ghs.ParseSignalWord(line); word != "" && (line == ghs.Text(word, "zh") || line == ghs.Text(word, "en")) {
+			h.SignalWord = word
+		}
+	}
+	return h
+}
+
+// HazardCells 将 GHS 分类转换为各列的标准文本，键为列名，没有内容的列不出现；
+// 一般防范说明(P1xx)与预防措施(P2xx)写在同一列
+func HazardCells(h ghs.Hazard, lang string) map[string]string {
+	cells := map[string]string{
+		ColumnHazards:    ghs.Lines(h.HazardStatements, lang),
+		ColumnPrevention: ghs.Lines(h.Precautions('1', '2'), lang),
+		ColumnResponse:   ghs.Lines(h.Precautions('3'), lang),
+		ColumnStorage:    ghs.Lines(h.Precautions('4'), lang),
+		ColumnDisposal:   ghs.Lines(h.Precautions('5'), lang),
+		ColumnLabel:      h.Label(lang),
+	}
+	for column, text := range cells {
+		if text == "" {
+			delete(cells, column)
+		}
+	}
+	return cells
+}
@@ -0,0 +1,175 @@
+package app
+
+import (
+	"encoding/csv"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cas.mod/internal/i18n"
+	"github.com/xuri/excelize/v2"
+)
+
+// IdentifierColumns 标准标识符写入的列，不存在时追加到表头末尾
+var IdentifierColumns = []string{FieldSMILES, FieldInChI, FieldInChIKey, FieldPubChemCID}
+
+// inchiKeyPattern 标准InChIKey格式，如 HSNWZBCBUUSSQD-UHFFFAOYSA-N
+var inchiKeyPattern = regexp.MustCompile(`^[A-Z]{14}-[A-Z]{10}-[A-Z]$`)
+
+// NormalizeInChIKey 去掉 "InChIKey=" 前缀并校验格式，格式不正确时返回空字符串
+func NormalizeInChIKey(key string) string {
+	key = strings.ToUpper(strings.TrimSpace(key))
+	key = strings.TrimPrefix(key, "INCHIKEY=")
+	if !inchiKeyPattern.MatchString(key) {
+		return ""
+	}
+	return key
+}
+
+// Identifiers 返回化学信息中的标准标识符，键为列名
+func (info *ChemicalInfo) Identifiers() map[string]string {
+	return map[string]string{
+		FieldSMILES:     info.SMILES,
+		FieldInChI:      info.InChI,
+		FieldInChIKey:   info.InChIKey,
+		FieldPubChemCID: info.PubChemCID,
+	}
+}
+
+// ColumnWriter 支持追加新列的写回目标
+type ColumnWriter interface {
+	EnsureColumns(sheetName string, columns []string) error
+}
+
+// EnsureColumns 表头中没有的列追加到末尾
+func (ew *ExcelWriter) EnsureColumns(sheetName string, columns []string) error {
+	f, err := ew.open()
+	if err != nil {
+		return err
+	}
+
+	if sheetName == "" {
+		sheetName = "Sheet1"
+	}
+
+	index, err := ew.headerIndex(f, sheetName)
+	if err != nil {
+		return err
+	}
+
+	last := 0
+	for _, col := range index {
+		last = max(last, col)
+	}
+	for _, column := range columns {
+		if _, ok := index[column]; ok {
+			continue
+		}
+		last++
+		cellName, err := excelize.CoordinatesToCellName(last, 1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetCellValue(sheetName, cellName, column); err != nil {
+			return err
+		}
+		index[column] = last
+		ew.dirty = true
+	}
+	return nil
+}
+
+// DuplicateGroup 同一标识符出现在多行
+type DuplicateGroup struct {
+	Key  string
+	Rows []int
+	CAS  []string
+}
+
+// FindDuplicates 查找指定列中值相同的行，用于按InChIKey识别重复录入的试剂
+func (ep *ExcelProcessor) FindDuplicates(columnName string) ([]DuplicateGroup, error) {
+	f, err := excelize.OpenFile(ep.FilePath)
+	if err != nil {
+		return nil, i18n.Errorf("打开文件失败: %v", err)
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, i18n.Errorf("Excel 文件中没有工作表")
+	}
+
+	rows, err := f.GetRows(sheets[0])
+	if err != nil {
+		return nil, i18n.Errorf("读取行数据失败: %v", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	col := -1
+	for i, header := range rows[0] {
+		if strings.TrimSpace(header) == columnName {
+			col = i
+			break
+		}
+	}
+	if col == -1 {
+		return nil, i18n.Errorf("未找到列名: %s", columnName)
+	}
+	casCol := ep.findCASColumn(rows[0])
+
+	groups := make(map[string]*DuplicateGroup)
+	for rowIndex := 1; rowIndex < len(rows); rowIndex++ {
+		row := rows[rowIndex]
+		if len(row) <= col || isEmptyValue(row[col]) {
+			continue
+		}
+		key := strings.TrimSpace(row[col])
+		g, ok := groups[key]
+		if !ok {
+			g = &DuplicateGroup{Key: key}
+			groups[key] = g
+		}
+		g.Rows = append(g.Rows, rowIndex+1)
+		if casCol >= 0 && len(row) > casCol {
+			g.CAS = append(g.CAS, strings.TrimSpace(row[casCol]))
+		} else {
+			g.CAS = append(g.CAS, "")
+		}
+	}
+
+	var duplicates []DuplicateGroup
+	for _, g := range groups {
+		if len(g.Rows) > 1 {
+			duplicates = append(duplicates, *g)
+		}
+	}
+	sort.Slice(duplicates, func(i, j int) bool {
+		return duplicates[i].Rows[0] < duplicates[j].Rows[0]
+	})
+
+	return duplicates, nil
+}
+
+// SaveDuplicates 将重复记录保存为CSV文件，每行一条记录
+func SaveDuplicates(groups []DuplicateGroup, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"key", "row", "cas"})
+	for _, g := range groups {
+		for i, row := range g.Rows {
+			w.Write([]string{g.Key, strconv.Itoa(row), g.CAS[i]})
+		}
+	}
+	w.Flush()
+
+	return w.Error()
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"cas.mod/internal/i18n"
+)
+
+// imageExtensions 可以嵌入Excel的图片格式
+var imageExtensions = map[string]bool{
+	".png": true, ".gif": true, ".jpg": true, ".jpeg": true, ".bmp": true, ".svg": true,
+}
+
+// ImageStore 按内容哈希存放结构式图片，相同图片只保存一份
+type ImageStore struct {
+	Dir    string
+	Client *http.Client
+}
+
+// Download 下载图片并保存，返回本地文件路径
+func (is *ImageStore) Download(imageURL string) (string, error) {
+	resp, err := clientOrDefault(is.Client).Get(imageURL)
+	if err != nil {
+		return "", i18n.Errorf("下载图片失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", &StatusError{URL: imageURL, StatusCode: resp.StatusCode}
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", i18n.Errorf("读取图片失败: %v", err)
+	}
+
+	ext := imageExtension(imageURL, resp.Header.Get("Content-Type"))
+	if ext == "" {
+		return "", i18n.Errorf("不支持的图片格式: %s", imageURL)
+	}
+
+	return is.Save(data, ext)
+}
+
+// Save 保存图片内容，路径为 <Dir>/<哈希前两位>/<哈希><扩展名>
+func (is *ImageStore) Save(data []byte, ext string) (string, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	filePath := filepath.Join(is.Dir, hash[:2], hash+ext)
+
+	if _, err := os.Stat(filePath); err == nil {
+		return filePath, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return "", i18n.Errorf("创建图片目录失败: %v", err)
+	}
+	if err := os.WriteFile(filePath, data, 0o644); err != nil {
+		return "", i18n.Errorf("保存图片失败: %v", err)
+	}
+
+	return filePath, nil
+}
+
+// imageExtension 根据URL或Content-Type确定图片扩展名，不支持时返回空字符串
+func imageExtension(imageURL, contentType string) string {
+	if u, err := url.Parse(imageURL); err == nil {
+		if ext := strings.ToLower(path.Ext(u.Path)); imageExtensions[ext] {
+			return ext
+		}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	exts, _ := mime.ExtensionsByType(mediaType)
+	for _, ext := range exts {
+		if imageExtensions[ext] {
+			return ext
+		}
+	}
+
+	return ""
+}
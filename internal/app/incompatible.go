@@ -0,0 +1,222 @@
+package app
+
+import (
+	"encoding/csv"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"cas.mod/internal/ghs"
+)
+
+// 试剂工作表中的基本列
+const (
+	ColumnName    = "常用名称"
+	ColumnCAS     = "CAS号"
+	ColumnDensity = "相对密度(水=1)"
+)
+
+// 相容性检查使用的列
+const (
+	ColumnStorageCondition = "储存条件"
+	ColumnCategory         = "类别"
+	ColumnIncompatible     = "禁配物"
+)
+
+// minNameLength 按名称匹配禁配物时名称的最少字数，避免 "水" 等单字误报
+const minNameLength = 2
+
+// classKeywords 类别、禁配物等自由文本中的关键词，按出现顺序匹配
+var classKeywords = []struct {
+	keyword string
+	class   ghs.Class
+}{
+	{"氧化剂", ghs.Oxidizer}, {"氧化性", ghs.Oxidizer}, {"过氧化物", ghs.Oxidizer}, {"oxidiz", ghs.Oxidizer},
+	{"还原剂", ghs.Reducer}, {"还原性", ghs.Reducer}, {"reducing", ghs.Reducer},
+	{"易燃", ghs.Flammable}, {"可燃", ghs.Flammable}, {"flammable", ghs.Flammable}, {"combustible", ghs.Flammable},
+	{"自燃", ghs.Pyrophoric}, {"pyrophoric", ghs.Pyrophoric},
+	{"遇湿", ghs.WaterReactive}, {"遇水", ghs.WaterReactive}, {"活性金属", ghs.WaterReactive}, {"water-reactive", ghs.WaterReactive},
+	{"爆炸", ghs.Explosive}, {"explosive", ghs.Explosive},
+	{"酸", ghs.Acid}, {"acid", ghs.Acid},
+	{"碱", ghs.Base}, {"alkali", ghs.Base},
+}
+
+var (
+	// negatedKeyword 否定的关键词，如 "不易燃"、"非氧化性"、"non-flammable"，匹配前去掉
+	negatedKeyword = regexp.MustCompile(`(?:不|非|难|无|non-?|not\s+)(?:` + keywordPattern() + `)`)
+	// saltOrEster 盐、酯和氨基酸中的 "酸"，如 碳酸盐、硫酸钠、碳酸氢钠、乙酸乙酯、氨基酸
+	saltOrEster = regexp.MustCompile(`酸(?:盐|氢?(?:钠|钾|钙|铵|镁|锂|钡|铝|铁|铜|锌|银|铅|锰|镍|钴)|[甲乙丙丁戊]?酯)|氨基酸|amino acids?`)
+)
+
+// keywordPattern 所有类别关键词的正则选择分支
+func keywordPattern() string {
+	quoted := make([]string, len(classKeywords))
+	for i, kw := range classKeywords {
+		quoted[i] = regexp.QuoteMeta(kw.keyword)
+	}
+	return strings.Join(quoted, "|")
+}
+
+// textClasses 从自由文本中识别危险类别，"碱金属" 按活性金属处理而不是碱；
+// 否定的说法（不易燃、非氧化性）以及盐和酯名称中的 "酸" 不计入
+func textClasses(text string, set map[ghs.Class]bool) {
+	text = strings.ToLower(strings.ReplaceAll(text, "碱金属", "活性金属"))
+	text = negatedKeyword.ReplaceAllString(text, " ")
+	text = saltOrEster.ReplaceAllString(text, " ")
+	for _, kw := range classKeywords {
+		if strings.Contains(text, kw.keyword) {
+			set[kw.class] = true
+		}
+	}
+}
+
+// Reagent 相容性检查中的一种试剂
+type Reagent struct {
+	Row       int
+	Name      string
+	CAS       string
+	Group     string      // 储存位置，未填写时为空
+	Classes   []ghs.Class // 自身的危险类别
+	Avoid     []ghs.Class // 禁配物中列出的类别
+	AvoidText string      // 禁配物原文，用于按名称匹配同处储存的试剂
+}
+
+// NewReagent 根据一行记录推断危险类别：危险特性和标签中的 GHS 代码、类别列的关键词、
+// 名称以 "酸" 结尾（氨基酸除外）或含 "氢氧化"、"氨水" 的常见酸碱，以及标签为爆炸品的记录
+func NewReagent(r Record, groupBy string) Reagent {
+	own := make(map[ghs.Class]bool)
+	for _, c := range RecordHazard(r).Classes() {
+		own[c] = true
+	}
+	textClasses(r.Get(ColumnCategory), own)
+
+	name := r.Get(ColumnName)
+	// 甘氨酸、谷氨酸等氨基酸不按酸储存
+	if strings.HasSuffix(name, "酸") && !strings.HasSuffix(name, "氨酸") {
+		own[ghs.Acid] = true
+	}
+	if strings.Contains(name, "氢氧化") || strings.Contains(name, "氨水") {
+		own[ghs.Base] = true
+	}
+	if strings.Contains(r.Get(ColumnLabel), "爆炸品") {
+		own[ghs.Explosive] = true
+	}
+
+	avoid := make(map[ghs.Class]bool)
+	textClasses(r.Get(ColumnIncompatible), avoid)
+
+	return Reagent{
+		Row:       r.Row,
+		Name:      name,
+		CAS:       r.Get(ColumnCAS),
+		Group:     r.Get(groupBy),
+		Classes:   ghs.SortClasses(own),
+		Avoid:     ghs.SortClasses(avoid),
+		AvoidText: r.Get(ColumnIncompatible),
+	}
+}
+
+// IncompatiblePair 同处储存但不相容的两种试剂
+type IncompatiblePair struct {
+	Group   string
+	A, B    Reagent
+	Reasons []string
+}
+
+// FindIncompatible 按储存位置分组，列出组内不相容的试剂对。判断依据为内置的类别矩阵、
+// 一方禁配物中的类别与另一方的类别重合，以及一方禁配物中写明另一方的名称。
+// 未填写储存位置的试剂不知道和谁放在一起，不参与配对
+func FindIncompatible(reagents []Reagent) []IncompatiblePair {
+	groups := make(map[string][]Reagent)
+	var names []string
+	for _, r := range reagents {
+		if r.Group == "" {
+			continue
+		}
+		if _, ok := groups[r.Group]; !ok {
+			names = append(names, r.Group)
+		}
+		groups[r.Group] = append(groups[r.Group], r)
+	}
+	sort.Strings(names)
+
+	var pairs []IncompatiblePair
+	for _, name := range names {
+		members := groups[name]
+		for i := 0; i < len(members); i++ {
+			for j := i + 1; j < len(members); j++ {
+				reasons := incompatibleReasons(members[i], members[j])
+				if len(reasons) > 0 {
+					pairs = append(pairs, IncompatiblePair{Group: name, A: members[i], B: members[j], Reasons: reasons})
+				}
+			}
+		}
+	}
+	return pairs
+}
+
+// incompatibleReasons 返回两种试剂不相容的原因，相容时返回空
+func incompatibleReasons(a, b Reagent) []string {
+	// 双方都没有禁配物且至少一方没有危险类别时必然相容，大多数试剂属于这种情况
+	if a.AvoidText == "" && b.AvoidText == "" && (len(a.Classes) == 0 || len(b.Classes) == 0) {
+		return nil
+	}
+
+	var reasons []string
+	add := func(reason string) {
+		for _, r := range reasons {
+			if r == reason {
+				return
+			}
+		}
+		reasons = append(reasons, reason)
+	}
+
+	for _, ca := range a.Classes {
+		for _, cb := range b.Classes {
+			if ghs.Incompatible(ca, cb) {
+				add(string(ca) + "与" + string(cb))
+			}
+		}
+	}
+	for _, side := range [][2]Reagent{{a, b}, {b, a}} {
+		x, y := side[0], side[1]
+		for _, avoid := range x.Avoid {
+			for _, c := range y.Classes {
+				if avoid == c {
+					add(x.Name + "禁配" + string(c))
+				}
+			}
+		}
+		if utf8.RuneCountInString(y.Name) >= minNameLength && strings.Contains(x.AvoidText, y.Name) {
+			add(x.Name + "禁配" + y.Name)
+		}
+	}
+	return reasons
+}
+
+// SaveIncompatible 保存不相容试剂对报告
+func SaveIncompatible(pairs []IncompatiblePair, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"group", "row_a", "name_a", "cas_a", "row_b", "name_b", "cas_b", "reason"})
+	for _, p := range pairs {
+		w.Write([]string{
+			p.Group,
+			strconv.Itoa(p.A.Row), p.A.Name, p.A.CAS,
+			strconv.Itoa(p.B.Row), p.B.Name, p.B.CAS,
+			strings.Join(p.Reasons, "；"),
+		})
+	}
+	w.Flush()
+
+	return w.Error()
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	"cas.mod/internal/ghs"
+)
+
+func TestFindIncompatible(t *testing.T) {
+	record := func(row int, values map[string]string) Record {
+		return Record{Row: row, Values: values}
+	}
+	records := []Record{
+		record(2, map[string]string{ColumnName: "硝酸钡", ColumnHazards: "H272 可加剧燃烧；氧化剂", ColumnStorageCondition: "A柜"}),
+		record(3, map[string]string{ColumnName: "乙醇", ColumnHazards: "H225 高度易燃液体和蒸气", ColumnStorageCondition: "A柜"}),
+		record(4, map[string]string{ColumnName: "硫酸", ColumnIncompatible: "碱类、碱金属、乙醇", ColumnStorageCondition: "A柜"}),
+		record(5, map[string]string{ColumnName: "氢氧化钠", ColumnStorageCondition: "B柜"}),
+		record(6, map[string]string{ColumnName: "金属钠", ColumnCategory: "遇湿易燃物品", ColumnStorageCondition: "A柜"}),
+		// 未填写储存位置的试剂不参与配对
+		record(7, map[string]string{ColumnName: "高锰酸钾", ColumnHazards: "H272 可加剧燃烧；氧化剂"}),
+		record(8, map[string]string{ColumnName: "丙酮", ColumnHazards: "H225 高度易燃液体和蒸气"}),
+	}
+
+	var reagents []Reagent
+	for _, r := range records {
+		reagents = append(reagents, NewReagent(r, ColumnStorageCondition))
+	}
+	if got := reagents[3].Classes; !reflect.DeepEqual(got, []ghs.Class{ghs.Base}) {
+		t.Errorf("氢氧化钠 类别 = %v", got)
+	}
+	if got := reagents[2].Avoid; !reflect.DeepEqual(got, []ghs.Class{ghs.Base, ghs.WaterReactive}) {
+		t.Errorf("硫酸 禁配类别 = %v", got)
+	}
+
+	type pair struct {
+		a, b    int
+		reasons []string
+	}
+	var got []pair
+	for _, p := range FindIncompatible(reagents) {
+		got = append(got, pair{p.A.Row, p.B.Row, p.Reasons})
+	}
+	want := []pair{
+		{2, 3, []string{"氧化剂与易燃物"}},
+		{2, 6, []string{"氧化剂与易燃物"}},
+		{3, 4, []string{"硫酸禁配乙醇"}},
+		{4, 6, []string{"酸与遇水反应物", "硫酸禁配遇水反应物"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindIncompatible = %v, want %v", got, want)
+	}
+}
+
+func TestNewReagentFalsePositives(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]string
+		own    []ghs.Class
+		avoid  []ghs.Class
+	}{
+		{"否定的易燃", map[string]string{ColumnName: "氯化钠", ColumnCategory: "不易燃、不可燃"}, []ghs.Class{}, []ghs.Class{}},
+		{"否定的氧化性", map[string]string{ColumnName: "硫酸钡", ColumnCategory: "非氧化性 non-flammable"}, []ghs.Class{}, []ghs.Class{}},
+		{"盐和酯", map[string]string{ColumnName: "碳酸钙", ColumnIncompatible: "碳酸盐、硫酸钠、碳酸氢钠、乙酸乙酯"}, []ghs.Class{}, []ghs.Class{}},
+		{"氨基酸", map[string]string{ColumnName: "甘氨酸", ColumnIncompatible: "强氧化剂"}, []ghs.Class{}, []ghs.Class{ghs.Oxidizer}},
+		{"酸仍按酸", map[string]string{ColumnName: "乙酸", ColumnIncompatible: "碱类、氧化性酸"}, []ghs.Class{ghs.Acid}, []ghs.Class{ghs.Oxidizer, ghs.Base, ghs.Acid}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReagent(Record{Row: 2, Values: tt.values}, ColumnStorageCondition)
+			if !reflect.DeepEqual(r.Classes, tt.own) {
+				t.Errorf("类别 = %v, want %v", r.Classes, tt.own)
+			}
+			if !reflect.DeepEqual(r.Avoid, tt.avoid) {
+				t.Errorf("禁配类别 = %v, want %v", r.Avoid, tt.avoid)
+			}
+		})
+	}
+}
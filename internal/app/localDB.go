@@ -0,0 +1,272 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"cas.mod/internal/ghs"
+	"cas.mod/internal/i18n"
+	"cas.mod/internal/sds"
+)
+
+// LocalDB 本地试剂库，按CAS号保存已整理好的化学信息。
+// 文件为 JSON Lines，每行一条记录，按CAS号排序，便于版本管理和比对
+type LocalDB struct {
+	Path string
+
+	records map[string]*ChemicalInfo
+}
+
+// OpenLocalDB 读取本地试剂库，文件不存在时返回空库
+func OpenLocalDB(path string) (*LocalDB, error) {
+	db := &LocalDB{Path: path, records: make(map[string]*ChemicalInfo)}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return db, nil
+	}
+	if err != nil {
+		return nil, i18n.Errorf("打开本地试剂库失败: %v", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var info ChemicalInfo
+		if err := json.Unmarshal(scanner.Bytes(), &info); err != nil {
+			return nil, i18n.Errorf("%s 第 %d 行: %v", path, line, err)
+		}
+		if info.CASNumber != "" {
+			db.records[info.CASNumber] = &info
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, i18n.Errorf("读取本地试剂库失败: %v", err)
+	}
+	return db, nil
+}
+
+// Len 库中的记录数
+func (db *LocalDB) Len() int {
+	return len(db.records)
+}
+
+// Get 返回CAS号对应记录的副本，db 为 nil 时视为空库
+func (db *LocalDB) Get(casNumber string) (*ChemicalInfo, bool) {
+	if db == nil {
+		return nil, false
+	}
+	info, ok := db.records[strings.TrimSpace(casNumber)]
+	if !ok {
+		return nil, false
+	}
+	c := *info
+	return &c, true
+}
+
+// CASNumbers 按顺序返回库中所有CAS号
+func (db *LocalDB) CASNumbers() []string {
+	numbers := make([]string, 0, len(db.records))
+	for cas := range db.records {
+		numbers = append(numbers, cas)
+	}
+	sort.Strings(numbers)
+	return numbers
+}
+
+// Put 合并一条记录：新记录中的非空字段覆盖库中已有的值，空字段保留原值
+func (db *LocalDB) Put(info *ChemicalInfo) {
+	if info.CASNumber == "" {
+		return
+	}
+	existing, ok := db.records[info.CASNumber]
+	if !ok {
+		c := *info
+		db.records[info.CASNumber] = &c
+		return
+	}
+	mergeInfo(existing, info)
+}
+
+// mergeInfo 将 src 中的非空字段写入 dst，空字段保留 dst 的原值
+func mergeInfo(dst, src *ChemicalInfo) {
+	for _, f := range []struct{ dst, src *string }{
+		{&dst.ChineseName, &src.ChineseName},
+		{&dst.EnglishName, &src.EnglishName},
+		{&dst.ChemicalFormula, &src.ChemicalFormula},
+		{&dst.Density, &src.Density},
+		{&dst.StructureImage, &src.StructureImage},
+		{&dst.SMILES, &src.SMILES},
+		{&dst.InChI, &src.InChI},
+		{&dst.InChIKey, &src.InChIKey},
+		{&dst.PubChemCID, &src.PubChemCID},
+		{&dst.SDSURL, &src.SDSURL},
+		{&dst.Properties, &src.Properties},
+		{&dst.FireFighting, &src.FireFighting},
+		{&dst.Storage, &src.Storage},
+		{&dst.Disposal, &src.Disposal},
+		{&dst.SourceURL, &src.SourceURL},
+		{&dst.Provider, &src.Provider},
+	} {
+		if *f.src != "" {
+			*f.dst = *f.src
+		}
+	}
+	if !src.Hazard.Empty() {
+		dst.Hazard = src.Hazard
+	}
+}
+
+// Save 按CAS号排序写回文件，先写临时文件再替换，避免中断时损坏原库
+func (db *LocalDB) Save() error {
+	if dir := filepath.Dir(db.Path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return i18n.Errorf("创建目录失败: %v", err)
+		}
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(db.Path), filepath.Base(db.Path)+".*.tmp")
+	if err != nil {
+		return i18n.Errorf("创建临时文件失败: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, cas := range db.CASNumbers() {
+		if err := enc.Encode(db.records[cas]); err != nil {
+			tmp.Close()
+			return i18n.Errorf("写入记录 %s 失败: %v", cas, err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return i18n.Errorf("写入本地试剂库失败: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return i18n.Errorf("写入本地试剂库失败: %v", err)
+	}
+	return os.Rename(tmp.Name(), db.Path)
+}
+
+// RecordInfo 将工作表中已整理的一行转换为化学信息，CAS号缺失或校验位错误时返回 nil
+func RecordInfo(r Record, source string) *ChemicalInfo {
+	cas := r.Get(ColumnCAS)
+	if !sds.ValidCAS(cas) {
+		return nil
+	}
+	h := RecordHazard(r)
+	if len(h.PrecautionaryStatements) == 0 {
+		h.PrecautionaryStatements = ghs.Parse(strings.Join([]string{
+			r.Get(ColumnPrevention), r.Get(ColumnResponse), r.Get(ColumnStorage), r.Get(ColumnDisposal),
+		}, "\n")).PrecautionaryStatements
+	}
+	return &ChemicalInfo{
+		CASNumber:       cas,
+		ChineseName:     r.Get(ColumnName),
+		EnglishName:     r.Get(FieldEnglishName),
+		ChemicalFormula: r.Get(FieldFormula),
+		Density:         r.Get(ColumnDensity),
+		SMILES:          r.Get(FieldSMILES),
+		InChI:           r.Get(FieldInChI),
+		InChIKey:        NormalizeInChIKey(r.Get(FieldInChIKey)),
+		PubChemCID:      r.Get(FieldPubChemCID),
+		Hazard:          h,
+		SDSURL:          r.Get(FieldSDS),
+		Properties:      r.Get(ColumnProperties),
+		FireFighting:    r.Get(ColumnFireFighting),
+		Storage:         r.Get(ColumnStorage),
+		Disposal:        r.Get(ColumnDisposal),
+		SourceURL:       source,
+	}
+}
+
+// Seed 用工作表中的记录更新本地试剂库，返回导入的记录数
+func (db *LocalDB) Seed(records []Record, source string) int {
+	n := 0
+	for _, r := range records {
+		if info := RecordInfo(r, source); info != nil {
+			db.Put(info)
+			n++
+		}
+	}
+	return n
+}
+
+// LocalProvider 以本地试剂库作为数据源。Need 不为空时，记录缺少所需字段
+// 视为未找到，由 ProviderChain 继续查询在线数据源
+type LocalProvider struct {
+	DB   *LocalDB
+	Need func(*ChemicalInfo) bool
+}
+
+// Name 实现 Provider
+func (lp *LocalProvider) Name() string {
+	return "local-db"
+}
+
+// Lookup 实现 Provider
+func (lp *LocalProvider) Lookup(casNumber string) (*ChemicalInfo, error) {
+	info, ok := lp.DB.Get(casNumber)
+	if !ok || (lp.Need != nil && !lp.Need(info)) {
+		return nil, ErrNotFound
+	}
+	info.Provider = lp.Name()
+	return info, nil
+}
+
+// MergedProvider 先查本地试剂库，记录满足 Need 时直接返回；否则再查询 Online，
+// 在线结果只补充本地记录中为空的字段，本地整理过的值优先。
+// 在线查询失败时仍返回本地记录
+type MergedProvider struct {
+	DB     *LocalDB
+	Online Provider
+	Need   func(*ChemicalInfo) bool
+}
+
+// Name 实现 Provider
+func (mp *MergedProvider) Name() string {
+	return "local-db+" + mp.Online.Name()
+}
+
+// Lookup 实现 Provider
+func (mp *MergedProvider) Lookup(casNumber string) (*ChemicalInfo, error) {
+	start := time.Now()
+	local, ok := mp.DB.Get(casNumber)
+	var localErr error
+	if !ok {
+		localErr = ErrNotFound
+	}
+	ObserveLookup("local-db", localErr, time.Since(start))
+	if ok {
+		local.Provider = "local-db"
+		if mp.Need == nil || mp.Need(local) {
+			return local, nil
+		}
+	}
+
+	info, err := mp.Online.Lookup(casNumber)
+	switch {
+	case err != nil && ok:
+		return local, nil
+	case err != nil:
+		return nil, err
+	case !ok:
+		return info, nil
+	}
+
+	merged := *info
+	mergeInfo(&merged, local)
+	merged.SourceURL, merged.Provider = info.SourceURL, local.Provider+","+info.Provider
+	return &merged, nil
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLocalDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reagents.jsonl")
+	db, err := OpenLocalDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := []Record{
+		{Row: 2, Values: map[string]string{ColumnName: "硫酸", ColumnCAS: "7664-93-9", FieldFormula: "H2SO4", ColumnDensity: "1.84", ColumnHazards: "H290\nH314", ColumnDisposal: "P501 按规定处置"}},
+		{Row: 3, Values: map[string]string{ColumnName: "乙醇", ColumnCAS: "64-17-5"}},
+		{Row: 4, Values: map[string]string{ColumnName: "校验位错误", ColumnCAS: "64-17-6", FieldFormula: "C2H6O"}},
+		{Row: 5, Values: map[string]string{ColumnName: "没有CAS号"}},
+	}
+	if n := db.Seed(records, "curated.xlsx"); n != 2 {
+		t.Errorf("Seed = %d, want 2", n)
+	}
+
+	// 空字段不覆盖已有值
+	db.Put(&ChemicalInfo{CASNumber: "64-17-5", ChemicalFormula: "C2H6O"})
+	db.Put(&ChemicalInfo{CASNumber: "64-17-5", EnglishName: "Ethanol"})
+	if err := db.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = OpenLocalDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := db.CASNumbers(); !reflect.DeepEqual(got, []string{"64-17-5", "7664-93-9"}) {
+		t.Errorf("CASNumbers = %v", got)
+	}
+	ethanol, _ := db.Get("64-17-5")
+	if ethanol.ChineseName != "乙醇" || ethanol.ChemicalFormula != "C2H6O" || ethanol.EnglishName != "Ethanol" {
+		t.Errorf("乙醇 = %+v", ethanol)
+	}
+	acid, _ := db.Get("7664-93-9")
+	if acid.Density != "1.84" || acid.SourceURL != "curated.xlsx" {
+		t.Errorf("硫酸 = %+v", acid)
+	}
+	if got := acid.Hazard.HazardStatements; !reflect.DeepEqual(got, []string{"H290", "H314"}) {
+		t.Errorf("危险性说明 = %v", got)
+	}
+	if got := acid.Hazard.PrecautionaryStatements; !reflect.DeepEqual(got, []string{"P501"}) {
+		t.Errorf("防范说明 = %v", got)
+	}
+
+	provider := &LocalProvider{DB: db, Need: func(info *ChemicalInfo) bool { return info.SMILES != "" }}
+	if _, err := provider.Lookup("7664-93-9"); err != ErrNotFound {
+		t.Errorf("缺少所需字段时应返回 ErrNotFound，得到 %v", err)
+	}
+	provider.Need = nil
+	info, err := provider.Lookup("7664-93-9")
+	if err != nil || info.Provider != "local-db" {
+		t.Errorf("Lookup = %+v, %v", info, err)
+	}
+}
+
+// stubProvider 返回固定结果的数据源，记录查询次数
+type stubProvider struct {
+	info  *ChemicalInfo
+	err   error
+	calls int
+}
+
+func (sp *stubProvider) Name() string { return "stub" }
+
+func (sp *stubProvider) Lookup(string) (*ChemicalInfo, error) {
+	sp.calls++
+	if sp.err != nil {
+		return nil, sp.err
+	}
+	c := *sp.info
+	return &c, nil
+}
+
+func TestMergedProvider(t *testing.T) {
+	db, err := OpenLocalDB(filepath.Join(t.TempDir(), "reagents.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Put(&ChemicalInfo{CASNumber: "7664-93-9", ChineseName: "硫酸", ChemicalFormula: "H2SO4"})
+
+	online := &stubProvider{info: &ChemicalInfo{
+		CASNumber: "7664-93-9", ChemicalFormula: "H₂SO₄", InChIKey: "QAOWNCQODCNURD-UHFFFAOYSA-N",
+		SourceURL: "http://example.com/7664-93-9", Provider: "stub",
+	}}
+	mp := &MergedProvider{DB: db, Online: online, Need: NeedColumns([]string{FieldFormula, FieldInChIKey})}
+
+	// 本地记录缺少 InChIKey，在线结果只补充空字段
+	info, err := mp.Lookup("7664-93-9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ChemicalFormula != "H2SO4" || info.InChIKey != "QAOWNCQODCNURD-UHFFFAOYSA-N" || info.ChineseName != "硫酸" {
+		t.Errorf("合并结果 = %+v", info)
+	}
+	if info.Provider != "local-db,stub" || info.SourceURL != "http://example.com/7664-93-9" {
+		t.Errorf("来源 = %q %q", info.Provider, info.SourceURL)
+	}
+
+	// 本地记录已满足所需列时不在线查询
+	mp.Need = NeedColumns([]string{FieldFormula})
+	if info, err := mp.Lookup("7664-93-9"); err != nil || info.Provider != "local-db" || online.calls != 1 {
+		t.Errorf("Lookup = %+v, %v，在线查询 %d 次", info, err, online.calls)
+	}
+
+	// 在线查询失败时返回本地记录，本地也没有时返回错误
+	mp.Need = NeedColumns([]string{FieldInChIKey})
+	online.err = ErrNotFound
+	if info, err := mp.Lookup("7664-93-9"); err != nil || info.ChemicalFormula != "H2SO4" {
+		t.Errorf("Lookup = %+v, %v", info, err)
+	}
+	if _, err := mp.Lookup("64-17-5"); err != ErrNotFound {
+		t.Errorf("Lookup = %v, want ErrNotFound", err)
+	}
+}
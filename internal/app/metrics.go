@@ -0,0 +1,93 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cas.mod/internal/metrics"
+)
+
+// 查询和写入的监控指标，serve 命令在 /metrics 输出，批量命令结束时保存
+var (
+	providerRequests = metrics.NewCounter("cas_provider_requests_total",
+		"各数据源的查询次数，result 为 found、not_found 或 error", "provider", "result")
+	providerDuration = metrics.NewHistogram("cas_provider_request_duration_seconds",
+		"各数据源的查询耗时", metrics.DefBuckets, "provider")
+	httpResponses = metrics.NewCounter("cas_http_responses_total",
+		"访问各站点的HTTP状态码，请求失败时 code 为 error", "host", "code")
+	parseResults = metrics.NewCounter("cas_parse_total",
+		"从页面中解析字段的结果，result 为 ok 或 failed", "field", "result")
+	cacheLookups = metrics.NewCounter("cas_cache_lookups_total",
+		"查询缓存的次数，result 为 hit 或 miss", "result")
+	writeDuration = metrics.NewHistogram("cas_write_duration_seconds",
+		"写入一个单元格的耗时，result 为 ok、conflict 或 error", metrics.DefBuckets, "writer", "result")
+)
+
+// ObserveLookup 记录一次数据源查询的结果和耗时
+func ObserveLookup(provider string, err error, elapsed time.Duration) {
+	result := "found"
+	switch {
+	case errors.Is(err, ErrNotFound):
+		result = "not_found"
+	case err != nil:
+		result = "error"
+	}
+	providerRequests.Inc(provider, result)
+	providerDuration.Observe(elapsed.Seconds(), provider)
+}
+
+// observeParse 记录从页面中解析字段是否成功
+func observeParse(field string, ok bool) {
+	result := "ok"
+	if !ok {
+		result = "failed"
+	}
+	parseResults.Inc(field, result)
+}
+
+// observeWrite 记录一次单元格写入的结果和耗时，在写入函数中 defer 调用，errp 指向其返回值
+func observeWrite(writer string, errp *error, start time.Time) {
+	result := "ok"
+	var conflict *ConflictError
+	switch err := *errp; {
+	case errors.As(err, &conflict):
+		result = "conflict"
+	case err != nil:
+		result = "error"
+	}
+	writeDuration.Observe(time.Since(start).Seconds(), writer, result)
+}
+
+// InstrumentClient 返回按站点统计HTTP状态码的客户端，client 为 nil 时包装 http.DefaultClient
+func InstrumentClient(client *http.Client) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if _, ok := client.Transport.(*countingTransport); ok {
+		return client
+	}
+	c := *client
+	c.Transport = &countingTransport{next: client.Transport}
+	return &c
+}
+
+// countingTransport 统计每个响应的状态码
+type countingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		httpResponses.Inc(req.URL.Host, "error")
+		return nil, err
+	}
+	httpResponses.Inc(req.URL.Host, strconv.Itoa(resp.StatusCode))
+	return resp, nil
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	db, err := OpenLocalDB(filepath.Join(t.TempDir(), "reagents.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Put(&ChemicalInfo{CASNumber: "64-17-5", ChemicalFormula: "C2H6O"})
+
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer site.Close()
+	host := mustHost(t, site.URL)
+
+	found := providerRequests.Value("local-db", "found")
+	notFound := providerRequests.Value("local-db", "not_found")
+	failed := providerRequests.Value("ichemistry", "error")
+	status503 := httpResponses.Value(host, "503")
+	hits, misses := cacheLookups.Value("hit"), cacheLookups.Value("miss")
+
+	chain := ProviderChain{&LocalProvider{DB: db}, &IchemistryProvider{BaseURL: site.URL}}
+	cached := &CachedProvider{Provider: chain}
+	cached.Lookup("64-17-5")
+	cached.Lookup("64-17-5")
+	cached.Lookup("7664-93-9")
+
+	if got := providerRequests.Value("local-db", "found") - found; got != 1 {
+		t.Errorf("local-db found = %v, want 1", got)
+	}
+	if got := providerRequests.Value("local-db", "not_found") - notFound; got != 1 {
+		t.Errorf("local-db not_found = %v, want 1", got)
+	}
+	if got := providerRequests.Value("ichemistry", "error") - failed; got != 1 {
+		t.Errorf("ichemistry error = %v, want 1", got)
+	}
+	if got := httpResponses.Value(host, "503") - status503; got != 1 {
+		t.Errorf("HTTP 503 = %v, want 1", got)
+	}
+	if got := cacheLookups.Value("hit") - hits; got != 1 {
+		t.Errorf("cache hit = %v, want 1", got)
+	}
+	if got := cacheLookups.Value("miss") - misses; got != 2 {
+		t.Errorf("cache miss = %v, want 2", got)
+	}
+}
+
+func mustHost(t *testing.T, rawURL string) string {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Host
+}
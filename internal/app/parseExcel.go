@@ -2,11 +2,12 @@ package app
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"strings"
 	"time"
 
+	"cas.mod/internal/i18n"
 	"github.com/xuri/excelize/v2"
 )
 
@@ -18,17 +19,17 @@ type ExcelProcessor struct {
 // ProcessEmptyChemicalFormulas 处理化学式为空的记录
 func (ep *ExcelProcessor) ProcessEmptyChemicalFormulas() ([]int, int, error) {
 	startTime := time.Now()
-	log.Printf("开始处理文件: %s\n", ep.FilePath)
+	slog.Info("开始处理文件", "file", ep.FilePath)
 
 	f, err := excelize.OpenFile(ep.FilePath)
 	if err != nil {
-		return nil, 0, fmt.Errorf("打开文件失败: %v", err)
+		return nil, 0, i18n.Errorf("打开文件失败: %v", err)
 	}
 	defer f.Close()
 
 	sheets := f.GetSheetList()
 	if len(sheets) == 0 {
-		return nil, 0, fmt.Errorf("Excel 文件中没有工作表")
+		return nil, 0, i18n.Errorf("Excel 文件中没有工作表")
 	}
 
 	var allEmptyRows []int
@@ -36,11 +37,11 @@ func (ep *ExcelProcessor) ProcessEmptyChemicalFormulas() ([]int, int, error) {
 
 	// 处理所有工作表
 	for _, sheetName := range sheets {
-		log.Printf("\n处理工作表: %s\n", sheetName)
+		slog.Info("处理工作表", "sheet", sheetName)
 
 		emptyRows, emptyCount, err := ep.processSheet(f, sheetName)
 		if err != nil {
-			log.Printf("警告: 处理工作表 %s 时出错: %v", sheetName, err)
+			slog.Warn("处理工作表出错", "sheet", sheetName, "err", err)
 			continue
 		}
 
@@ -49,7 +50,7 @@ func (ep *ExcelProcessor) ProcessEmptyChemicalFormulas() ([]int, int, error) {
 	}
 
 	elapsed := time.Since(startTime)
-	log.Printf("\n处理完成! 耗时: %v\n", elapsed)
+	slog.Info("处理完成", "elapsed", elapsed)
 
 	return allEmptyRows, totalEmptyCount, nil
 }
@@ -59,29 +60,29 @@ func (ep *ExcelProcessor) processSheet(f *excelize.File, sheetName string) ([]in
 	// 使用 GetRows 获取所有数据
 	rows, err := f.GetRows(sheetName)
 	if err != nil {
-		return nil, 0, fmt.Errorf("读取行数据失败: %v", err)
+		return nil, 0, i18n.Errorf("读取行数据失败: %v", err)
 	}
 
 	if len(rows) == 0 {
-		log.Printf("  工作表 %s 为空\n", sheetName)
+		slog.Info("工作表为空", "sheet", sheetName)
 		return nil, 0, nil
 	}
 
-	log.Printf("  总行数: %d (包含表头)\n", len(rows))
+	slog.Debug("工作表总行数(包含表头)", "sheet", sheetName, "rows", len(rows))
 
 	// 查找化学式列的索引
 	formulaCol := ep.findFormulaColumn(rows[0])
 	if formulaCol == -1 {
-		return nil, 0, fmt.Errorf("未找到化学式列")
+		return nil, 0, i18n.Errorf("未找到化学式列")
 	}
 
-	log.Printf("化学式列: 第 %d 列\n", formulaCol+1)
+	slog.Debug("化学式列", "sheet", sheetName, "index", formulaCol+1)
 
 	// 处理数据行
 	var emptyRows []int
 	emptyCount := 0
 
-	log.Printf("  开始扫描数据行...\n")
+	slog.Debug("开始扫描数据行", "sheet", sheetName)
 
 	for rowIndex := 1; rowIndex < len(rows); rowIndex++ {
 		row := rows[rowIndex]
@@ -103,12 +104,12 @@ func (ep *ExcelProcessor) processSheet(f *excelize.File, sheetName string) ([]in
 
 			// 实时显示进度
 			if emptyCount%500 == 0 {
-				log.Printf("已找到 %d 个空化学式记录...\n", emptyCount)
+				slog.Info("扫描进度", "sheet", sheetName, "empty", emptyCount)
 			}
 		}
 	}
 
-	log.Printf("工作表 %s: 找到 %d 个化学式为空的记录\n", sheetName, emptyCount)
+	slog.Info("找到化学式为空的记录", "sheet", sheetName, "count", emptyCount)
 	return emptyRows, emptyCount, nil
 }
 
@@ -126,7 +127,7 @@ func (ep *ExcelProcessor) findFormulaColumn(headers []string) int {
 
 		for _, pattern := range formulaPatterns {
 			if strings.Contains(normalizedHeader, pattern) {
-				log.Printf("识别化学式列: 第 %d 列 (%s)\n", i+1, header)
+				slog.Debug("识别化学式列", "index", i+1, "header", header)
 				return i
 			}
 		}
@@ -136,7 +137,12 @@ func (ep *ExcelProcessor) findFormulaColumn(headers []string) int {
 
 // isChemicalFormulaEmpty 判断化学式是否为空
 func (ep *ExcelProcessor) isChemicalFormulaEmpty(formula string) bool {
-	trimmed := strings.TrimSpace(formula)
+	return isEmptyValue(formula)
+}
+
+// isEmptyValue 判断单元格的值是否视为空，化学式扫描和写回检查共用同一规则
+func isEmptyValue(value string) bool {
+	trimmed := strings.TrimSpace(value)
 
 	// 空字符串
 	if trimmed == "" {
@@ -171,10 +177,7 @@ func (ep *ExcelProcessor) isChemicalFormulaEmpty(formula string) bool {
 
 // PrintResults 打印结果
 func (ep *ExcelProcessor) PrintResults(emptyRows []int, totalCount int) {
-	log.Printf("\n========== 统计结果 ==========\n")
-	log.Printf("化学式为空的记录总数: %d\n", totalCount)
-	log.Printf("空化学式所在行号列表:\n")
-	log.Printf("==================================\n\n")
+	slog.Info("统计结果", "file", ep.FilePath, "empty", totalCount)
 
 	// 分组显示行号（每行显示10个）
 	for i := 0; i < len(emptyRows); i += 10 {
@@ -187,18 +190,18 @@ func (ep *ExcelProcessor) PrintResults(emptyRows []int, totalCount int) {
 		for j := i; j < end; j++ {
 			line += fmt.Sprintf("%-6d", emptyRows[j])
 		}
-		log.Println(line)
+		slog.Info("空化学式所在行号", "rows", strings.TrimSpace(line))
 	}
 
-	log.Printf("\n==================================\n")
-	log.Printf("总计: %d 个化学式为空的记录\n", totalCount)
-
 	// 显示统计信息
 	if totalCount > 0 {
 		firstRow := emptyRows[0]
 		lastRow := emptyRows[len(emptyRows)-1]
-		log.Printf("行号范围: %d - %d\n", firstRow, lastRow)
-		log.Printf("空记录占比: %.2f%%\n", float64(totalCount)/float64(lastRow)*100)
+		slog.Info("空记录分布",
+			"first", firstRow,
+			"last", lastRow,
+			"ratio", fmt.Sprintf("%.2f%%", float64(totalCount)/float64(lastRow)*100),
+		)
 	}
 }
 
@@ -211,13 +214,13 @@ func (ep *ExcelProcessor) SaveToFile(emptyRows []int, totalCount int, filename s
 	defer file.Close()
 
 	// 写入文件头
-	file.WriteString("化学式为空的记录统计\n")
+	file.WriteString(i18n.T("化学式为空的记录统计") + "\n")
 	file.WriteString("====================\n\n")
-	file.WriteString(fmt.Sprintf("统计时间: %s\n", time.Now().Format("2006-01-02 15:04:05")))
-	file.WriteString(fmt.Sprintf("源文件: %s\n", ep.FilePath))
-	file.WriteString(fmt.Sprintf("总记录数: %d\n\n", totalCount))
+	file.WriteString(i18n.Sprintf("统计时间: %s\n", time.Now().Format("2006-01-02 15:04:05")))
+	file.WriteString(i18n.Sprintf("源文件: %s\n", ep.FilePath))
+	file.WriteString(i18n.Sprintf("总记录数: %d\n\n", totalCount))
 
-	file.WriteString("空化学式所在行号:\n")
+	file.WriteString(i18n.T("空化学式所在行号:") + "\n")
 	file.WriteString("----------------\n")
 
 	// 分组写入行号（每行10个）
@@ -235,23 +238,25 @@ func (ep *ExcelProcessor) SaveToFile(emptyRows []int, totalCount int, filename s
 	}
 
 	file.WriteString("\n====================\n")
-	file.WriteString("统计完成!\n")
+	file.WriteString(i18n.T("统计完成!") + "\n")
 
 	return nil
 }
 
 // GenerateReport 生成详细报告
 func (ep *ExcelProcessor) GenerateReport(emptyRows []int, totalCount int) {
-	log.Printf("\n ========== 详细统计报告 ==========\n")
-	log.Printf("文件名称: %s\n", ep.FilePath)
-	log.Printf("处理时间: %s\n", time.Now().Format("2006-01-02 15:04:05"))
-	log.Printf("空记录总数: %d\n", totalCount)
-
-	// 空记录分布
-	log.Printf("空记录分布:\n")
-	log.Printf("最小行号: %d\n", emptyRows[0])
-	log.Printf("最大行号: %d\n", emptyRows[len(emptyRows)-1])
-	log.Printf("行号跨度: %d 行\n", emptyRows[len(emptyRows)-1]-emptyRows[0]+1)
+	if totalCount == 0 {
+		slog.Info("详细统计报告", "file", ep.FilePath, "empty", 0)
+		return
+	}
+	slog.Info("详细统计报告",
+		"file", ep.FilePath,
+		"time", time.Now().Format("2006-01-02 15:04:05"),
+		"empty", totalCount,
+		"min_row", emptyRows[0],
+		"max_row", emptyRows[len(emptyRows)-1],
+		"span", emptyRows[len(emptyRows)-1]-emptyRows[0]+1,
+	)
 }
 
 // ParseExcel 解析excel
@@ -263,28 +268,30 @@ func ParseExcel(filePath string) map[int]string {
 
 	// 检查文件是否存在
 	if _, err := os.Stat(processor.FilePath); os.IsNotExist(err) {
-		log.Fatalf("文件不存在: %s", processor.FilePath)
+		slog.Error("文件不存在", "file", processor.FilePath)
+		os.Exit(1)
 	}
 
-	log.Printf("开始解析Excel文件: %s\n", processor.FilePath)
+	slog.Info("开始解析Excel文件", "file", processor.FilePath)
 
 	// 处理数据
 	emptyRows, totalCount, err := processor.ProcessEmptyChemicalFormulas()
 	if err != nil {
-		log.Fatalf("处理失败: %v", err)
+		slog.Error("处理失败", "file", processor.FilePath, "err", err)
+		os.Exit(1)
 	}
 
 	// 保存结果到文件
 	outputFile := "./docs/empty_formula_report.txt"
 	cas, err := processor.GetCASByRowNumbers(emptyRows)
 	if err != nil {
-		log.Println("error!")
+		slog.Error("读取CAS号失败", "file", processor.FilePath, "err", err)
 	}
 	err = processor.SaveToFile(emptyRows, totalCount, outputFile)
 	if err != nil {
-		log.Printf("保存文件失败: %v", err)
+		slog.Error("保存文件失败", "file", outputFile, "err", err)
 	} else {
-		log.Printf("结果已保存到: %s\n", outputFile)
+		slog.Info("结果已保存", "file", outputFile)
 	}
 	return cas
 }
@@ -293,13 +300,13 @@ func ParseExcel(filePath string) map[int]string {
 func (ep *ExcelProcessor) GetCASByRowNumbers(rowNumbers []int) (map[int]string, error) {
 	f, err := excelize.OpenFile(ep.FilePath)
 	if err != nil {
-		return nil, fmt.Errorf("打开文件失败: %v", err)
+		return nil, i18n.Errorf("打开文件失败: %v", err)
 	}
 	defer f.Close()
 
 	sheets := f.GetSheetList()
 	if len(sheets) == 0 {
-		return nil, fmt.Errorf("Excel 文件中没有工作表")
+		return nil, i18n.Errorf("Excel 文件中没有工作表")
 	}
 
 	// 默认处理第一个工作表
@@ -307,21 +314,93 @@ func (ep *ExcelProcessor) GetCASByRowNumbers(rowNumbers []int) (map[int]string,
 	return ep.getCASFromSheetBatch(f, sheetName, rowNumbers)
 }
 
+// GetCASByEmptyColumn 获取指定列为空（没有值也没有图片）的行号及其CAS号，列不存在时返回所有有CAS号的行
+func (ep *ExcelProcessor) GetCASByEmptyColumn(columnName string) (map[int]string, error) {
+	f, err := excelize.OpenFile(ep.FilePath)
+	if err != nil {
+		return nil, i18n.Errorf("打开文件失败: %v", err)
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, i18n.Errorf("Excel 文件中没有工作表")
+	}
+
+	// 默认处理第一个工作表
+	sheetName := sheets[0]
+	rows, err := f.GetRows(sheetName)
+	if err != nil {
+		return nil, i18n.Errorf("读取行数据失败: %v", err)
+	}
+	if len(rows) == 0 {
+		return nil, i18n.Errorf("工作表 %s 为空", sheetName)
+	}
+
+	col := -1
+	for i, header := range rows[0] {
+		if strings.TrimSpace(header) == columnName {
+			col = i
+			break
+		}
+	}
+	// 列不存在时视为整列为空，由写入方追加该列
+	if col == -1 {
+		slog.Info("未找到列，所有记录视为空", "column", columnName)
+	}
+
+	casCol := ep.findCASColumn(rows[0])
+	if casCol == -1 {
+		return nil, i18n.Errorf("未找到CAS号列")
+	}
+
+	// 嵌入了图片的单元格视为非空
+	pictureCells, err := f.GetPictureCells(sheetName)
+	if err != nil {
+		return nil, i18n.Errorf("读取图片失败: %v", err)
+	}
+	hasPicture := make(map[string]bool, len(pictureCells))
+	for _, cell := range pictureCells {
+		hasPicture[cell] = true
+	}
+
+	result := make(map[int]string)
+	for rowIndex := 1; rowIndex < len(rows); rowIndex++ {
+		row := rows[rowIndex]
+		if len(row) <= casCol || strings.TrimSpace(row[casCol]) == "" {
+			continue
+		}
+		if col >= 0 {
+			if len(row) > col && !isEmptyValue(row[col]) {
+				continue
+			}
+			cellName, _ := excelize.CoordinatesToCellName(col+1, rowIndex+1)
+			if hasPicture[cellName] {
+				continue
+			}
+		}
+		result[rowIndex+1] = strings.TrimSpace(row[casCol])
+	}
+
+	slog.Info("列为空的记录", "column", columnName, "count", len(result))
+	return result, nil
+}
+
 func (ep *ExcelProcessor) getCASFromSheetBatch(f *excelize.File, sheetName string, rowNumbers []int) (map[int]string, error) {
 	// 获取所有行数据
 	rows, err := f.GetRows(sheetName)
 	if err != nil {
-		return nil, fmt.Errorf("读取行数据失败: %v", err)
+		return nil, i18n.Errorf("读取行数据失败: %v", err)
 	}
 
 	if len(rows) == 0 {
-		return nil, fmt.Errorf("工作表 %s 为空", sheetName)
+		return nil, i18n.Errorf("工作表 %s 为空", sheetName)
 	}
 
 	// 查找CAS号列的索引
 	casCol := ep.findCASColumn(rows[0])
 	if casCol == -1 {
-		return nil, fmt.Errorf("未找到CAS号列")
+		return nil, i18n.Errorf("未找到CAS号列")
 	}
 
 	result := make(map[int]string)
@@ -361,7 +440,7 @@ func (ep *ExcelProcessor) findCASColumn(headers []string) int {
 
 		for _, pattern := range casPatterns {
 			if strings.Contains(normalizedHeader, pattern) {
-				log.Printf("识别CAS号列: 第 %d 列 (%s)\n", i+1, header)
+				slog.Debug("识别CAS号列", "index", i+1, "header", header)
 				return i
 			}
 		}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"time"
+
+	"cas.mod/internal/i18n"
+	"github.com/xuri/excelize/v2"
+)
+
+// ProvenanceSheet 记录自动填充来源的隐藏工作表名称
+const ProvenanceSheet = "_provenance"
+
+// ProvenanceOptions 自动填充值的来源标记方式，可同时启用多种
+type ProvenanceOptions struct {
+	Comment bool   // 为单元格添加批注
+	Fill    string // 单元格填充色，如 "#FFF2CC"，为空时不填充
+	Sheet   bool   // 在隐藏工作表中记录每次写入
+}
+
+// Enabled 是否启用了任意一种来源标记
+func (po ProvenanceOptions) Enabled() bool {
+	return po.Comment || po.Fill != "" || po.Sheet
+}
+
+// markProvenance 按配置为刚写入的单元格标记来源，nextRow 见 appendProvenanceRow
+func markProvenance(f *excelize.File, opts ProvenanceOptions, u CellUpdate, cellName string, now time.Time, nextRow *int) error {
+	timestamp := now.Format("2006-01-02 15:04:05")
+
+	if opts.Comment {
+		text := i18n.Sprintf("自动填充\n来源: %s\n数据源: %s\n时间: %s\n版本: %s", u.Source, u.Provider, timestamp, Version)
+		// 覆盖已有值时保留原值，如替换失效的SDS链接
+		if !isEmptyValue(u.OldValue) {
+			text += i18n.Sprintf("\n原值: %s", u.OldValue)
+		}
+		// 同一单元格只保留最新一次的批注
+		if err := f.DeleteComment(u.Sheet, cellName); err != nil {
+			return err
+		}
+		if err := f.AddComment(u.Sheet, excelize.Comment{Cell: cellName, Author: "CAS2formula", Text: text}); err != nil {
+			return i18n.Errorf("添加批注失败: %v", err)
+		}
+	}
+
+	if opts.Fill != "" {
+		if err := fillCell(f, u.Sheet, cellName, opts.Fill); err != nil {
+			return i18n.Errorf("设置填充色失败: %v", err)
+		}
+	}
+
+	if opts.Sheet {
+		if err := appendProvenanceRow(f, u, cellName, timestamp, nextRow); err != nil {
+			return i18n.Errorf("记录来源失败: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// fillCell 在保留原有样式的基础上设置单元格填充色
+func fillCell(f *excelize.File, sheetName, cellName, color string) error {
+	styleID, err := f.GetCellStyle(sheetName, cellName)
+	if err != nil {
+		return err
+	}
+
+	style, err := f.GetStyle(styleID)
+	if err != nil {
+		return err
+	}
+	style.Fill = excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{color}}
+
+	newID, err := f.NewStyle(style)
+	if err != nil {
+		return err
+	}
+	return f.SetCellStyle(sheetName, cellName, cellName, newID)
+}
+
+// appendProvenanceRow 在隐藏的来源工作表末尾追加一行记录
+// nextRow 保存下一个空行的行号，为 0 时读取一次工作表确定，之后每追加一行加一
+func appendProvenanceRow(f *excelize.File, u CellUpdate, cellName, timestamp string, nextRow *int) error {
+	if *nextRow == 0 {
+		row, err := provenanceEnd(f)
+		if err != nil {
+			return err
+		}
+		*nextRow = row
+	}
+
+	start, err := excelize.CoordinatesToCellName(1, *nextRow)
+	if err != nil {
+		return err
+	}
+
+	record := []interface{}{u.Sheet, cellName, u.Column, u.NewValue, u.Source, u.Provider, timestamp, Version}
+	if err := f.SetSheetRow(ProvenanceSheet, start, &record); err != nil {
+		return err
+	}
+	*nextRow++
+	return nil
+}
+
+// provenanceEnd 返回来源工作表第一个空行的行号，工作表不存在时创建
+func provenanceEnd(f *excelize.File) (int, error) {
+	index, err := f.GetSheetIndex(ProvenanceSheet)
+	if err != nil {
+		return 0, err
+	}
+
+	if index == -1 {
+		if _, err := f.NewSheet(ProvenanceSheet); err != nil {
+			return 0, err
+		}
+		header := []interface{}{"工作表", "单元格", "列名", "值", "来源URL", "数据源", "时间", "工具版本"}
+		if err := f.SetSheetRow(ProvenanceSheet, "A1", &header); err != nil {
+			return 0, err
+		}
+		if err := f.SetSheetVisible(ProvenanceSheet, false); err != nil {
+			return 0, err
+		}
+		return 2, nil
+	}
+
+	rows, err := f.GetRows(ProvenanceSheet)
+	if err != nil {
+		return 0, err
+	}
+	return len(rows) + 1, nil
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cas.mod/internal/i18n"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/language"
+)
+
+func TestProvenanceComment(t *testing.T) {
+	defer i18n.Set(i18n.Current())
+	i18n.Set(language.English)
+
+	f := excelize.NewFile()
+	defer f.Close()
+	u := CellUpdate{Sheet: "Sheet1", Column: FieldSDS, Row: 2, OldValue: "http://old", NewValue: "http://new", Source: "http://src", Provider: "ichemistry"}
+	now := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	if err := markProvenance(f, ProvenanceOptions{Comment: true}, u, "B2", now, new(int)); err != nil {
+		t.Fatal(err)
+	}
+
+	comments, err := f.GetComments("Sheet1")
+	if err != nil || len(comments) != 1 {
+		t.Fatalf("批注 = %v, %v", comments, err)
+	}
+	text := comments[0].Text
+	for _, p := range comments[0].Paragraph {
+		text += p.Text
+	}
+	for _, want := range []string{"Auto-filled\nSource: http://src\nProvider: ichemistry\nTime: 2026-10-18 09:30:00", "\nPrevious value: http://old"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("批注缺少 %q: %q", want, text)
+		}
+	}
+}
+
+func TestProvenanceSheet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reagents.xlsx")
+	f := excelize.NewFile()
+	f.SetSheetRow("Sheet1", "A1", &[]string{ColumnCAS, FieldFormula, FieldSMILES})
+	f.SetSheetRow("Sheet1", "A2", &[]string{"7664-93-9"})
+	f.SetSheetRow("Sheet1", "A3", &[]string{"64-17-5"})
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	// 第二次打开时接着已有的记录追加
+	for _, updates := range [][]CellUpdate{
+		{{Column: FieldFormula, Row: 2, NewValue: "H2SO4"}, {Column: FieldFormula, Row: 3, NewValue: "C2H6O"}},
+		{{Column: FieldSMILES, Row: 3, NewValue: "CCO"}},
+	} {
+		ew := &ExcelWriter{FilePath: path, Provenance: ProvenanceOptions{Sheet: true}}
+		for _, u := range updates {
+			if err := ew.WriteCell(u); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := ew.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := f.GetRows(ProvenanceSheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cells []string
+	for _, row := range rows[1:] {
+		cells = append(cells, row[1]+"="+row[3])
+	}
+	if got := strings.Join(cells, " "); len(rows) != 4 || got != "B2=H2SO4 B3=C2H6O C3=CCO" {
+		t.Errorf("来源记录 = %q", got)
+	}
+}
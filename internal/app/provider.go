@@ -0,0 +1,200 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"cas.mod/internal/i18n"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// Provider 化学信息数据源
+type Provider interface {
+	Name() string
+	Lookup(casNumber string) (*ChemicalInfo, error)
+}
+
+// ErrNotFound 数据源中没有该CAS号
+var ErrNotFound error = notFoundError{}
+
+// notFoundError 在输出时才翻译，使包初始化后切换的语言也能生效
+type notFoundError struct{}
+
+func (notFoundError) Error() string { return i18n.T("未找到") }
+
+// ProviderChain 按顺序查询多个数据源，返回第一个成功的结果
+type ProviderChain []Provider
+
+// Name 实现 Provider
+func (pc ProviderChain) Name() string {
+	names := make([]string, len(pc))
+	for i, p := range pc {
+		names[i] = p.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// Lookup 依次查询，全部失败时返回最后一个错误
+func (pc ProviderChain) Lookup(casNumber string) (*ChemicalInfo, error) {
+	err := i18n.Errorf("CAS %s: 没有可用的数据源", casNumber)
+	for _, p := range pc {
+		start := time.Now()
+		info, lookupErr := p.Lookup(casNumber)
+		ObserveLookup(p.Name(), lookupErr, time.Since(start))
+		if lookupErr == nil {
+			if info.Provider == "" {
+				info.Provider = p.Name()
+			}
+			return info, nil
+		}
+		err = fmt.Errorf("%s: %w", p.Name(), lookupErr)
+	}
+	return nil, err
+}
+
+// SearchProvider search.ichemistry.cn 搜索结果页
+type SearchProvider struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// Name 实现 Provider
+func (sp *SearchProvider) Name() string {
+	return "ichemistry-search"
+}
+
+// Lookup 实现 Provider
+func (sp *SearchProvider) Lookup(casNumber string) (*ChemicalInfo, error) {
+	baseURL := sp.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultSearchBaseURL
+	}
+	return getChemicalInfo(clientOrDefault(sp.Client), baseURL, casNumber)
+}
+
+// DefaultChemicalBaseURL www.ichemistry.cn 的默认地址
+const DefaultChemicalBaseURL = "http://www.ichemistry.cn"
+
+// IchemistryProvider www.ichemistry.cn 详情页
+type IchemistryProvider struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// Name 实现 Provider
+func (ip *IchemistryProvider) Name() string {
+	return "ichemistry"
+}
+
+// Lookup 实现 Provider
+func (ip *IchemistryProvider) Lookup(casNumber string) (*ChemicalInfo, error) {
+	baseURL := ip.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultChemicalBaseURL
+	}
+	pageURL := fmt.Sprintf("%s/chemistry/%s.htm", strings.TrimSuffix(baseURL, "/"), casNumber)
+
+	body, err := fetchPage(clientOrDefault(ip.Client), pageURL, true)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := ExtractFieldsFromHTML(body, ChemicalRules)
+	if err != nil {
+		return nil, err
+	}
+	if fields[FieldFormula] == "" && fields[FieldChineseName] == "" {
+		return nil, fmt.Errorf("CAS %s: %w", casNumber, ErrNotFound)
+	}
+
+	info := &ChemicalInfo{
+		CASNumber:       casNumber,
+		ChineseName:     fields[FieldChineseName],
+		EnglishName:     fields[FieldEnglishName],
+		ChemicalFormula: fields[FieldFormula],
+		SMILES:          fields[FieldSMILES],
+		InChI:           fields[FieldInChI],
+		InChIKey:        NormalizeInChIKey(fields[FieldInChIKey]),
+		PubChemCID:      fields[FieldPubChemCID],
+		Hazard:          parseHazard(fields),
+		SourceURL:       pageURL,
+		Provider:        ip.Name(),
+	}
+	if src := fields[FieldStructureImage]; src != "" {
+		info.StructureImage = resolveURL(pageURL, src)
+	}
+	if href := fields[FieldSDS]; href != "" {
+		info.SDSURL = resolveURL(pageURL, href)
+	}
+
+	return info, nil
+}
+
+// StatusError 数据源返回了非200状态码
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return i18n.Sprintf("HTTP状态码错误: %d %s", e.StatusCode, e.URL)
+}
+
+// fetchPage 获取页面内容，gbk 为 true 时按GBK解码
+func fetchPage(client *http.Client, pageURL string, gbk bool) (string, error) {
+	req, err := http.NewRequest("GET", pageURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	// 设置请求头
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "zh-CN,zh;q=0.8,en-US;q=0.5,en;q=0.3")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", i18n.Errorf("HTTP请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%w: %w", ErrNotFound, &StatusError{URL: pageURL, StatusCode: resp.StatusCode})
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", &StatusError{URL: pageURL, StatusCode: resp.StatusCode}
+	}
+
+	var reader io.Reader = resp.Body
+	if gbk {
+		reader = simplifiedchinese.GBK.NewDecoder().Reader(resp.Body)
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return "", i18n.Errorf("读取响应失败: %v", err)
+	}
+	return string(body), nil
+}
+
+// resolveURL 将页面中的相对地址转换为绝对地址
+func resolveURL(pageURL, ref string) string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ref
+	}
+	u, err := base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return u.String()
+}
+
+// clientOrDefault 未指定客户端时使用 http.DefaultClient，返回的客户端统计HTTP状态码
+func clientOrDefault(client *http.Client) *http.Client {
+	return InstrumentClient(client)
+}
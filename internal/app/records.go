@@ -0,0 +1,88 @@
+package app
+
+import (
+	"strings"
+
+	"cas.mod/internal/i18n"
+	"github.com/xuri/excelize/v2"
+)
+
+// Record 工作表中的一行，按表头取值
+type Record struct {
+	Row    int               // Excel 行号，从 2 开始
+	Values map[string]string // 表头到单元格的值，已去掉首尾空白
+}
+
+// Get 返回指定列的值，列不存在时返回空字符串
+func (r Record) Get(column string) string {
+	return r.Values[column]
+}
+
+// Records 读取第一个工作表的所有数据行，跳过整行为空的行
+func (ep *ExcelProcessor) Records() ([]Record, error) {
+	_, _, records, err := ep.records(excelize.Options{})
+	return records, err
+}
+
+// RawRecords 与 Records 相同，但不应用数字格式，日期单元格返回Excel序列号
+func (ep *ExcelProcessor) RawRecords() ([]Record, error) {
+	_, _, records, err := ep.records(excelize.Options{RawCellValue: true})
+	return records, err
+}
+
+// HeadersAndRecords 与 Records 相同，并返回读取的工作表名和按列顺序的表头，
+// 写回时须使用同一个工作表
+func (ep *ExcelProcessor) HeadersAndRecords() (string, []string, []Record, error) {
+	return ep.records(excelize.Options{})
+}
+
+func (ep *ExcelProcessor) records(opts excelize.Options) (string, []string, []Record, error) {
+	f, err := excelize.OpenFile(ep.FilePath)
+	if err != nil {
+		return "", nil, nil, i18n.Errorf("打开文件失败: %v", err)
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return "", nil, nil, i18n.Errorf("Excel 文件中没有工作表")
+	}
+
+	rows, err := f.GetRows(sheets[0], opts)
+	if err != nil {
+		return "", nil, nil, i18n.Errorf("读取行数据失败: %v", err)
+	}
+	if len(rows) == 0 {
+		return sheets[0], nil, nil, nil
+	}
+
+	headers := make([]string, len(rows[0]))
+	for i, header := range rows[0] {
+		headers[i] = strings.TrimSpace(header)
+	}
+
+	var records []Record
+	for rowIndex := 1; rowIndex < len(rows); rowIndex++ {
+		values := make(map[string]string, len(headers))
+		for i, value := range rows[rowIndex] {
+			if i >= len(headers) || headers[i] == "" {
+				continue
+			}
+			if value = strings.TrimSpace(value); value != "" {
+				values[headers[i]] = value
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		records = append(records, Record{Row: rowIndex + 1, Values: values})
+	}
+
+	var columns []string
+	for _, header := range headers {
+		if header != "" {
+			columns = append(columns, header)
+		}
+	}
+	return sheets[0], columns, records, nil
+}
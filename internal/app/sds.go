@@ -0,0 +1,65 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+
+	"cas.mod/internal/i18n"
+)
+
+// 链接检查结果列，每次运行都会刷新
+const (
+	ColumnSDSStatus  = "msds状态"
+	ColumnSDSChecked = "msds检查日期"
+)
+
+// SDSColumns sds 命令写入的列，不存在时追加到表头末尾
+var SDSColumns = []string{FieldSDS, ColumnSDSStatus, ColumnSDSChecked}
+
+// LinkStatus 一次链接检查的结果
+type LinkStatus struct {
+	URL        string
+	StatusCode int   // HTTP状态码，请求失败时为 0
+	Err        error // 请求失败的原因
+}
+
+// OK 链接可以访问
+func (ls LinkStatus) OK() bool {
+	return ls.Err == nil && ls.StatusCode >= 200 && ls.StatusCode < 300
+}
+
+// String 写入状态列的文字，如 "200 OK"
+func (ls LinkStatus) String() string {
+	if ls.Err != nil {
+		return i18n.Sprintf("请求失败: %v", ls.Err)
+	}
+	return fmt.Sprintf("%d %s", ls.StatusCode, http.StatusText(ls.StatusCode))
+}
+
+// CheckLink 用HEAD请求检查链接是否可以访问，服务器不支持HEAD时改用GET且不读取正文
+func CheckLink(client *http.Client, link string) LinkStatus {
+	client = clientOrDefault(client)
+	status := LinkStatus{URL: link}
+
+	for _, method := range []string{http.MethodHead, http.MethodGet} {
+		req, err := http.NewRequest(method, link, nil)
+		if err != nil {
+			status.Err = err
+			return status
+		}
+		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			status.Err = err
+			return status
+		}
+		resp.Body.Close()
+
+		status.StatusCode = resp.StatusCode
+		if resp.StatusCode != http.StatusMethodNotAllowed && resp.StatusCode != http.StatusNotImplemented {
+			break
+		}
+	}
+	return status
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"cas.mod/internal/i18n"
+	"cas.mod/internal/sds"
+)
+
+// SDS 各部分对应的列
+const (
+	ColumnProperties   = "物化性质"
+	ColumnFireFighting = "灭火方式"
+)
+
+// SDSFileColumns ingest-sds 命令写入的列
+var SDSFileColumns = []string{ColumnProperties, ColumnFireFighting, ColumnStorage, ColumnDisposal}
+
+// maxCellLength Excel 单元格最多容纳的字符数
+const maxCellLength = 32767
+
+// sdsExtensions 可以读取的SDS文件
+var sdsExtensions = map[string]bool{".pdf": true, ".txt": true, ".text": true}
+
+// SDSProvider 本地目录中的SDS文件，离线数据源。
+// 文件名中含有CAS号时直接使用，否则从第1、3部分中查找
+type SDSProvider struct {
+	Dir string
+
+	once  sync.Once
+	index map[string]string // CAS号到文件路径
+}
+
+// Name 实现 Provider
+func (sp *SDSProvider) Name() string {
+	return "sds-file"
+}
+
+// Lookup 实现 Provider，SourceURL 为SDS文件路径
+func (sp *SDSProvider) Lookup(casNumber string) (*ChemicalInfo, error) {
+	sp.once.Do(sp.buildIndex)
+	path, ok := sp.index[strings.TrimSpace(casNumber)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	text, err := sds.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc := sds.Parse(text)
+	info := &ChemicalInfo{
+		CASNumber:    casNumber,
+		Properties:   doc.Section(sds.SectionProperties),
+		FireFighting: doc.Section(sds.SectionFireFighting),
+		Storage:      doc.Storage(),
+		Disposal:     doc.Section(sds.SectionDisposal),
+		SourceURL:    path,
+		Provider:     sp.Name(),
+	}
+	if info.Properties == "" && info.FireFighting == "" && info.Storage == "" && info.Disposal == "" {
+		return nil, i18n.Errorf("%s: 没有识别出SDS的部分标题", path)
+	}
+	return info, nil
+}
+
+// buildIndex 遍历目录，为每个SDS文件确定CAS号。同一CAS号有多个文件时使用路径排序靠前的文件
+func (sp *SDSProvider) buildIndex() {
+	sp.index = make(map[string]string)
+	err := filepath.WalkDir(sp.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !sdsExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		cas := sds.FindCAS(filepath.Base(path))
+		if cas == "" {
+			text, err := sds.ReadFile(path)
+			if err != nil {
+				slog.Warn("读取SDS文件失败", "file", path, "err", err)
+				return nil
+			}
+			if cas = sds.Parse(text).CAS(); cas == "" {
+				slog.Warn("SDS文件中没有找到CAS号，已跳过", "file", path)
+				return nil
+			}
+		}
+		if _, ok := sp.index[cas]; !ok {
+			sp.index[cas] = path
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("读取SDS目录失败", "dir", sp.Dir, "err", err)
+	}
+}
+
+// SDSCells 按列整理SDS各部分的文字，超出单元格长度的部分截断
+func SDSCells(info *ChemicalInfo) map[string]string {
+	cells := map[string]string{
+		ColumnProperties:   info.Properties,
+		ColumnFireFighting: info.FireFighting,
+		ColumnStorage:      info.Storage,
+		ColumnDisposal:     info.Disposal,
+	}
+	for column, text := range cells {
+		if utf8.RuneCountInString(text) > maxCellLength {
+			cells[column] = string([]rune(text)[:maxCellLength])
+		}
+	}
+	return cells
+}
@@ -0,0 +1,4 @@
+package app
+
+// Version 工具版本，记录在来源信息中
+const Version = "0.2.0"
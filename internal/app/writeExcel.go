@@ -1,23 +1,290 @@
 package app
 
 import (
-	"fmt"
-	"log"
+	"encoding/csv"
+	_ "image/gif" // 嵌入图片时 excelize 需要解码图片尺寸
+	_ "image/jpeg"
+	_ "image/png"
+	"log/slog"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 
+	"cas.mod/internal/i18n"
 	"github.com/xuri/excelize/v2"
 )
 
-// ExcelWriter Excel对象，主要存储了Excel文件的地址
+// ExcelWriter Excel对象，主要存储了Excel文件的地址。
+// 首次写入时打开文件，之后的写入都在内存中进行，Close 时保存一次
 type ExcelWriter struct {
-	FilePath string
+	FilePath   string
+	Provenance ProvenanceOptions // 自动填充值的来源标记
+	Force      bool              // 允许覆盖非空单元格
+
+	conflicts []CellUpdate
+	file      *excelize.File
+	columns   map[string]map[string]int // 各工作表表头文字对应的列号，从1开始
+	nextProv  int                       // 来源工作表下一个空行的行号，0 表示尚未读取
+	dirty     bool
+}
+
+// CellUpdate 一次单元格变更
+type CellUpdate struct {
+	Sheet    string // 工作表名称
+	Column   string // 列名（表头文字）
+	Row      int    // 行号，从1开始
+	OldValue string // 变更前的值，由写入方填充
+	NewValue string // 变更后的值
+	Source   string // 数据来源URL
+	Provider string // 数据源名称，如 ichemistry
+
+	// Overwrite 目标是工具维护的列（如链接检查状态），每次运行都刷新，不做冲突检查
+	Overwrite bool
+}
+
+// ResultWriter 查询结果的写回目标
+type ResultWriter interface {
+	WriteCell(u CellUpdate) error
+	Close() error
+}
+
+// ConflictError 目标单元格已有值，未强制覆盖时拒绝写入
+type ConflictError struct {
+	Update CellUpdate
+}
+
+func (e *ConflictError) Error() string {
+	return i18n.Sprintf("单元格 %s 第 %d 行已有值 %q，跳过写入 %q", e.Update.Column, e.Update.Row, e.Update.OldValue, e.Update.NewValue)
+}
+
+// PictureWriter 支持向单元格嵌入图片的写回目标
+type PictureWriter interface {
+	WritePicture(u CellUpdate, imagePath string) error
+}
+
+// WriteCell 将变更写入Excel文件，并按配置标记来源
+// 写入前重新检查单元格，已有值时除非 Force 否则返回 *ConflictError
+func (ew *ExcelWriter) WriteCell(u CellUpdate) (err error) {
+	defer observeWrite("excel", &err, time.Now())
+
+	if u.Sheet == "" {
+		u.Sheet = "Sheet1"
+	}
+
+	f, cellName, err := ew.cell(u.Sheet, u.Column, u.Row)
+	if err != nil {
+		return err
+	}
+
+	u.OldValue, err = f.GetCellValue(u.Sheet, cellName)
+	if err != nil {
+		return err
+	}
+
+	// 值没有变化时不写入，也不更新来源标记
+	if u.OldValue == u.NewValue {
+		return nil
+	}
+
+	if !ew.Force && !u.Overwrite && !isEmptyValue(u.OldValue) {
+		ew.conflicts = append(ew.conflicts, u)
+		return &ConflictError{Update: u}
+	}
+
+	if err := f.SetCellValue(u.Sheet, cellName, u.NewValue); err != nil {
+		return err
+	}
+	ew.dirty = true
+
+	if ew.Provenance.Enabled() {
+		if err := markProvenance(f, ew.Provenance, u, cellName, time.Now(), &ew.nextProv); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WritePicture 向单元格嵌入图片，单元格已有值或图片时除非 Force 否则返回 *ConflictError
+func (ew *ExcelWriter) WritePicture(u CellUpdate, imagePath string) (err error) {
+	defer observeWrite("excel-picture", &err, time.Now())
+
+	if u.Sheet == "" {
+		u.Sheet = "Sheet1"
+	}
+
+	f, cellName, err := ew.cell(u.Sheet, u.Column, u.Row)
+	if err != nil {
+		return err
+	}
+
+	u.OldValue, err = cellValueOrPicture(f, u.Sheet, cellName)
+	if err != nil {
+		return err
+	}
+
+	if !ew.Force && !u.Overwrite && !isEmptyValue(u.OldValue) {
+		ew.conflicts = append(ew.conflicts, u)
+		return &ConflictError{Update: u}
+	}
+
+	if err := f.DeletePicture(u.Sheet, cellName); err != nil {
+		return err
+	}
+
+	opts := &excelize.GraphicOptions{AltText: u.NewValue, AutoFit: true, LockAspectRatio: true}
+	if err := f.AddPicture(u.Sheet, cellName, imagePath, opts); err != nil {
+		return i18n.Errorf("嵌入图片失败: %v", err)
+	}
+	ew.dirty = true
+
+	if ew.Provenance.Enabled() {
+		if err := markProvenance(f, ew.Provenance, u, cellName, time.Now(), &ew.nextProv); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// open 返回已打开的文件，首次调用时打开
+func (ew *ExcelWriter) open() (*excelize.File, error) {
+	if ew.file != nil {
+		return ew.file, nil
+	}
+	f, err := excelize.OpenFile(ew.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	ew.file = f
+	ew.columns = make(map[string]map[string]int)
+	return f, nil
+}
+
+// headerIndex 返回工作表表头文字对应的列号，每个工作表只读取一次表头
+func (ew *ExcelWriter) headerIndex(f *excelize.File, sheetName string) (map[string]int, error) {
+	if index, ok := ew.columns[sheetName]; ok {
+		return index, nil
+	}
+
+	index, err := readHeaderIndex(f, sheetName)
+	if err != nil {
+		return nil, err
+	}
+	ew.columns[sheetName] = index
+	return index, nil
+}
+
+// readHeaderIndex 只读取工作表第一行，返回表头文字对应的列号
+func readHeaderIndex(f *excelize.File, sheetName string) (map[string]int, error) {
+	rows, err := f.Rows(sheetName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	index := make(map[string]int)
+	if rows.Next() {
+		headers, err := rows.Columns()
+		if err != nil {
+			return nil, err
+		}
+		for i, header := range headers {
+			// 与逐行查找时一致，同名列取第一列
+			if _, ok := index[header]; !ok && header != "" {
+				index[header] = i + 1
+			}
+		}
+	}
+	return index, nil
+}
+
+// cell 返回列名和行号对应的单元格名称
+func (ew *ExcelWriter) cell(sheetName, columnName string, row int) (*excelize.File, string, error) {
+	f, err := ew.open()
+	if err != nil {
+		return nil, "", err
+	}
+
+	index, err := ew.headerIndex(f, sheetName)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(index) == 0 {
+		return nil, "", i18n.Errorf("工作表为空")
+	}
+	colIndex, ok := index[columnName]
+	if !ok {
+		return nil, "", i18n.Errorf("未找到列名: %s", columnName)
+	}
+
+	cellName, err := excelize.CoordinatesToCellName(colIndex, row)
+	if err != nil {
+		return nil, "", err
+	}
+	return f, cellName, nil
+}
+
+// cellValueOrPicture 获取单元格的值，单元格中有图片时返回 "[图片]"
+func cellValueOrPicture(f *excelize.File, sheetName, cellName string) (string, error) {
+	pics, err := f.GetPictures(sheetName, cellName)
+	if err != nil {
+		return "", err
+	}
+	if len(pics) > 0 {
+		return "[图片]", nil
+	}
+	return f.GetCellValue(sheetName, cellName)
+}
+
+// Conflicts 返回因单元格已有值而跳过的写入
+func (ew *ExcelWriter) Conflicts() []CellUpdate {
+	return ew.conflicts
+}
+
+// SaveConflicts 将冲突记录保存为CSV文件
+func SaveConflicts(conflicts []CellUpdate, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"sheet", "row", "column", "current", "new", "source"})
+	for _, c := range conflicts {
+		w.Write([]string{c.Sheet, strconv.Itoa(c.Row), c.Column, c.OldValue, c.NewValue, c.Source})
+	}
+	w.Flush()
+
+	return w.Error()
+}
+
+// Close 实现 ResultWriter，有写入时保存文件，之后的写入会重新打开文件
+func (ew *ExcelWriter) Close() error {
+	if ew.file == nil {
+		return nil
+	}
+	f := ew.file
+	ew.file, ew.columns, ew.nextProv = nil, nil, 0
+
+	var err error
+	if ew.dirty {
+		err = f.Save()
+		ew.dirty = false
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
 }
 
 // getActualSheetName 获取实际的工作表名称
 func (ew *ExcelWriter) getActualSheetName(f *excelize.File, preferredName string) (string, error) {
 	sheets := f.GetSheetList()
 	if len(sheets) == 0 {
-		return "", fmt.Errorf("Excel 文件中没有工作表")
+		return "", i18n.Errorf("Excel 文件中没有工作表")
 	}
 
 	// 如果指定了工作表名称且存在，则使用它
@@ -28,7 +295,7 @@ func (ew *ExcelWriter) getActualSheetName(f *excelize.File, preferredName string
 	}
 
 	// 如果指定的工作表不存在，使用第一个工作表
-	log.Printf("⚠️  警告: 工作表 '%s' 不存在，使用第一个工作表 '%s'\n", preferredName, sheets[0])
+	slog.Warn("工作表不存在，使用第一个工作表", "sheet", preferredName, "using", sheets[0])
 	return sheets[0], nil
 }
 
@@ -36,13 +303,13 @@ func (ew *ExcelWriter) getActualSheetName(f *excelize.File, preferredName string
 func (ew *ExcelWriter) GetSheetList() ([]string, error) {
 	f, err := excelize.OpenFile(ew.FilePath)
 	if err != nil {
-		return nil, fmt.Errorf("打开文件失败: %v", err)
+		return nil, i18n.Errorf("打开文件失败: %v", err)
 	}
 	defer f.Close()
 
 	sheets := f.GetSheetList()
 	if len(sheets) == 0 {
-		return nil, fmt.Errorf("Excel 文件中没有工作表")
+		return nil, i18n.Errorf("Excel 文件中没有工作表")
 	}
 
 	return sheets, nil
@@ -52,7 +319,7 @@ func (ew *ExcelWriter) GetSheetList() ([]string, error) {
 func (ew *ExcelWriter) DetectFormulaColumn(sheetName string) (int, string, error) {
 	f, err := excelize.OpenFile(ew.FilePath)
 	if err != nil {
-		return 0, "", fmt.Errorf("打开文件失败: %v", err)
+		return 0, "", i18n.Errorf("打开文件失败: %v", err)
 	}
 	defer f.Close()
 
@@ -63,7 +330,7 @@ func (ew *ExcelWriter) DetectFormulaColumn(sheetName string) (int, string, error
 
 	headers, err := f.GetRows(actualSheetName)
 	if err != nil || len(headers) == 0 {
-		return 0, "", fmt.Errorf("无法获取表头或工作表为空")
+		return 0, "", i18n.Errorf("无法获取表头或工作表为空")
 	}
 
 	// 查找"化学式"列
@@ -76,7 +343,7 @@ func (ew *ExcelWriter) DetectFormulaColumn(sheetName string) (int, string, error
 		}
 	}
 
-	return 0, "", fmt.Errorf("未找到化学式列")
+	return 0, "", i18n.Errorf("未找到化学式列")
 }
 
 // WriteToCell 向指定列名和行号的单元格写入数据
@@ -118,7 +385,7 @@ func findColumnIndex(f *excelize.File, sheetName, columnName string) (int, error
 	}
 
 	if len(rows) == 0 {
-		return 0, fmt.Errorf("工作表为空")
+		return 0, i18n.Errorf("工作表为空")
 	}
 
 	headers := rows[0]
@@ -128,5 +395,5 @@ func findColumnIndex(f *excelize.File, sheetName, columnName string) (int, error
 		}
 	}
 
-	return 0, fmt.Errorf("未找到列名: %s", columnName)
+	return 0, i18n.Errorf("未找到列名: %s", columnName)
 }
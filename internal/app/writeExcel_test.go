@@ -0,0 +1,67 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestExcelWriterSavesOnClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reagents.xlsx")
+	f := excelize.NewFile()
+	f.SetSheetRow("Sheet1", "A1", &[]string{ColumnCAS, FieldFormula, ColumnDensity})
+	f.SetSheetRow("Sheet1", "A2", &[]string{"7664-93-9", "", "1.84"})
+	f.SetSheetRow("Sheet1", "A3", &[]string{"64-17-5", "C2H6O", ""})
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	before, _ := os.Stat(path)
+
+	ew := &ExcelWriter{FilePath: path}
+	if err := ew.WriteCell(CellUpdate{Column: FieldFormula, Row: 2, NewValue: "H2SO4"}); err != nil {
+		t.Fatal(err)
+	}
+	// 值相同时不算冲突
+	if err := ew.WriteCell(CellUpdate{Column: FieldFormula, Row: 3, NewValue: "C2H6O"}); err != nil {
+		t.Errorf("值相同时应直接返回，得到 %v", err)
+	}
+	var conflict *ConflictError
+	if err := ew.WriteCell(CellUpdate{Column: ColumnDensity, Row: 2, NewValue: "1.83"}); !errors.As(err, &conflict) {
+		t.Errorf("已有值时应返回 *ConflictError，得到 %v", err)
+	}
+	if err := ew.EnsureColumns("Sheet1", []string{FieldInChIKey}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ew.WriteCell(CellUpdate{Column: FieldInChIKey, Row: 2, NewValue: "QAOWNCQODCNURD-UHFFFAOYSA-N"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 关闭前文件不变
+	if after, _ := os.Stat(path); !after.ModTime().Equal(before.ModTime()) || after.Size() != before.Size() {
+		t.Error("关闭前不应保存文件")
+	}
+	if err := ew.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ew.Close(); err != nil {
+		t.Errorf("重复关闭: %v", err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for cell, want := range map[string]string{"B2": "H2SO4", "C2": "1.84", "D1": FieldInChIKey, "D2": "QAOWNCQODCNURD-UHFFFAOYSA-N"} {
+		if got, _ := f.GetCellValue("Sheet1", cell); got != want {
+			t.Errorf("%s = %q, want %q", cell, got, want)
+		}
+	}
+	if len(ew.Conflicts()) != 1 {
+		t.Errorf("Conflicts = %v", ew.Conflicts())
+	}
+}
@@ -0,0 +1,25 @@
+package depict
+
+import "cas.mod/internal/i18n"
+
+// Render 解析SMILES并绘制结构图，format 为 "png" 或 "svg"，返回图片内容和扩展名
+func Render(smiles, format string) ([]byte, string, error) {
+	m, err := ParseSMILES(smiles)
+	if err != nil {
+		return nil, "", err
+	}
+	m.Layout()
+
+	switch format {
+	case "svg":
+		return m.SVG(), ".svg", nil
+	case "png", "":
+		data, err := m.PNG()
+		if err != nil {
+			return nil, "", i18n.Errorf("绘制结构图失败: %v", err)
+		}
+		return data, ".png", nil
+	default:
+		return nil, "", i18n.Errorf("不支持的图片格式: %s", format)
+	}
+}
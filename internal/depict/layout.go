@@ -0,0 +1,287 @@
+package depict
+
+import (
+	"math"
+	"sort"
+)
+
+// maxRingSize 按正多边形布局的最大环大小
+const maxRingSize = 8
+
+// Layout 计算原子的二维坐标，键长为1
+//
+// 以拓扑距离推算每对原子的理想距离（链按120°折线，同一小环内按正多边形的弦长），
+// 先用经典MDS得到初始坐标，再用应力优化调整。多个组分从左到右依次排列。
+func (m *Molecule) Layout() {
+	adj := m.Neighbors()
+	ringDist := m.ringDistances(adj)
+
+	offsetX := 0.0
+	for _, comp := range components(adj) {
+		pos := layoutComponent(comp, adj, ringDist)
+
+		minX, maxX := math.Inf(1), math.Inf(-1)
+		for _, p := range pos {
+			minX = math.Min(minX, p[0])
+			maxX = math.Max(maxX, p[0])
+		}
+		for i, atom := range comp {
+			m.Atoms[atom].X = pos[i][0] - minX + offsetX
+			m.Atoms[atom].Y = pos[i][1]
+		}
+		offsetX += maxX - minX + 1.5
+	}
+}
+
+// components 按原子下标顺序返回各连通组分
+func components(adj [][]int) [][]int {
+	seen := make([]bool, len(adj))
+	var comps [][]int
+	for start := range adj {
+		if seen[start] {
+			continue
+		}
+		comp := []int{start}
+		seen[start] = true
+		for i := 0; i < len(comp); i++ {
+			for _, n := range adj[comp[i]] {
+				if !seen[n] {
+					seen[n] = true
+					comp = append(comp, n)
+				}
+			}
+		}
+		sort.Ints(comp)
+		comps = append(comps, comp)
+	}
+	return comps
+}
+
+// ringDistances 找出每个键所在的最小环，返回同环原子对的正多边形弦长
+func (m *Molecule) ringDistances(adj [][]int) map[[2]int]float64 {
+	dist := make(map[[2]int]float64)
+	for _, b := range m.Bonds {
+		ring := shortestPath(adj, b.A, b.B, true)
+		if ring == nil || len(ring) > maxRingSize {
+			continue
+		}
+		n := len(ring)
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				k := j - i
+				if n-k < k {
+					k = n - k
+				}
+				chord := math.Sin(math.Pi*float64(k)/float64(n)) / math.Sin(math.Pi/float64(n))
+				key := pairKey(ring[i], ring[j])
+				// 稠环中同一对原子可能属于多个环，取较小的距离
+				if d, ok := dist[key]; !ok || chord < d {
+					dist[key] = chord
+				}
+			}
+		}
+	}
+	return dist
+}
+
+// shortestPath 广度优先搜索 from 到 to 的最短路径，skipDirect 为 true 时不走两者之间的直接键
+func shortestPath(adj [][]int, from, to int, skipDirect bool) []int {
+	prev := make([]int, len(adj))
+	for i := range prev {
+		prev[i] = -1
+	}
+	prev[from] = from
+	queue := []int{from}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, n := range adj[cur] {
+			if skipDirect && cur == from && n == to {
+				continue
+			}
+			if prev[n] != -1 {
+				continue
+			}
+			prev[n] = cur
+			if n == to {
+				path := []int{to}
+				for p := cur; p != from; p = prev[p] {
+					path = append(path, p)
+				}
+				return append(path, from)
+			}
+			queue = append(queue, n)
+		}
+	}
+	return nil
+}
+
+func pairKey(a, b int) [2]int {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int{a, b}
+}
+
+// zigzag 120°折线上相隔 k 个键的两个原子的距离
+func zigzag(k int) float64 {
+	x := float64(k) * math.Sqrt(3) / 2
+	if k%2 == 1 {
+		return math.Sqrt(x*x + 0.25)
+	}
+	return x
+}
+
+// layoutComponent 对单个连通组分进行布局，返回与 comp 顺序对应的坐标
+func layoutComponent(comp []int, adj [][]int, ringDist map[[2]int]float64) [][2]float64 {
+	n := len(comp)
+	pos := make([][2]float64, n)
+	if n == 1 {
+		return pos
+	}
+
+	// 理想距离矩阵
+	ideal := make([][]float64, n)
+	for i, atom := range comp {
+		ideal[i] = make([]float64, n)
+		hops := bfsHops(adj, atom)
+		for j, other := range comp {
+			if i == j {
+				continue
+			}
+			if d, ok := ringDist[pairKey(atom, other)]; ok {
+				ideal[i][j] = d
+			} else {
+				ideal[i][j] = zigzag(hops[other])
+			}
+		}
+	}
+
+	pos = classicalMDS(ideal)
+	stressMajorization(pos, ideal, 300)
+	return pos
+}
+
+// bfsHops 返回从 start 出发到各原子的键数
+func bfsHops(adj [][]int, start int) map[int]int {
+	hops := map[int]int{start: 0}
+	queue := []int{start}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, n := range adj[cur] {
+			if _, ok := hops[n]; !ok {
+				hops[n] = hops[cur] + 1
+				queue = append(queue, n)
+			}
+		}
+	}
+	return hops
+}
+
+// classicalMDS 用幂迭代求双中心化距离矩阵的前两个特征向量作为初始坐标
+func classicalMDS(d [][]float64) [][2]float64 {
+	n := len(d)
+	b := make([][]float64, n)
+	rowMean := make([]float64, n)
+	total := 0.0
+	for i := range d {
+		b[i] = make([]float64, n)
+		for j := range d[i] {
+			sq := d[i][j] * d[i][j]
+			b[i][j] = sq
+			rowMean[i] += sq / float64(n)
+			total += sq / float64(n*n)
+		}
+	}
+	for i := range b {
+		for j := range b[i] {
+			b[i][j] = -0.5 * (b[i][j] - rowMean[i] - rowMean[j] + total)
+		}
+	}
+
+	pos := make([][2]float64, n)
+	var prev []float64
+	for axis := 0; axis < 2; axis++ {
+		v := make([]float64, n)
+		for i := range v {
+			// 确定性的初始向量，保证同一SMILES每次布局一致
+			v[i] = math.Sin(float64(i+1)*(1.3+float64(axis))) + 0.01*float64(i)
+		}
+
+		var lambda float64
+		for iter := 0; iter < 200; iter++ {
+			next := make([]float64, n)
+			for i := range b {
+				for j := range b[i] {
+					next[i] += b[i][j] * v[j]
+				}
+			}
+			if prev != nil {
+				// 去掉第一个特征向量的分量
+				dot := 0.0
+				for i := range next {
+					dot += next[i] * prev[i]
+				}
+				for i := range next {
+					next[i] -= dot * prev[i]
+				}
+			}
+			lambda = norm(next)
+			if lambda < 1e-12 {
+				break
+			}
+			for i := range next {
+				next[i] /= lambda
+			}
+			v = next
+		}
+
+		scale := math.Sqrt(math.Max(lambda, 0))
+		for i := range pos {
+			pos[i][axis] = v[i] * scale
+		}
+		prev = v
+	}
+
+	return pos
+}
+
+func norm(v []float64) float64 {
+	s := 0.0
+	for _, x := range v {
+		s += x * x
+	}
+	return math.Sqrt(s)
+}
+
+// stressMajorization 按权重 1/d² 迭代最小化布局应力
+func stressMajorization(pos [][2]float64, ideal [][]float64, iterations int) {
+	n := len(pos)
+	for iter := 0; iter < iterations; iter++ {
+		for i := 0; i < n; i++ {
+			var sumW, x, y float64
+			for j := 0; j < n; j++ {
+				if i == j || ideal[i][j] == 0 {
+					continue
+				}
+				w := 1 / (ideal[i][j] * ideal[i][j])
+				dx := pos[i][0] - pos[j][0]
+				dy := pos[i][1] - pos[j][1]
+				dist := math.Hypot(dx, dy)
+				if dist < 1e-9 {
+					// 重合时沿固定方向推开
+					dx, dy, dist = 1e-3*float64(i-j), 1e-3, math.Hypot(1e-3*float64(i-j), 1e-3)
+				}
+				x += w * (pos[j][0] + ideal[i][j]*dx/dist)
+				y += w * (pos[j][1] + ideal[i][j]*dy/dist)
+				sumW += w
+			}
+			if sumW > 0 {
+				pos[i][0] = x / sumW
+				pos[i][1] = y / sumW
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package depict
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// molBondLength 写入MOL文件时的键长(Å)，布局坐标的键长为1
+const molBondLength = 1.5
+
+// Molfile 生成 MDL V2000 MOL 块，坐标取自 Layout，需先调用 Layout。
+// 芳香键写为键类型 4，方括号原子的氢原子数不单独写出
+func (m *Molecule) Molfile(name string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n  cas.mod 2D\n\n", name)
+	fmt.Fprintf(&buf, "%3d%3d  0  0  0  0  0  0  0  0999 V2000\n", len(m.Atoms), len(m.Bonds))
+	for _, a := range m.Atoms {
+		// MOL 文件的 y 轴向上，布局坐标与SVG一致向下
+		fmt.Fprintf(&buf, "%10.4f%10.4f%10.4f %-3s 0  0  0  0  0  0  0  0  0  0  0  0\n",
+			a.X*molBondLength, -a.Y*molBondLength, 0.0, a.Symbol)
+	}
+	for _, b := range m.Bonds {
+		order := b.Order
+		if b.Aromatic {
+			order = 4
+		}
+		fmt.Fprintf(&buf, "%3d%3d%3d  0\n", b.A+1, b.B+1, order)
+	}
+	// 电荷按每行最多8个写入 M  CHG
+	var charged []int
+	for i, a := range m.Atoms {
+		if a.Charge != 0 {
+			charged = append(charged, i)
+		}
+	}
+	for start := 0; start < len(charged); start += 8 {
+		end := start + 8
+		if end > len(charged) {
+			end = len(charged)
+		}
+		fmt.Fprintf(&buf, "M  CHG%3d", end-start)
+		for _, i := range charged[start:end] {
+			fmt.Fprintf(&buf, " %3d %3d", i+1, m.Atoms[i].Charge)
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("M  END\n")
+	return buf.Bytes()
+}
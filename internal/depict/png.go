@@ -0,0 +1,120 @@
+package depict
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+)
+
+// PNG 将已布局的分子绘制为PNG
+func (m *Molecule) PNG() ([]byte, error) {
+	sc := buildScene(m)
+	img := image.NewRGBA(image.Rect(0, 0, int(math.Ceil(sc.width)), int(math.Ceil(sc.height))))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+
+	for _, l := range sc.lines {
+		drawLine(img, l.x1, l.y1, l.x2, l.y2)
+	}
+	for _, c := range sc.circles {
+		steps := int(2 * math.Pi * c.r)
+		for s := 0; s <= steps; s++ {
+			angle := 2 * math.Pi * float64(s) / float64(steps)
+			stamp(img, c.cx+c.r*math.Cos(angle), c.cy+c.r*math.Sin(angle))
+		}
+	}
+	for _, l := range sc.labels {
+		drawLabel(img, l)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawLine 沿线段逐点盖印画出有宽度的线
+func drawLine(img *image.RGBA, x1, y1, x2, y2 float64) {
+	length := math.Hypot(x2-x1, y2-y1)
+	steps := int(length*2) + 1
+	for s := 0; s <= steps; s++ {
+		t := float64(s) / float64(steps)
+		stamp(img, x1+(x2-x1)*t, y1+(y2-y1)*t)
+	}
+}
+
+// stamp 以线宽为直径画一个实心圆点
+func stamp(img *image.RGBA, x, y float64) {
+	r := lineWidth / 2
+	for py := int(math.Floor(y - r)); py <= int(math.Ceil(y+r)); py++ {
+		for px := int(math.Floor(x - r)); px <= int(math.Ceil(x+r)); px++ {
+			if math.Hypot(float64(px)+0.5-x, float64(py)+0.5-y) <= r+0.3 {
+				img.Set(px, py, color.Black)
+			}
+		}
+	}
+}
+
+// drawLabel 用点阵字体绘制原子标签，氢原子数下标、电荷上标
+func drawLabel(img *image.RGBA, l label) {
+	const scale, small = 2, 1
+	type run struct {
+		text  string
+		scale int
+		dy    int
+	}
+	runs := []run{{l.symbol, scale, 0}}
+	if l.hydro != "" {
+		runs = append(runs, run{"H", scale, 0})
+		if n := l.hydro[1:]; n != "" {
+			runs = append(runs, run{n, small, glyphHeight * scale / 2})
+		}
+	}
+	if l.charge != "" {
+		runs = append(runs, run{l.charge, small, -glyphHeight * scale / 4})
+	}
+
+	width := 0
+	for _, r := range runs {
+		width += len(r.text) * (glyphWidth + 1) * r.scale
+	}
+
+	// 以元素符号为中心，先清出白色背景
+	x := int(l.x) - len(l.symbol)*(glyphWidth+1)*scale/2
+	y := int(l.y) - glyphHeight*scale/2
+	for py := y - 2; py < y+glyphHeight*scale+2; py++ {
+		for px := x - 2; px < x+width+2; px++ {
+			img.Set(px, py, color.White)
+		}
+	}
+
+	for _, r := range runs {
+		for _, ch := range r.text {
+			drawGlyph(img, ch, x, y+r.dy, r.scale)
+			x += (glyphWidth + 1) * r.scale
+		}
+	}
+}
+
+func drawGlyph(img *image.RGBA, ch rune, x, y, scale int) {
+	g, ok := glyphs[ch]
+	if !ok {
+		return
+	}
+	for row := 0; row < glyphHeight; row++ {
+		for col := 0; col < glyphWidth; col++ {
+			if g[row]&(1<<(glyphWidth-1-col)) == 0 {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.Set(x+col*scale+dx, y+row*scale+dy, color.Black)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package depict
+
+import (
+	"math"
+	"sort"
+	"strconv"
+)
+
+// 绘图参数，单位为像素
+const (
+	bondPixels   = 30.0 // 键长
+	margin       = 20.0 // 边距
+	lineWidth    = 1.6
+	doubleOffset = 4.0  // 双键两条线的间距
+	labelRadius  = 8.0  // 原子标签周围不画键的半径
+	fontPixels   = 14.0 // 标签字号
+)
+
+// line 绘图中的线段
+type line struct {
+	x1, y1, x2, y2 float64
+}
+
+// circle 芳香环内的圆
+type circle struct {
+	cx, cy, r float64
+}
+
+// label 原子标签，如 O、NH2、N+
+type label struct {
+	x, y   float64
+	symbol string // 元素符号
+	hydro  string // 氢原子部分，如 H、H2
+	charge string // 电荷，如 +、2-
+}
+
+// scene 与输出格式无关的绘图元素
+type scene struct {
+	width, height float64
+	lines         []line
+	circles       []circle
+	labels        []label
+}
+
+// buildScene 将已布局的分子转换为像素坐标下的绘图元素
+func buildScene(m *Molecule) *scene {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, a := range m.Atoms {
+		minX, maxX = math.Min(minX, a.X), math.Max(maxX, a.X)
+		minY, maxY = math.Min(minY, a.Y), math.Max(maxY, a.Y)
+	}
+	// 没有原子时画空白图片，不产生无穷大的尺寸
+	if len(m.Atoms) == 0 {
+		minX, minY, maxX, maxY = 0, 0, 0, 0
+	}
+
+	sc := &scene{
+		width:  (maxX-minX)*bondPixels + 2*margin,
+		height: (maxY-minY)*bondPixels + 2*margin,
+	}
+	px := func(i int) (float64, float64) {
+		a := m.Atoms[i]
+		// 图片坐标的 y 轴向下
+		return (a.X-minX)*bondPixels + margin, (maxY-a.Y)*bondPixels + margin
+	}
+
+	adj := m.Neighbors()
+	labeled := make([]bool, len(m.Atoms))
+	for i, a := range m.Atoms {
+		if a.Symbol != "C" || a.Charge != 0 || len(adj[i]) == 0 {
+			labeled[i] = true
+			x, y := px(i)
+			sc.labels = append(sc.labels, atomLabel(a, x, y))
+		}
+	}
+
+	rings := m.smallRings(adj)
+	for _, b := range m.Bonds {
+		x1, y1 := px(b.A)
+		x2, y2 := px(b.B)
+		x1, y1, x2, y2 = shorten(x1, y1, x2, y2, labeled[b.A], labeled[b.B])
+
+		switch b.Order {
+		case 2:
+			// 环内的双键在环内侧画第二条线，其余画两条平行线
+			if cx, cy, ok := ringCenter(m, rings, b, px); ok {
+				sc.lines = append(sc.lines, line{x1, y1, x2, y2}, innerLine(x1, y1, x2, y2, cx, cy))
+			} else {
+				a1, a2 := offsetLine(x1, y1, x2, y2, doubleOffset/2)
+				sc.lines = append(sc.lines, a1, a2)
+			}
+		case 3:
+			a1, a2 := offsetLine(x1, y1, x2, y2, doubleOffset)
+			sc.lines = append(sc.lines, line{x1, y1, x2, y2}, a1, a2)
+		default:
+			sc.lines = append(sc.lines, line{x1, y1, x2, y2})
+		}
+	}
+
+	// 芳香环画内切圆
+	for _, ring := range rings {
+		aromatic := true
+		for _, atom := range ring {
+			aromatic = aromatic && m.Atoms[atom].Aromatic
+		}
+		if !aromatic {
+			continue
+		}
+		var cx, cy float64
+		for _, atom := range ring {
+			x, y := px(atom)
+			cx += x / float64(len(ring))
+			cy += y / float64(len(ring))
+		}
+		apothem := bondPixels / (2 * math.Tan(math.Pi/float64(len(ring))))
+		sc.circles = append(sc.circles, circle{cx, cy, apothem * 0.6})
+	}
+
+	return sc
+}
+
+// atomLabel 生成原子标签
+func atomLabel(a Atom, x, y float64) label {
+	l := label{x: x, y: y, symbol: a.Symbol}
+	switch {
+	case a.HCount == 1:
+		l.hydro = "H"
+	case a.HCount > 1:
+		l.hydro = "H" + strconv.Itoa(a.HCount)
+	}
+	switch {
+	case a.Charge == 1:
+		l.charge = "+"
+	case a.Charge == -1:
+		l.charge = "-"
+	case a.Charge > 1:
+		l.charge = strconv.Itoa(a.Charge) + "+"
+	case a.Charge < -1:
+		l.charge = strconv.Itoa(-a.Charge) + "-"
+	}
+	return l
+}
+
+// shorten 在有标签的一端留出空白
+func shorten(x1, y1, x2, y2 float64, cut1, cut2 bool) (float64, float64, float64, float64) {
+	length := math.Hypot(x2-x1, y2-y1)
+	if length < 1e-9 {
+		return x1, y1, x2, y2
+	}
+	ux, uy := (x2-x1)/length, (y2-y1)/length
+	if cut1 {
+		x1, y1 = x1+ux*labelRadius, y1+uy*labelRadius
+	}
+	if cut2 {
+		x2, y2 = x2-ux*labelRadius, y2-uy*labelRadius
+	}
+	return x1, y1, x2, y2
+}
+
+// offsetLine 返回与线段平行、两侧各偏移 d 的两条线段
+func offsetLine(x1, y1, x2, y2, d float64) (line, line) {
+	length := math.Hypot(x2-x1, y2-y1)
+	if length < 1e-9 {
+		return line{x1, y1, x2, y2}, line{x1, y1, x2, y2}
+	}
+	nx, ny := -(y2-y1)/length*d, (x2-x1)/length*d
+	return line{x1 + nx, y1 + ny, x2 + nx, y2 + ny}, line{x1 - nx, y1 - ny, x2 - nx, y2 - ny}
+}
+
+// innerLine 环内双键的第二条线，偏向环中心并略短
+func innerLine(x1, y1, x2, y2, cx, cy float64) line {
+	a, b := offsetLine(x1, y1, x2, y2, doubleOffset)
+	inner := a
+	if math.Hypot((b.x1+b.x2)/2-cx, (b.y1+b.y2)/2-cy) < math.Hypot((a.x1+a.x2)/2-cx, (a.y1+a.y2)/2-cy) {
+		inner = b
+	}
+	dx, dy := (inner.x2-inner.x1)*0.15, (inner.y2-inner.y1)*0.15
+	return line{inner.x1 + dx, inner.y1 + dy, inner.x2 - dx, inner.y2 - dy}
+}
+
+// ringCenter 返回键所在小环的中心
+func ringCenter(m *Molecule, rings [][]int, b Bond, px func(int) (float64, float64)) (float64, float64, bool) {
+	for _, ring := range rings {
+		hasA, hasB := false, false
+		for _, atom := range ring {
+			hasA = hasA || atom == b.A
+			hasB = hasB || atom == b.B
+		}
+		if !hasA || !hasB {
+			continue
+		}
+		var cx, cy float64
+		for _, atom := range ring {
+			x, y := px(atom)
+			cx += x / float64(len(ring))
+			cy += y / float64(len(ring))
+		}
+		return cx, cy, true
+	}
+	return 0, 0, false
+}
+
+// smallRings 返回不重复的小环
+func (m *Molecule) smallRings(adj [][]int) [][]int {
+	seen := make(map[string]bool)
+	var rings [][]int
+	for _, b := range m.Bonds {
+		ring := shortestPath(adj, b.A, b.B, true)
+		if ring == nil || len(ring) > maxRingSize {
+			continue
+		}
+		sorted := append([]int(nil), ring...)
+		sort.Ints(sorted)
+		key := ""
+		for _, a := range sorted {
+			key += strconv.Itoa(a) + ","
+		}
+		if !seen[key] {
+			seen[key] = true
+			rings = append(rings, ring)
+		}
+	}
+	return rings
+}
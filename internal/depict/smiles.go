@@ -0,0 +1,347 @@
+// Package depict 解析SMILES并生成二维结构图（SVG/PNG），不依赖外部图片服务。
+package depict
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"cas.mod/internal/i18n"
+)
+
+// Atom 分子中的原子
+type Atom struct {
+	Symbol   string // 元素符号，芳香原子也使用大写形式，如 C、N
+	Aromatic bool
+	Charge   int
+	HCount   int  // 氢原子数，方括号原子为显式值，其余按常见价态推算
+	Bracket  bool // 是否为方括号原子
+	X, Y     float64
+}
+
+// Bond 原子间的化学键
+type Bond struct {
+	A, B     int // 原子下标
+	Order    int // 1 单键，2 双键，3 三键
+	Aromatic bool
+}
+
+// Molecule 由SMILES解析得到的分子图
+type Molecule struct {
+	Atoms []Atom
+	Bonds []Bond
+}
+
+// organicSubset 方括号外可直接书写的元素
+var organicSubset = []string{"Cl", "Br", "B", "C", "N", "O", "P", "S", "F", "I"}
+
+// defaultValences 推算隐式氢时使用的常见价态
+var defaultValences = map[string][]int{
+	"B": {3}, "C": {4}, "N": {3, 5}, "O": {2}, "P": {3, 5}, "S": {2, 4, 6},
+	"F": {1}, "Cl": {1}, "Br": {1}, "I": {1},
+}
+
+// ParseSMILES 解析SMILES字符串，支持分支、环闭合、方括号原子、芳香原子和 "." 分隔的多组分
+func ParseSMILES(smiles string) (*Molecule, error) {
+	p := &smilesParser{src: strings.TrimSpace(smiles), prev: -1, rings: map[int]ringOpen{}}
+	if p.src == "" {
+		return nil, i18n.Errorf("SMILES为空")
+	}
+	if err := p.parse(); err != nil {
+		return nil, i18n.Errorf("解析SMILES %q 失败: 位置 %d: %v", smiles, p.pos, err)
+	}
+	if len(p.mol.Atoms) == 0 {
+		return nil, i18n.Errorf("SMILES %q 中没有原子", smiles)
+	}
+	p.mol.addImplicitHydrogens()
+	return &p.mol, nil
+}
+
+// ringOpen 尚未闭合的环
+type ringOpen struct {
+	atom  int
+	order int
+}
+
+type smilesParser struct {
+	src   string
+	pos   int
+	mol   Molecule
+	prev  int   // 上一个原子，-1 表示新的组分
+	stack []int // 分支起点
+	bond  int   // 待使用的键级，0 表示默认
+	rings map[int]ringOpen
+}
+
+func (p *smilesParser) parse() error {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '(':
+			if p.prev < 0 {
+				return i18n.Errorf("分支前没有原子")
+			}
+			p.stack = append(p.stack, p.prev)
+			p.pos++
+		case c == ')':
+			if len(p.stack) == 0 {
+				return i18n.Errorf("括号不匹配")
+			}
+			p.prev = p.stack[len(p.stack)-1]
+			p.stack = p.stack[:len(p.stack)-1]
+			p.pos++
+		case c == '.':
+			p.prev = -1
+			p.pos++
+		case c == '-' || c == '/' || c == '\\':
+			p.bond = 1
+			p.pos++
+		case c == '=':
+			p.bond = 2
+			p.pos++
+		case c == '#':
+			p.bond = 3
+			p.pos++
+		case c == ':':
+			p.bond = 1
+			p.pos++
+		case c >= '0' && c <= '9' || c == '%':
+			if err := p.ringClosure(); err != nil {
+				return err
+			}
+		case c == '[':
+			if err := p.bracketAtom(); err != nil {
+				return err
+			}
+		default:
+			if err := p.organicAtom(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(p.stack) > 0 {
+		return i18n.Errorf("括号不匹配")
+	}
+	if len(p.rings) > 0 {
+		return i18n.Errorf("存在未闭合的环")
+	}
+	return nil
+}
+
+// addAtom 添加原子并与上一个原子成键
+func (p *smilesParser) addAtom(a Atom) {
+	p.mol.Atoms = append(p.mol.Atoms, a)
+	idx := len(p.mol.Atoms) - 1
+	if p.prev >= 0 {
+		p.mol.addBond(p.prev, idx, p.bond)
+	}
+	p.prev = idx
+	p.bond = 0
+}
+
+func (p *smilesParser) organicAtom() error {
+	if p.src[p.pos] == '*' {
+		p.pos++
+		p.addAtom(Atom{Symbol: "*"})
+		return nil
+	}
+
+	for _, sym := range organicSubset {
+		if strings.HasPrefix(p.src[p.pos:], sym) {
+			p.pos += len(sym)
+			p.addAtom(Atom{Symbol: sym})
+			return nil
+		}
+	}
+
+	// 芳香原子
+	for _, sym := range []string{"b", "c", "n", "o", "p", "s"} {
+		if strings.HasPrefix(p.src[p.pos:], sym) {
+			p.pos += len(sym)
+			p.addAtom(Atom{Symbol: strings.ToUpper(sym), Aromatic: true})
+			return nil
+		}
+	}
+
+	return i18n.Errorf("无法识别的字符 %q", p.src[p.pos])
+}
+
+func (p *smilesParser) bracketAtom() error {
+	end := strings.IndexByte(p.src[p.pos:], ']')
+	if end < 0 {
+		return i18n.Errorf("方括号不匹配")
+	}
+	body := p.src[p.pos+1 : p.pos+end]
+	p.pos += end + 1
+
+	i := 0
+	// 同位素
+	for i < len(body) && unicode.IsDigit(rune(body[i])) {
+		i++
+	}
+
+	if i >= len(body) {
+		return i18n.Errorf("方括号中缺少元素")
+	}
+
+	a := Atom{Bracket: true}
+	switch {
+	case body[i] == '*':
+		a.Symbol = "*"
+		i++
+	case unicode.IsUpper(rune(body[i])):
+		a.Symbol = body[i : i+1]
+		i++
+		// 方括号中氢原子数使用大写 H，紧随的小写字母都属于元素符号
+		if i < len(body) && unicode.IsLower(rune(body[i])) {
+			a.Symbol += body[i : i+1]
+			i++
+		}
+	case unicode.IsLower(rune(body[i])):
+		// 芳香原子，如 [nH]、[se]
+		j := i + 1
+		if j < len(body) && (body[i:j+1] == "se" || body[i:j+1] == "as") {
+			j++
+		}
+		a.Symbol = strings.ToUpper(body[i:i+1]) + body[i+1:j]
+		a.Aromatic = true
+		i = j
+	default:
+		return i18n.Errorf("方括号中的元素无效: %q", body)
+	}
+
+	// 手性标记
+	for i < len(body) && body[i] == '@' {
+		i++
+	}
+
+	// 氢原子数
+	if i < len(body) && body[i] == 'H' {
+		i++
+		a.HCount = 1
+		if j := digitsEnd(body, i); j > i {
+			a.HCount, _ = strconv.Atoi(body[i:j])
+			i = j
+		}
+	}
+
+	// 电荷，支持 +、++、+2 等写法
+	for i < len(body) && (body[i] == '+' || body[i] == '-') {
+		sign := 1
+		if body[i] == '-' {
+			sign = -1
+		}
+		i++
+		if j := digitsEnd(body, i); j > i {
+			n, _ := strconv.Atoi(body[i:j])
+			a.Charge += sign * n
+			i = j
+		} else {
+			a.Charge += sign
+		}
+	}
+
+	// 原子类别 :n
+	if i < len(body) && body[i] == ':' {
+		i = digitsEnd(body, i+1)
+	}
+
+	if i != len(body) {
+		return i18n.Errorf("方括号原子 %q 无法解析", body)
+	}
+
+	p.addAtom(a)
+	return nil
+}
+
+func (p *smilesParser) ringClosure() error {
+	if p.prev < 0 {
+		return i18n.Errorf("环闭合前没有原子")
+	}
+
+	var num int
+	if p.src[p.pos] == '%' {
+		if p.pos+3 > len(p.src) {
+			return i18n.Errorf("环编号不完整")
+		}
+		n, err := strconv.Atoi(p.src[p.pos+1 : p.pos+3])
+		if err != nil {
+			return i18n.Errorf("环编号无效")
+		}
+		num = n
+		p.pos += 3
+	} else {
+		num = int(p.src[p.pos] - '0')
+		p.pos++
+	}
+
+	if open, ok := p.rings[num]; ok {
+		order := p.bond
+		if order == 0 {
+			order = open.order
+		}
+		p.mol.addBond(open.atom, p.prev, order)
+		delete(p.rings, num)
+	} else {
+		p.rings[num] = ringOpen{atom: p.prev, order: p.bond}
+	}
+	p.bond = 0
+	return nil
+}
+
+func digitsEnd(s string, i int) int {
+	for i < len(s) && unicode.IsDigit(rune(s[i])) {
+		i++
+	}
+	return i
+}
+
+// addBond 添加化学键，order 为 0 时两端都是芳香原子则为芳香键，否则为单键
+func (m *Molecule) addBond(a, b, order int) {
+	aromatic := false
+	if order == 0 {
+		order = 1
+		aromatic = m.Atoms[a].Aromatic && m.Atoms[b].Aromatic
+	}
+	m.Bonds = append(m.Bonds, Bond{A: a, B: b, Order: order, Aromatic: aromatic})
+}
+
+// addImplicitHydrogens 按常见价态推算非方括号原子的氢原子数
+func (m *Molecule) addImplicitHydrogens() {
+	valence := make([]int, len(m.Atoms))
+	for _, b := range m.Bonds {
+		valence[b.A] += b.Order
+		valence[b.B] += b.Order
+	}
+
+	for i := range m.Atoms {
+		a := &m.Atoms[i]
+		if a.Bracket {
+			continue
+		}
+		used := valence[i]
+		valences := defaultValences[a.Symbol]
+		if a.Aromatic && len(valences) > 0 {
+			// 芳香原子有一个电子参与共轭，只按最低价态计算
+			used++
+			valences = valences[:1]
+		}
+		for _, v := range valences {
+			if v >= used {
+				a.HCount = v - used
+				break
+			}
+		}
+	}
+}
+
+// Neighbors 返回每个原子的相邻原子
+func (m *Molecule) Neighbors() [][]int {
+	adj := make([][]int, len(m.Atoms))
+	for _, b := range m.Bonds {
+		adj[b.A] = append(adj[b.A], b.B)
+		adj[b.B] = append(adj[b.B], b.A)
+	}
+	return adj
+}
@@ -0,0 +1,121 @@
+package depict
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseSMILES(t *testing.T) {
+	tests := []struct {
+		smiles  string
+		atoms   int
+		bonds   int
+		hydro   int // 所有原子的氢原子数之和
+		charges int // 所有原子的电荷之和
+	}{
+		{smiles: "CCO", atoms: 3, bonds: 2, hydro: 6},
+		{smiles: "c1ccccc1", atoms: 6, bonds: 6, hydro: 6},
+		{smiles: "OS(=O)(=O)O", atoms: 5, bonds: 4, hydro: 2},
+		{smiles: "CCCCCO[N+](=O)[O-]", atoms: 9, bonds: 8, hydro: 11},
+		{smiles: "[Na+].[Cl-]", atoms: 2, bonds: 0, charges: 0},
+		{smiles: "c1cc[nH]c1", atoms: 5, bonds: 5, hydro: 5},
+		{smiles: "Cn1cnc2c1c(=O)n(C)c(=O)n2C", atoms: 14, bonds: 15, hydro: 10},
+		{smiles: "C%10CC%10", atoms: 3, bonds: 3, hydro: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.smiles, func(t *testing.T) {
+			m, err := ParseSMILES(tt.smiles)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(m.Atoms) != tt.atoms || len(m.Bonds) != tt.bonds {
+				t.Errorf("原子 %d 键 %d, want %d %d", len(m.Atoms), len(m.Bonds), tt.atoms, tt.bonds)
+			}
+			hydro, charges := 0, 0
+			for _, a := range m.Atoms {
+				hydro += a.HCount
+				charges += a.Charge
+			}
+			if hydro != tt.hydro || charges != tt.charges {
+				t.Errorf("氢 %d 电荷 %d, want %d %d", hydro, charges, tt.hydro, tt.charges)
+			}
+		})
+	}
+}
+
+func TestParseSMILESErrors(t *testing.T) {
+	for _, smiles := range []string{"", "C(C", "C1CC", "CC)", "[Na", "CX", "#", ".", "="} {
+		if _, err := ParseSMILES(smiles); err == nil {
+			t.Errorf("ParseSMILES(%q) 应返回错误", smiles)
+		}
+	}
+}
+
+func TestRenderEmpty(t *testing.T) {
+	m := &Molecule{}
+	m.Layout()
+	if _, err := m.PNG(); err != nil {
+		t.Errorf("PNG: %v", err)
+	}
+}
+
+func FuzzRender(f *testing.F) {
+	for _, smiles := range []string{"c1ccccc1O", "CC(=O)O", "[Na+].[Cl-]", "#", "C1CC"} {
+		f.Add(smiles)
+	}
+	f.Fuzz(func(t *testing.T, smiles string) {
+		Render(smiles, "png")
+		Render(smiles, "svg")
+	})
+}
+
+func TestLayoutBondLength(t *testing.T) {
+	m, err := ParseSMILES("CC(=O)Oc1ccccc1C(=O)O")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Layout()
+
+	for _, b := range m.Bonds {
+		a1, a2 := m.Atoms[b.A], m.Atoms[b.B]
+		dx, dy := a1.X-a2.X, a1.Y-a2.Y
+		if d := dx*dx + dy*dy; d < 0.7 || d > 1.3 {
+			t.Errorf("键 %d-%d 长度的平方 %.2f 偏离 1", b.A, b.B, d)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	data, ext, err := Render("c1ccccc1O", "png")
+	if err != nil || ext != ".png" || !bytes.HasPrefix(data, []byte("\x89PNG")) {
+		t.Fatalf("PNG: %v %s", err, ext)
+	}
+
+	data, ext, err = Render("c1ccccc1O", "svg")
+	if err != nil || ext != ".svg" || !bytes.Contains(data, []byte("<svg")) {
+		t.Fatalf("SVG: %v %s", err, ext)
+	}
+}
+
+func TestMolfile(t *testing.T) {
+	m, err := ParseSMILES("CCCCCO[N+](=O)[O-]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Layout()
+	lines := strings.Split(string(m.Molfile("amyl nitrate")), "\n")
+	if lines[0] != "amyl nitrate" {
+		t.Errorf("名称行 = %q", lines[0])
+	}
+	if got := lines[3]; !strings.HasPrefix(got, "  9  8") || !strings.HasSuffix(got, "V2000") {
+		t.Errorf("计数行 = %q", got)
+	}
+	if got, want := lines[4+9+8], "M  CHG  2   7   1   9  -1"; got != want {
+		t.Errorf("电荷行 = %q, want %q", got, want)
+	}
+	if lines[len(lines)-2] != "M  END" {
+		t.Errorf("缺少 M  END")
+	}
+}
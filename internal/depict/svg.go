@@ -0,0 +1,47 @@
+package depict
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+)
+
+// SVG 将已布局的分子绘制为SVG
+func (m *Molecule) SVG() []byte {
+	sc := buildScene(m)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.1f %.1f">`+"\n",
+		sc.width, sc.height, sc.width, sc.height)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="white"/>`+"\n")
+
+	fmt.Fprintf(&buf, `<g stroke="black" stroke-width="%.1f" stroke-linecap="round" fill="none">`+"\n", lineWidth)
+	for _, l := range sc.lines {
+		fmt.Fprintf(&buf, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f"/>`+"\n", l.x1, l.y1, l.x2, l.y2)
+	}
+	for _, c := range sc.circles {
+		fmt.Fprintf(&buf, `<circle cx="%.1f" cy="%.1f" r="%.1f"/>`+"\n", c.cx, c.cy, c.r)
+	}
+	buf.WriteString("</g>\n")
+
+	fmt.Fprintf(&buf, `<g font-family="Arial, Helvetica, sans-serif" font-size="%.0f" text-anchor="middle" dominant-baseline="central">`+"\n", fontPixels)
+	for _, l := range sc.labels {
+		fmt.Fprintf(&buf, `<text x="%.1f" y="%.1f">%s`, l.x, l.y, html.EscapeString(l.symbol))
+		if l.hydro != "" {
+			fmt.Fprintf(&buf, `<tspan>H</tspan>`)
+			if n := l.hydro[1:]; n != "" {
+				fmt.Fprintf(&buf, `<tspan font-size="%.0f" dy="4">%s</tspan>`, fontPixels*0.7, n)
+				if l.charge != "" {
+					fmt.Fprintf(&buf, `<tspan dy="-4"></tspan>`)
+				}
+			}
+		}
+		if l.charge != "" {
+			fmt.Fprintf(&buf, `<tspan font-size="%.0f" dy="-6">%s</tspan>`, fontPixels*0.7, l.charge)
+		}
+		buf.WriteString("</text>\n")
+	}
+	buf.WriteString("</g>\n</svg>\n")
+
+	return buf.Bytes()
+}
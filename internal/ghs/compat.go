@@ -0,0 +1,89 @@
+package ghs
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Class 储存相容性检查使用的危险类别
+type Class string
+
+// 危险类别
+const (
+	Explosive     Class = "爆炸物"
+	Flammable     Class = "易燃物"
+	Pyrophoric    Class = "自燃物"
+	WaterReactive Class = "遇水反应物"
+	Oxidizer      Class = "氧化剂"
+	Reducer       Class = "还原剂"
+	Acid          Class = "酸"
+	Base          Class = "碱"
+)
+
+// classRanges H 代码区间到危险类别，区间为闭区间
+var classRanges = []struct {
+	from, to int
+	class    Class
+}{
+	{200, 208, Explosive},
+	{220, 232, Flammable},
+	{240, 242, Oxidizer}, // 自反应物质和有机过氧化物按氧化剂隔离
+	{250, 250, Pyrophoric},
+	{251, 252, Flammable},
+	{260, 261, WaterReactive},
+	{270, 272, Oxidizer},
+}
+
+// incompatible 内置的相容性矩阵，列出不得同处储存的类别组合，检查时双向生效
+var incompatible = map[Class][]Class{
+	Oxidizer:   {Flammable, Pyrophoric, Explosive, Reducer},
+	Explosive:  {Flammable, Pyrophoric},
+	Pyrophoric: {Flammable},
+	Acid:       {Base, WaterReactive},
+	Base:       {WaterReactive},
+}
+
+// Classes 根据危险性说明代码推断危险类别，按名称排序
+func (h Hazard) Classes() []Class {
+	set := make(map[Class]bool)
+	for _, code := range h.HazardStatements {
+		if len(code) < 4 {
+			continue
+		}
+		n, err := strconv.Atoi(code[1:4])
+		if err != nil {
+			continue
+		}
+		for _, r := range classRanges {
+			if n >= r.from && n <= r.to {
+				set[r.class] = true
+			}
+		}
+	}
+	return SortClasses(set)
+}
+
+// SortClasses 将类别集合转换为有序列表
+func SortClasses(set map[Class]bool) []Class {
+	classes := make([]Class, 0, len(set))
+	for c := range set {
+		classes = append(classes, c)
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i] < classes[j] })
+	return classes
+}
+
+// Incompatible 两个类别是否不得同处储存
+func Incompatible(a, b Class) bool {
+	for _, c := range incompatible[a] {
+		if c == b {
+			return true
+		}
+	}
+	for _, c := range incompatible[b] {
+		if c == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+// Package ghs 全球化学品统一分类和标签制度(GHS)：象形图、警示词和 H/P 代码，
+// 以及由内嵌代码表生成的中英文标准文本
+package ghs
+
+import (
+	_ "embed"
+	"regexp"
+	"strings"
+)
+
+//go:embed statements.tsv
+var statementsTSV string
+
+// 警示词
+const (
+	Danger  = "Danger"
+	Warning = "Warning"
+)
+
+// Hazard 一种化学品的 GHS 危险性分类
+type Hazard struct {
+	Pictograms              []string // 象形图，如 GHS05
+	SignalWord              string   // 警示词，Danger 或 Warning
+	HazardStatements        []string // 危险性说明代码，如 H314
+	PrecautionaryStatements []string // 防范说明代码，组合代码以 + 连接，如 P301+P330+P331
+}
+
+// Empty 没有任何分类信息
+func (h Hazard) Empty() bool {
+	return len(h.Pictograms) == 0 && h.SignalWord == "" &&
+		len(h.HazardStatements) == 0 && len(h.PrecautionaryStatements) == 0
+}
+
+// statement 代码表中的一行
+type statement struct {
+	zh, en string
+}
+
+// statements 代码到标准文本
+var statements = loadStatements(statementsTSV)
+
+// loadStatements 解析 "代码\t中文\t英文" 格式的代码表，# 开头为注释
+func loadStatements(data string) map[string]statement {
+	table := make(map[string]statement)
+	for _, line := range strings.Split(data, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.Split(line, "\t")
+		if len(parts) != 3 {
+			continue
+		}
+		table[parts[0]] = statement{zh: parts[1], en: parts[2]}
+	}
+	return table
+}
+
+var (
+	// codePattern H/P 代码及其 + 组合，H360FD 等生殖毒性代码带字母后缀
+	codePattern = regexp.MustCompile(`\b[HhPp]\s?\d{3}[FDfdi]*(?:\s*\+\s*[HhPp]\s?\d{3}[FDfdi]*)*`)
+	// pictogramPattern 象形图编号，兼容 GHS5、ghs05.gif 等写法
+	pictogramPattern = regexp.MustCompile(`(?i)\bGHS\s?0?([1-9])`)
+)
+
+// Parse 从任意文本中提取象形图和 H/P 代码，按出现顺序去重；
+// 不认识的代码被忽略，警示词需另行用 ParseSignalWord 解析
+func Parse(text string) Hazard {
+	var h Hazard
+	seen := make(map[string]bool)
+	add := func(list *[]string, code string) {
+		if code == "" || seen[code] {
+			return
+		}
+		seen[code] = true
+		*list = append(*list, code)
+	}
+
+	for _, m := range pictogramPattern.FindAllStringSubmatch(text, -1) {
+		add(&h.Pictograms, "GHS0"+m[1])
+	}
+	for _, m := range codePattern.FindAllString(text, -1) {
+		code := normalizeCombination(m)
+		if strings.HasPrefix(code, "H") {
+			add(&h.HazardStatements, code)
+		} else {
+			add(&h.PrecautionaryStatements, code)
+		}
+	}
+	return h
+}
+
+// normalizeCombination 规范化组合代码，去掉空白并丢弃代码表中没有的部分
+func normalizeCombination(s string) string {
+	var codes []string
+	for _, part := range strings.Split(s, "+") {
+		if code := normalizeCode(part); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	return strings.Join(codes, "+")
+}
+
+// normalizeCode 规范化单个代码，带后缀的代码不在表中时退回到不带后缀的代码
+func normalizeCode(s string) string {
+	s = strings.ReplaceAll(strings.TrimSpace(s), " ", "")
+	if s == "" {
+		return ""
+	}
+	code := strings.ToUpper(s[:1]) + s[1:]
+	if _, ok := statements[code]; ok {
+		return code
+	}
+	if len(code) > 4 {
+		if _, ok := statements[code[:4]]; ok {
+			return code[:4]
+		}
+	}
+	return ""
+}
+
+// ParseSignalWord 识别中英文警示词，无法识别时返回空字符串
+func ParseSignalWord(s string) string {
+	s = strings.ToLower(s)
+	switch {
+	case strings.Contains(s, "危险"), strings.Contains(s, "danger"):
+		return Danger
+	case strings.Contains(s, "警告"), strings.Contains(s, "warning"):
+		return Warning
+	}
+	return ""
+}
+
+// Text 返回代码的标准文本，组合代码的各部分依次连接；lang 为 "en" 时返回英文，否则返回中文
+func Text(code, lang string) string {
+	var texts []string
+	for _, part := range strings.Split(code, "+") {
+		st, ok := statements[part]
+		if !ok {
+			continue
+		}
+		if lang == "en" {
+			texts = append(texts, st.en)
+		} else {
+			texts = append(texts, st.zh)
+		}
+	}
+	if lang == "en" {
+		return strings.Join(texts, " ")
+	}
+	return strings.Join(texts, "")
+}
+
+// Lines 每个代码一行，格式为 "代码 标准文本"
+func Lines(codes []string, lang string) string {
+	lines := make([]string, 0, len(codes))
+	for _, code := range codes {
+		lines = append(lines, code+" "+Text(code, lang))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Precautions 返回指定类别的防范说明，类别为代码的首位数字：
+// 1 一般、2 预防、3 响应、4 储存、5 处置
+func (h Hazard) Precautions(groups ...byte) []string {
+	var codes []string
+	for _, code := range h.PrecautionaryStatements {
+		for _, g := range groups {
+			if len(code) > 1 && code[1] == g {
+				codes = append(codes, code)
+				break
+			}
+		}
+	}
+	return codes
+}
+
+// Label 标签文本：象形图一行一个，最后一行为警示词
+func (h Hazard) Label(lang string) string {
+	lines := strings.Split(Lines(h.Pictograms, lang), "\n")
+	if len(h.Pictograms) == 0 {
+		lines = nil
+	}
+	if h.SignalWord != "" {
+		lines = append(lines, Text(h.SignalWord, lang))
+	}
+	return strings.Join(lines, "\n")
+}
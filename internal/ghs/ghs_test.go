@@ -0,0 +1,73 @@
+package ghs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Hazard
+	}{
+		{
+			name: "连字符分隔",
+			text: "GHS05 H290-H314 P280-P301 + P330 + P331-P305+P351+P338-P310",
+			want: Hazard{
+				Pictograms:              []string{"GHS05"},
+				HazardStatements:        []string{"H290", "H314"},
+				PrecautionaryStatements: []string{"P280", "P301+P330+P331", "P305+P351+P338", "P310"},
+			},
+		},
+		{
+			name: "小写和后缀",
+			text: "ghs2.gif ghs08.gif h225；h360FD；H361x；H999；H225",
+			want: Hazard{
+				Pictograms:       []string{"GHS02", "GHS08"},
+				HazardStatements: []string{"H225", "H360FD", "H361"},
+			},
+		},
+		{
+			name: "欧盟代码不是H代码",
+			text: "EUH014 EUH066",
+			want: Hazard{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		code, lang, want string
+	}{
+		{"H314", "zh", "造成严重皮肤灼伤和眼损伤"},
+		{"H314", "en", "Causes severe skin burns and eye damage"},
+		{"P301+P330+P331", "zh", "如误吞咽：漱口。不得诱导呕吐。"},
+		{"P301+P330+P331", "en", "IF SWALLOWED: Rinse mouth. Do NOT induce vomiting."},
+		{"Danger", "zh", "危险"},
+	}
+	for _, tt := range tests {
+		if got := Text(tt.code, tt.lang); got != tt.want {
+			t.Errorf("Text(%q, %q) = %q, want %q", tt.code, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestLabel(t *testing.T) {
+	h := Hazard{Pictograms: []string{"GHS02", "GHS07"}, SignalWord: ParseSignalWord("警告 Warning")}
+	want := "GHS02 火焰\nGHS07 感叹号\n警告"
+	if got := h.Label("zh"); got != want {
+		t.Errorf("Label = %q, want %q", got, want)
+	}
+	if got := (Hazard{SignalWord: Danger}).Label("en"); got != "Danger" {
+		t.Errorf("Label = %q", got)
+	}
+}
@@ -0,0 +1,309 @@
+package i18n
+
+// english 英文译文，键为源码中的中文原文
+var english = map[string]string{
+	// 命令行帮助
+	"用法: %s [%s] [参数]\n":       "Usage: %s [%s] [flags]\n",
+	"未知命令: %s\n":               "unknown command: %s\n",
+	"待处理的Excel文件":              "Excel workbook to process",
+	"只查询并输出差异，不修改Excel文件":      "only look up and print the differences, do not modify the workbook",
+	"预演模式的差异输出文件，\"-\" 表示标准输出": "diff output file for dry runs, \"-\" for standard output",
+	"将结果写入该副本，原文件保持不变；为空时写回原文件并自动备份":                      "write results to this copy and leave the original unchanged; when empty, write back to the original after a backup",
+	"将结果写入 <文件名>.enriched.xlsx":                           "write results to <name>.enriched.xlsx",
+	"为自动填充的单元格添加来源批注":                                     "add a source comment to auto-filled cells",
+	"为自动填充的单元格设置填充色，如 #FFF2CC":                            "fill color for auto-filled cells, e.g. #FFF2CC",
+	"在隐藏工作表 %s 中记录每个值的来源":                                 "record the source of every value in the hidden sheet %s",
+	"允许覆盖已有值的单元格":                                         "allow overwriting cells that already have a value",
+	"单元格已有值而跳过写入时的冲突报告":                                   "conflict report for writes skipped because the cell already has a value",
+	"化学式数据源地址":                                            "base URL of the formula source",
+	"密度数据源地址":                                             "base URL of the density source",
+	"搜索站点地址":                                              "base URL of the search site",
+	"结构式图片的保存目录，按内容哈希存放":                                  "directory for structure images, stored by content hash",
+	"没有图片时根据SMILES绘制结构图的格式 (png|svg)":                     "format for structures drawn from SMILES when no image is available (png|svg)",
+	"InChIKey 相同的重复记录报告":                                  "report of records sharing an InChIKey",
+	"GHS 危险性说明和防范说明的语言 (zh|en)":                           "language of GHS hazard and precautionary statements (zh|en)",
+	"相容性检查的分组列，同组视为同处储存":                                  "column grouping the compatibility check; rows in a group are stored together",
+	"同处储存的不相容试剂对报告":                                       "report of incompatible reagent pairs stored together",
+	"过期检查中视为即将过期的天数":                                      "number of days within which a reagent counts as expiring",
+	"过期检查的日期，格式 2006-01-02，默认当天":                          "date of the expiry check, formatted 2006-01-02, defaults to today",
+	"已过期、即将过期和无法识别的记录报告":                                  "report of expired, expiring and unparseable records",
+	"标签纸规格 (%s)":                                          "label sheet layout (%s)",
+	"打印标签的行号，如 2-20,25，默认所有行":                             "rows to print labels for, e.g. 2-20,25, defaults to all rows",
+	"标签输出文件，.pdf 或 .svg，SVG多页时按页编号":                       "label output file, .pdf or .svg; multi-page SVG output is numbered per page",
+	"本地SDS文件(PDF或文本)目录，文件名或第1部分中须含CAS号":                   "directory of local SDS files (PDF or text); the file name or section 1 must contain the CAS number",
+	"本地试剂库 (JSON Lines)，查询时优先使用，为空时不使用":                   "local reagent database (JSON Lines), queried first; empty to disable",
+	"导出来源 (workbook|db)":                                  "export source (workbook|db)",
+	"导出文件，按扩展名选择格式 (.jsonl|.csv|.sdf)":                    "export file, format chosen by extension (.jsonl|.csv|.sdf)",
+	"导入文件 (.jsonl|.csv|.sdf)，按CAS号更新或追加行":                 "import file (.jsonl|.csv|.sdf); rows are updated or appended by CAS number",
+	"查询接口的监听地址，默认只接受本机访问":                                 "listen address of the lookup API, local connections only by default",
+	"查询结果的缓存有效期，0 表示不过期":                                  "how long lookup results are cached, 0 means forever",
+	"网页界面上传文件和结果的保存目录，默认系统临时目录":                           "directory for web UI uploads and results, defaults to the system temp directory",
+	"不在终端中运行时输出进度日志的间隔":                                   "interval between progress log lines when not running in a terminal",
+	"日志级别 (debug|info|warn|error)，debug 级别会输出未找到字段时的页面内容": "log level (debug|info|warn|error); debug also dumps page content when a field is not found",
+	"日志格式 (text|json)":                                    "log format (text|json)",
+	"批量命令结束时保存监控指标 (Prometheus 文本格式) 的文件，\"-\" 表示标准错误，为空时不保存": "file to save metrics to (Prometheus text format) when a batch command finishes, \"-\" for standard error, empty to disable",
+	"watch 命令监视的目录，处理其中新增或修改过的 .xlsx 文件":                      "directory watched by the watch command; new or modified .xlsx files are processed",
+	"填充后的副本和报告的输出目录":                                          "output directory for enriched copies and reports",
+	"扫描目录的间隔": "interval between directory scans",
+	"文件修改后等待该时长再处理，避免读取正在复制的文件":    "wait this long after a file changes before processing it, so files still being copied are not read",
+	"只扫描一次后退出，用于定时任务":              "scan once and exit, for scheduled jobs",
+	"填充的列，逗号分隔":                    "columns to fill, comma separated",
+	"消息语言 (zh|en)，默认按 LANG 环境变量选择": "message language (zh|en), defaults to the LANG environment variable",
+
+	// 日志
+	"开始处理文件":            "processing file",
+	"开始解析Excel文件":       "parsing workbook",
+	"开始扫描数据行":           "scanning data rows",
+	"处理工作表":             "processing sheet",
+	"处理工作表出错":           "failed to process sheet",
+	"处理完成":              "processing finished",
+	"处理失败":              "processing failed",
+	"工作表为空":             "sheet is empty",
+	"工作表总行数(包含表头)":      "sheet rows (including header)",
+	"工作表不存在，使用第一个工作表":   "sheet not found, using the first sheet",
+	"工作表中没有该列，已跳过":      "column not in sheet, skipped",
+	"化学式列":              "formula column",
+	"识别化学式列":            "detected formula column",
+	"识别CAS号列":           "detected CAS column",
+	"扫描进度":              "scan progress",
+	"找到化学式为空的记录":        "found records with an empty formula",
+	"列为空的记录":            "records with an empty column",
+	"未找到列，所有记录视为空":      "column not found, treating every record as empty",
+	"统计结果":              "summary",
+	"空化学式所在行号":          "rows with an empty formula",
+	"空记录分布":             "empty record distribution",
+	"详细统计报告":            "detailed report",
+	"文件不存在":             "file does not exist",
+	"读取CAS号失败":          "failed to read CAS numbers",
+	"结果已保存":             "results saved",
+	"保存文件失败":            "failed to save file",
+	"化学信息":              "chemical info",
+	"找到分子式":             "found formula",
+	"未找到分子式":            "formula not found",
+	"找到密度":              "found density",
+	"未找到密度":             "density not found",
+	"表格行":               "table row",
+	"表格内容":              "table content",
+	"解析HTML失败":          "failed to parse HTML",
+	"创建请求失败":            "failed to create request",
+	"请求失败":              "request failed",
+	"状态码错误":             "unexpected status code",
+	"读取响应失败":            "failed to read response",
+	"写入错误日志失败":          "failed to write error log",
+	"已记录错误URL":          "recorded failed URL",
+	"查询失败":              "lookup failed",
+	"写入失败":              "write failed",
+	"创建写回目标失败":          "failed to create result writer",
+	"预演模式: 不会修改Excel文件": "dry run: the workbook will not be modified",
+	"结果将写入副本":           "results will be written to a copy",
+	"已备份原文件":            "backed up the original file",
+	"单元格已有值，未覆盖 (使用 --force 强制覆盖)": "cells already have values and were not overwritten (use --force to overwrite)",
+	"保存冲突报告失败":                     "failed to save conflict report",
+	"冲突报告已保存":                      "conflict report saved",
+	"使用本地试剂库中的值":                   "using value from the local reagent database",
+	"本地试剂库不可用":                     "local reagent database unavailable",
+	"打开本地试剂库失败":                    "failed to open local reagent database",
+	"保存本地试剂库失败":                    "failed to save local reagent database",
+	"未指定本地试剂库 (-db)":               "no local reagent database given (-db)",
+	"导入本地试剂库完成":                    "local reagent database import finished",
+	"未知的导出来源 (workbook|db)":        "unknown export source (workbook|db)",
+	"导出失败":                         "export failed",
+	"导出完成":                         "export finished",
+	"未指定导入文件 (-import-in)":         "no import file given (-import-in)",
+	"读取导入文件失败":                     "failed to read import file",
+	"导入完成":                         "import finished",
+	"写回目标不支持追加列，已跳过":               "result writer cannot append columns, skipped",
+	"追加列失败":                        "failed to append columns",
+	"检查日期格式错误":                     "invalid check date",
+	"过期检查":                         "expiry check",
+	"过期记录":                         "expiry records",
+	"保存过期报告失败":                     "failed to save expiry report",
+	"过期报告已保存":                      "expiry report saved",
+	"没有 GHS 分类信息":                  "no GHS classification",
+	"写入安全信息":                       "wrote safety information",
+	"写入标识符":                        "wrote identifiers",
+	"追加标识符列失败":                     "failed to append identifier columns",
+	"检查重复失败":                       "duplicate check failed",
+	"没有发现 InChIKey 相同的记录":          "no records share an InChIKey",
+	"InChIKey 重复":                  "duplicate InChIKey",
+	"保存重复报告失败":                     "failed to save duplicate report",
+	"重复记录已保存":                      "duplicate report saved",
+	"写回目标不支持嵌入图片":                  "result writer cannot embed pictures",
+	"获取结构式图片失败":                    "failed to get structure image",
+	"已嵌入结构式图片":                     "embedded structure image",
+	"下载图片失败，改为根据SMILES绘制":          "image download failed, drawing from SMILES instead",
+	"相容性检查":                        "compatibility check",
+	"储存位置未填写的危险试剂不参与检查":            "hazardous reagents without a storage location are not checked",
+	"没有发现同处储存的不相容试剂":               "no incompatible reagents stored together",
+	"不相容试剂":                        "incompatible reagents",
+	"保存不相容报告失败":                    "failed to save incompatibility report",
+	"不相容试剂报告已保存":                   "incompatibility report saved",
+	"读取SDS失败":                      "failed to read SDS",
+	"写入SDS内容":                      "wrote SDS content",
+	"读取SDS文件失败":                    "failed to read SDS file",
+	"SDS文件中没有找到CAS号，已跳过":           "no CAS number found in SDS file, skipped",
+	"读取SDS目录失败":                    "failed to read SDS directory",
+	"未知的标签纸规格":                     "unknown label layout",
+	"行号格式错误":                       "invalid row list",
+	"没有需要打印的标签":                    "no labels to print",
+	"保存标签失败":                       "failed to save labels",
+	"标签已保存":                        "labels saved",
+	"不支持的标签格式，请使用 .pdf 或 .svg":     "unsupported label format, use .pdf or .svg",
+	"追加链接检查列失败":                    "failed to append link check columns",
+	"找到新链接":                        "found a new link",
+	"已有链接失效，新链接未写入":                "existing link is broken, new link not written",
+	"没有可用的SDS":                     "no working SDS",
+	"SDS 检查完成":                     "SDS check finished",
+	"SDS链接不可用":                     "SDS link unavailable",
+	"查询接口和网页界面已启动":                 "lookup API and web UI started",
+	"服务停止":                         "server stopped",
+	"数据源":                          "provider",
+	"保存上传文件失败":                     "failed to save upload",
+	"任务失败":                         "job failed",
+	"任务完成":                         "job finished",
+	"写入响应失败":                       "failed to write response",
+	"监控指标已保存":                      "metrics saved",
+	"保存监控指标失败":                     "failed to save metrics",
+	"开始监视目录":                       "watching directory",
+	"创建输出目录失败":                     "failed to create output directory",
+	"没有可以填充的列":                     "no fillable columns",
+	"不支持填充该列，已跳过":                  "column cannot be filled, skipped",
+	"读取监视目录失败":                     "failed to read watched directory",
+	"处理工作表失败":                      "failed to process workbook",
+	"工作表处理完成":                      "workbook processed",
+	"进度":                           "progress",
+	"完成":                           "done",
+
+	// 错误
+	"未找到":                                "not found",
+	"未找到CAS号 %s 的信息: %w":                 "no information for CAS %s: %w",
+	"CAS %s: 没有可用的数据源":                   "CAS %s: no provider available",
+	"未找到化学信息":                            "no chemical information found",
+	"CAS号格式或校验位错误: %s":                   "malformed CAS number or bad check digit: %s",
+	"单次最多查询 %d 个CAS号，收到 %d 个":            "at most %d CAS numbers per batch, got %d",
+	"请求体不是有效的JSON: %v":                   "request body is not valid JSON: %v",
+	"请求体应为 {\"cas\": [...]} 或CAS号数组: %v": "request body must be {\"cas\": [...]} or an array of CAS numbers: %v",
+	"HTTP请求失败: %v":                       "HTTP request failed: %v",
+	"HTTP状态码错误: %d %s":                   "unexpected HTTP status: %d %s",
+	"读取响应失败: %v":                         "failed to read response: %v",
+	"解析HTML失败: %v":                       "failed to parse HTML: %v",
+	"Excel 文件中没有工作表":                     "the workbook has no sheets",
+	"打开文件失败: %v":                         "failed to open file: %v",
+	"读取行数据失败: %v":                        "failed to read rows: %v",
+	"工作表 %s 为空":                          "sheet %s is empty",
+	"未找到化学式列":                            "formula column not found",
+	"未找到CAS号列":                           "CAS column not found",
+	"未找到列名: %s":                          "column not found: %s",
+	"无法获取表头或工作表为空":                       "cannot read the header or the sheet is empty",
+	"第 %d 行: %v":                         "row %d: %v",
+	"%s 第 %d 行: %v":                      "%s row %d: %v",
+	"写回目标不支持追加列":                         "result writer cannot append columns",
+	"创建写回目标失败: %v":                       "failed to create result writer: %v",
+	"创建输出文件失败: %v":                       "failed to create output file: %v",
+	"创建差异文件失败: %v":                       "failed to create diff file: %v",
+	"单元格 %s 第 %d 行已有值 %q，跳过写入 %q":        "cell %s row %d already has %q, skipped writing %q",
+	"写入差异失败: %v":                         "failed to write diff: %v",
+	"备份文件失败: %v":                         "failed to back up file: %v",
+	"创建目录失败: %v":                         "failed to create directory: %v",
+	"创建临时文件失败: %v":                       "failed to create temp file: %v",
+	"添加批注失败: %v":                         "failed to add comment: %v",
+	"设置填充色失败: %v":                        "failed to set fill color: %v",
+	"不支持填充列: %s":                         "cannot fill column: %s",
+	"记录来源失败: %v":                         "failed to record source: %v",
+	"嵌入图片失败: %v":                         "failed to embed picture: %v",
+	"创建图片目录失败: %v":                       "failed to create image directory: %v",
+	"下载图片失败: %v":                         "failed to download image: %v",
+	"读取图片失败: %v":                         "failed to read image: %v",
+	"保存图片失败: %v":                         "failed to save image: %v",
+	"不支持的图片格式: %s":                       "unsupported image format: %s",
+	"没有结构式图片和SMILES":                     "no structure image and no SMILES",
+	"绘制结构图失败: %v":                        "failed to draw structure: %v",
+	"保存填充报告失败: %v":                       "failed to save enrichment report: %v",
+	"写入监控指标失败: %v":                       "failed to write metrics: %v",
+	"读取本地试剂库失败: %v":                      "failed to read local reagent database: %v",
+	"打开本地试剂库失败: %v":                      "failed to open local reagent database: %v",
+	"写入本地试剂库失败: %v":                      "failed to write local reagent database: %v",
+	"写入记录 %s 失败: %v":                     "failed to write record %s: %v",
+	"不支持的文件格式: %s (支持 .jsonl、.csv、.sdf)": "unsupported file format: %s (supported: .jsonl, .csv, .sdf)",
+	"无效的日志级别 %q: %v":                     "invalid log level %q: %v",
+	"无效的日志格式 %q，应为 text 或 json":          "invalid log format %q, expected text or json",
+	"无法打开错误日志文件: %v":                     "cannot open error log file: %v",
+	"写入错误日志失败: %v":                       "failed to write error log: %v",
+	"刷新错误日志失败: %v":                       "failed to flush error log: %v",
+	"读取上传文件失败: %v":                       "failed to read upload: %v",
+	"缺少上传文件: %v":                         "missing upload: %v",
+	"只支持 .xlsx 文件: %s":                   "only .xlsx files are supported: %s",
+	"请至少选择一列":                            "select at least one column",
+	"排队的任务过多，请稍后再试":                      "too many queued jobs, try again later",
+	"任务不存在或已过期":                          "job not found or expired",
+	"任务尚未完成: %s":                         "job not finished yet: %s",
+	"化学式为空":                              "formula is empty",
+	"化学式 %s: %v":                         "formula %s: %v",
+	"化学式 %s: 多余的 %q":                     "formula %s: unexpected %q",
+	"未知元素 %s":                            "unknown element %s",
+	"括号不匹配":                              "unbalanced parentheses",
+	"SMILES为空":                           "SMILES is empty",
+	"SMILES %q 中没有原子":                    "SMILES %q has no atoms",
+	"解析SMILES %q 失败: 位置 %d: %v":          "failed to parse SMILES %q: position %d: %v",
+	"无法识别的字符 %q":                         "unrecognized character %q",
+	"方括号不匹配":                             "unbalanced brackets",
+	"方括号中缺少元素":                           "missing element in brackets",
+	"方括号中的元素无效: %q":                      "invalid element in brackets: %q",
+	"方括号原子 %q 无法解析":                      "cannot parse bracket atom %q",
+	"分支前没有原子":                            "branch without a preceding atom",
+	"环闭合前没有原子":                           "ring closure without a preceding atom",
+	"环编号不完整":                             "incomplete ring number",
+	"环编号无效":                              "invalid ring number",
+	"存在未闭合的环":                            "unclosed ring",
+	"不是PDF文件":                            "not a PDF file",
+	"不支持加密的PDF":                          "encrypted PDFs are not supported",
+	"PDF流解压后超过 %d MB":                    "PDF stream expands to more than %d MB",
+	"PDF流解压失败: %v":                       "failed to decompress PDF stream: %v",
+	"PDF中没有可提取的文字，可能是扫描件":                "no extractable text in the PDF, it may be a scan",
+	"%s: 无法识别的编码: %v":                    "%s: unrecognized encoding: %v",
+	"%s: 没有识别出SDS的部分标题":                  "%s: no SDS section headings recognized",
+
+	// 报告
+	"化学式为空的记录统计":   "Records with an empty formula",
+	"统计时间: %s\n":   "Generated: %s\n",
+	"源文件: %s\n":    "Source file: %s\n",
+	"总记录数: %d\n\n": "Total records: %d\n\n",
+	"空化学式所在行号:":    "Rows with an empty formula:",
+	"统计完成!":        "Done!",
+	"%d/%d (%.1f%%) 成功 %d 失败 %d | %.1f 行/秒 | 已用 %s 剩余 %s": "%d/%d (%.1f%%) ok %d failed %d | %.1f rows/s | elapsed %s remaining %s",
+	"未知": "unknown",
+
+	// 日志字段中的取值
+	"已过期":  "expired",
+	"即将过期": "expiring",
+	"无法识别": "unparseable",
+	"有效":   "valid",
+
+	// 写入工作表和标签的文字
+	"自动填充\n来源: %s\n数据源: %s\n时间: %s\n版本: %s": "Auto-filled\nSource: %s\nProvider: %s\nTime: %s\nVersion: %s",
+	"\n原值: %s": "\nPrevious value: %s",
+	"请求失败: %v": "request failed: %v",
+	"过期 %s":    "Exp %s",
+
+	// 网页界面
+	"试剂信息填充":         "Reagent info filler",
+	"选择工作表 (.xlsx)":  "Choose a workbook (.xlsx)",
+	"要填充的列":          "Columns to fill",
+	"上传并开始":          "Upload and start",
+	"下载填充后的工作表":      "Download the filled workbook",
+	"已写入 %d 个单元格":    "%d cells written",
+	"已有值未覆盖 %d 个单元格": "%d cells kept their existing value",
+	"行":              "Row",
+	"列":              "Column",
+	"新值":             "New value",
+	"来源":             "Source",
+	"当前值":            "Current value",
+	"查询到的值":          "Looked-up value",
+	"处理另一个文件":        "Process another file",
+	"排队中":            "queued",
+	"查询中":            "running",
+	"已完成":            "done",
+	"失败":             "failed",
+	"%s: %d / %d 行，失败 %d 行，写入 %d 个单元格": "%s: %d / %d rows, %d failed, %d cells written",
+	"未注明": "unspecified",
+}
@@ -0,0 +1,121 @@
+// Package i18n 面向用户的日志、报告、错误和命令行帮助的中英文消息目录。
+//
+// 消息以中文原文为键，简体中文直接使用原文，英文译文见 english。
+// 格式化仍使用 fmt 的格式动词，译文须保持相同的动词。
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"golang.org/x/text/language"
+)
+
+// Supported 支持的语言，第一个为默认语言
+var Supported = []language.Tag{language.SimplifiedChinese, language.English}
+
+var matcher = language.NewMatcher(Supported)
+
+// catalogs 各语言的译文，简体中文使用原文不需要目录
+var catalogs = map[language.Tag]map[string]string{
+	language.English: english,
+}
+
+var current atomic.Pointer[language.Tag]
+
+// Set 设置当前语言
+func Set(tag language.Tag) {
+	current.Store(&tag)
+}
+
+// Current 返回当前语言，未设置时为简体中文
+func Current() language.Tag {
+	if tag := current.Load(); tag != nil {
+		return *tag
+	}
+	return Supported[0]
+}
+
+// Parse 将 "en"、"zh-CN" 或 LANG 形式的 "en_US.UTF-8" 匹配到支持的语言，
+// 无法识别时返回默认语言
+func Parse(s string) language.Tag {
+	s, _, _ = strings.Cut(s, ".")
+	s, _, _ = strings.Cut(s, "@")
+	s = strings.ReplaceAll(s, "_", "-")
+	if s == "" || s == "C" || s == "POSIX" {
+		return Supported[0]
+	}
+	tag, err := language.Parse(s)
+	if err != nil {
+		return Supported[0]
+	}
+	_, index, confidence := matcher.Match(tag)
+	if confidence == language.No {
+		return Supported[0]
+	}
+	return Supported[index]
+}
+
+// FromEnv 按 LC_ALL、LC_MESSAGES、LANG 的顺序确定语言
+func FromEnv() language.Tag {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			return Parse(v)
+		}
+	}
+	return Supported[0]
+}
+
+// T 返回 key 在当前语言中的文本，没有译文时返回 key
+func T(key string) string {
+	if s, ok := catalogs[Current()][key]; ok {
+		return s
+	}
+	return key
+}
+
+// Sprintf 按当前语言的格式串格式化
+func Sprintf(key string, args ...any) string {
+	return fmt.Sprintf(T(key), args...)
+}
+
+// Errorf 按当前语言的格式串创建错误，支持 %w
+func Errorf(key string, args ...any) error {
+	return fmt.Errorf(T(key), args...)
+}
+
+// Handler 将日志消息和目录中已有的字符串字段值翻译为当前语言
+func Handler(h slog.Handler) slog.Handler {
+	return &handler{h}
+}
+
+type handler struct {
+	slog.Handler
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	if Current() == Supported[0] {
+		return h.Handler.Handle(ctx, r)
+	}
+	tr := slog.NewRecord(r.Time, r.Level, T(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Value.Kind() == slog.KindString {
+			a.Value = slog.StringValue(T(a.Value.String()))
+		}
+		tr.AddAttrs(a)
+		return true
+	})
+	return h.Handler.Handle(ctx, tr)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{h.Handler.WithGroup(name)}
+}
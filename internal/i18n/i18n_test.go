@@ -0,0 +1,174 @@
+package i18n
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+
+	"golang.org/x/text/language"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want language.Tag
+	}{
+		{"", language.SimplifiedChinese},
+		{"C", language.SimplifiedChinese},
+		{"POSIX", language.SimplifiedChinese},
+		{"en", language.English},
+		{"en_US.UTF-8", language.English},
+		{"en_GB.UTF-8@euro", language.English},
+		{"zh_CN.UTF-8", language.SimplifiedChinese},
+		{"zh-CN", language.SimplifiedChinese},
+		{"de_DE.UTF-8", language.SimplifiedChinese},
+		{"not a tag", language.SimplifiedChinese},
+	}
+	for _, tt := range tests {
+		if got := Parse(tt.in); got != tt.want {
+			t.Errorf("Parse(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "en_US.UTF-8")
+	if got := FromEnv(); got != language.English {
+		t.Errorf("LANG=en_US.UTF-8: got %v", got)
+	}
+	t.Setenv("LC_ALL", "zh_CN.UTF-8")
+	if got := FromEnv(); got != language.SimplifiedChinese {
+		t.Errorf("LC_ALL 应优先于 LANG: got %v", got)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	defer Set(Current())
+
+	Set(language.SimplifiedChinese)
+	if got := Sprintf("导入本地试剂库完成"); got != "导入本地试剂库完成" {
+		t.Errorf("zh: got %q", got)
+	}
+
+	Set(language.English)
+	if got := Errorf("打开文件失败: %v", "x").Error(); got != "failed to open file: x" {
+		t.Errorf("en: got %q", got)
+	}
+	if got := T("没有目录中的消息"); got != "没有目录中的消息" {
+		t.Errorf("未翻译的消息应原样返回: got %q", got)
+	}
+
+	var buf bytes.Buffer
+	slog.New(Handler(slog.NewTextHandler(&buf, nil))).With("cas", "64-17-5").Warn("查询失败", "status", "已过期")
+	if out := buf.String(); !strings.Contains(out, `msg="lookup failed"`) ||
+		!strings.Contains(out, "cas=64-17-5") || !strings.Contains(out, "status=expired") {
+		t.Errorf("日志未翻译: %s", out)
+	}
+}
+
+var verb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+// TestCatalog 检查源码中的每条中文消息都有英文译文，且译文的格式动词与原文一致
+func TestCatalog(t *testing.T) {
+	for _, key := range messageKeys(t) {
+		en, ok := english[key]
+		if !ok {
+			t.Errorf("缺少英文译文: %q", key)
+			continue
+		}
+		if !slices.Equal(verbs(key), verbs(en)) {
+			t.Errorf("格式动词不一致: %q => %q", key, en)
+		}
+	}
+	for key, en := range english {
+		if !slices.Equal(verbs(key), verbs(en)) {
+			t.Errorf("格式动词不一致: %q => %q", key, en)
+		}
+	}
+}
+
+func verbs(s string) []string {
+	v := verb.FindAllString(s, -1)
+	slices.Sort(v)
+	return v
+}
+
+// messageKeys 收集模块中作为消息传给 i18n、slog 和 fatal 的中文字符串字面量
+func messageKeys(t *testing.T) []string {
+	t.Helper()
+	logMethods := map[string]bool{"Debug": true, "Info": true, "Warn": true, "Error": true}
+	i18nFuncs := map[string]bool{"T": true, "Sprintf": true, "Errorf": true}
+
+	var keys []string
+	root := filepath.Join("..", "..")
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case "testdata", "standin", "i18n", ".git":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			switch fn := call.Fun.(type) {
+			case *ast.Ident:
+				if fn.Name != "fatal" {
+					return true
+				}
+			case *ast.SelectorExpr:
+				x, ok := fn.X.(*ast.Ident)
+				if !ok {
+					return true
+				}
+				isLog := logMethods[fn.Sel.Name] && (x.Name == "slog" || strings.HasSuffix(strings.ToLower(x.Name), "logger"))
+				isI18n := x.Name == "i18n" && i18nFuncs[fn.Sel.Name]
+				if !isLog && !isI18n {
+					return true
+				}
+			default:
+				return true
+			}
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			s, err := strconv.Unquote(lit.Value)
+			if err == nil && strings.ContainsFunc(s, func(r rune) bool { return unicode.Is(unicode.Han, r) }) {
+				keys = append(keys, s)
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
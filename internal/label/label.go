@@ -0,0 +1,191 @@
+// Package label 将试剂信息排版为试剂瓶标签页，输出SVG或PDF
+package label
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"cas.mod/internal/ghs"
+	"cas.mod/internal/i18n"
+)
+
+// Label 一张试剂瓶标签的内容
+type Label struct {
+	Name       string
+	CAS        string
+	Formula    string
+	MW         string   // 分子量，已格式化
+	Pictograms []string // GHS 象形图代码，如 GHS05
+	SignalWord string   // 警示词，ghs.Danger 或 ghs.Warning
+	Expiry     string   // 过期时间，已格式化
+}
+
+// Layout 标签纸规格，长度单位为毫米
+type Layout struct {
+	Name          string
+	PageWidth     float64
+	PageHeight    float64
+	Columns, Rows int
+	LabelWidth    float64
+	LabelHeight   float64
+	Left, Top     float64 // 第一张标签左上角到纸边的距离
+	GapX, GapY    float64 // 相邻标签的间距
+}
+
+// Layouts 常用的标签纸规格，键为 -layout 参数的取值
+var Layouts = map[string]Layout{
+	"l7163": {Name: "Avery L7163 (A4, 2×7, 99.1×38.1mm)", PageWidth: 210, PageHeight: 297, Columns: 2, Rows: 7,
+		LabelWidth: 99.1, LabelHeight: 38.1, Left: 4.65, Top: 15.15, GapX: 2.5},
+	"l7160": {Name: "Avery L7160 (A4, 3×7, 63.5×38.1mm)", PageWidth: 210, PageHeight: 297, Columns: 3, Rows: 7,
+		LabelWidth: 63.5, LabelHeight: 38.1, Left: 7.2, Top: 15.15, GapX: 2.5},
+	"a4-24": {Name: "A4 24格 (3×8, 70×37mm)", PageWidth: 210, PageHeight: 297, Columns: 3, Rows: 8,
+		LabelWidth: 70, LabelHeight: 37, Left: 0, Top: 0.5},
+	"5163": {Name: "Avery 5163 (Letter, 2×5, 4×2in)", PageWidth: 215.9, PageHeight: 279.4, Columns: 2, Rows: 5,
+		LabelWidth: 101.6, LabelHeight: 50.8, Left: 3.97, Top: 12.7, GapX: 4.76},
+	"5160": {Name: "Avery 5160 (Letter, 3×10, 2.625×1in)", PageWidth: 215.9, PageHeight: 279.4, Columns: 3, Rows: 10,
+		LabelWidth: 66.675, LabelHeight: 25.4, Left: 4.76, Top: 12.7, GapX: 3.175},
+}
+
+// LayoutNames 返回所有规格名，按字母排序
+func LayoutNames() []string {
+	names := make([]string, 0, len(Layouts))
+	for name := range Layouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PerPage 每页的标签数
+func (l Layout) PerPage() int {
+	return l.Columns * l.Rows
+}
+
+// position 第 i 张标签左上角的坐标，按行排列
+func (l Layout) position(i int) (x, y float64) {
+	col, row := i%l.Columns, i/l.Columns
+	return l.Left + float64(col)*(l.LabelWidth+l.GapX), l.Top + float64(row)*(l.LabelHeight+l.GapY)
+}
+
+// paginate 按每页标签数分页
+func paginate(labels []Label, layout Layout) [][]Label {
+	var pages [][]Label
+	perPage := layout.PerPage()
+	for start := 0; start < len(labels); start += perPage {
+		end := start + perPage
+		if end > len(labels) {
+			end = len(labels)
+		}
+		pages = append(pages, labels[start:end])
+	}
+	return pages
+}
+
+// canvas SVG和PDF共用的绘图接口，坐标单位为毫米，原点在页面左上角
+type canvas interface {
+	// rect 标签边框，作为裁切参考线
+	rect(x, y, w, h float64)
+	// pictogram 在左上角为 (x, y)、边长为 size 的方框中绘制象形图，title 为说明文字
+	pictogram(x, y, size float64, shapes []shape, title string)
+	// text 以 (x, y) 为基线起点绘制文字，center 为 true 时 x 为中心
+	text(x, y, size float64, s string, bold, center bool)
+}
+
+// padding 标签内边距
+const padding = 2.0
+
+// drawLabel 在 (x, y) 处绘制一张标签：左侧为名称、CAS号、化学式、分子量、过期时间和警示词，
+// 右侧为象形图
+func drawLabel(c canvas, l Label, x, y, w, h float64) {
+	c.rect(x, y, w, h)
+
+	// 象形图最多两行
+	n := len(l.Pictograms)
+	picWidth := 0.0
+	if n > 0 {
+		rows := 1
+		if n > 2 {
+			rows = 2
+		}
+		cols := (n + rows - 1) / rows
+		s := math.Min((h-2*padding)/float64(rows), w*0.45/float64(cols))
+		picWidth = float64(cols) * s
+		left := x + w - padding - picWidth
+		top := y + (h-float64(rows)*s)/2
+		for i, code := range l.Pictograms {
+			cx := left + (float64(i%cols)+0.5)*s
+			cy := top + (float64(i/cols)+0.5)*s
+			size := s * 0.95
+			shapes, ok := pictogramShapes(code)
+			c.pictogram(cx-size/2, cy-size/2, size, shapes, code+" "+ghs.Text(code, "zh"))
+			// 没有图样的代码只画边框并写出代码
+			if !ok {
+				c.text(cx, cy, s*0.14, code, false, true)
+			}
+		}
+	}
+
+	textWidth := w - 2*padding - picWidth
+	if picWidth > 0 {
+		textWidth -= padding
+	}
+	base := math.Min(h/9, 3.5)
+	lineHeight := base * 1.35
+
+	tx, ty := x+padding, y+padding+base*1.4
+	c.text(tx, ty, base*1.4, fit(l.Name, textWidth, base*1.4), true, false)
+	ty += base * 0.4
+
+	lines := []string{}
+	if l.CAS != "" {
+		lines = append(lines, "CAS "+l.CAS)
+	}
+	formula := l.Formula
+	if l.MW != "" {
+		if formula != "" {
+			formula += "  "
+		}
+		formula += "MW " + l.MW
+	}
+	if formula != "" {
+		lines = append(lines, formula)
+	}
+	if l.Expiry != "" {
+		lines = append(lines, i18n.Sprintf("过期 %s", l.Expiry))
+	}
+	for _, line := range lines {
+		ty += lineHeight
+		c.text(tx, ty, base, fit(line, textWidth, base), false, false)
+	}
+
+	if l.SignalWord != "" {
+		c.text(tx, y+h-padding, base*1.3, ghs.Text(l.SignalWord, "zh")+" "+ghs.Text(l.SignalWord, "en"), true, false)
+	}
+}
+
+// textWidth 估计文字宽度：ASCII字符半角，其余全角
+func textWidth(s string, size float64) float64 {
+	width := 0.0
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			width += 0.5
+		} else {
+			width += 1
+		}
+	}
+	return width * size
+}
+
+// fit 截断超出宽度的文字，末尾加省略号
+func fit(s string, width, size float64) string {
+	if textWidth(s, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(string(runes)+"…", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimSpace(string(runes)) + "…"
+}
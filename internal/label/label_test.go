@@ -0,0 +1,107 @@
+package label
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"cas.mod/internal/ghs"
+	"cas.mod/internal/i18n"
+	"golang.org/x/text/language"
+)
+
+func sampleLabels(n int) []Label {
+	labels := make([]Label, n)
+	for i := range labels {
+		labels[i] = Label{
+			Name:       fmt.Sprintf("硫酸 %d", i+1),
+			CAS:        "7664-93-9",
+			Formula:    "H2SO4",
+			MW:         "98.07",
+			Pictograms: []string{"GHS05"},
+			SignalWord: ghs.Danger,
+			Expiry:     "2026-09-01",
+		}
+	}
+	return labels
+}
+
+func TestSVG(t *testing.T) {
+	layout := Layouts["l7163"]
+	pages := SVG(sampleLabels(15), layout)
+	if len(pages) != 2 {
+		t.Fatalf("页数 = %d, want 2", len(pages))
+	}
+	if n := bytes.Count(pages[0], []byte(`class="pictogram"`)); n != 14 {
+		t.Errorf("第一页象形图 = %d, want 14", n)
+	}
+	for _, want := range []string{"硫酸 15", "CAS 7664-93-9", "H2SO4  MW 98.07", "过期 2026-09-01", "危险 Danger", "腐蚀"} {
+		if !bytes.Contains(pages[1], []byte(want)) {
+			t.Errorf("第二页缺少 %q", want)
+		}
+	}
+
+	defer i18n.Set(i18n.Current())
+	i18n.Set(language.English)
+	if page := SVG(sampleLabels(1), layout)[0]; !bytes.Contains(page, []byte("Exp 2026-09-01")) {
+		t.Error("英文标签缺少 \"Exp 2026-09-01\"")
+	}
+}
+
+func TestPictograms(t *testing.T) {
+	for i := 1; i <= 9; i++ {
+		code := fmt.Sprintf("GHS%02d", i)
+		if shapes, ok := pictogramShapes(code); !ok || len(shapes) <= len(border) {
+			t.Errorf("%s 缺少图形", code)
+		}
+	}
+	if shapes, ok := pictogramShapes("GHS10"); ok || len(shapes) != len(border) {
+		t.Errorf("未知代码应只有边框")
+	}
+}
+
+func TestPDF(t *testing.T) {
+	data := PDF(sampleLabels(15), Layouts["l7163"])
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4")) || !bytes.Contains(data, []byte("/Count 2")) {
+		t.Fatalf("PDF 文件头或页数不正确")
+	}
+
+	// 交叉引用表中的偏移量须指向对应的对象
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	if m == nil {
+		t.Fatal("缺少 startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1)
+	if len(entries) != 9 {
+		t.Fatalf("对象数 = %d, want 9", len(entries))
+	}
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		if want := fmt.Sprintf("%d 0 obj", i+1); !bytes.HasPrefix(data[off:], []byte(want)) {
+			t.Errorf("对象 %d 的偏移量 %d 不正确", i+1, off)
+		}
+	}
+
+	// 象形图以矢量路径绘制
+	if !bytes.Contains(data, []byte(" cm 1 J 1 j")) {
+		t.Error("缺少象形图图形")
+	}
+
+	// "硫酸" 按 UCS2 编码
+	if !bytes.Contains(data, []byte("<786B9178")) {
+		t.Error("名称未按 UCS2 编码")
+	}
+}
+
+func TestFit(t *testing.T) {
+	if got := fit("短名称", 20, 3); got != "短名称" {
+		t.Errorf("fit = %q", got)
+	}
+	got := fit("二水合氯化亚锡（II）二水合氯化亚锡", 20, 3)
+	if textWidth(got, 3) > 20 || got[len(got)-len("…"):] != "…" {
+		t.Errorf("fit = %q 宽度 %.1f", got, textWidth(got, 3))
+	}
+}
@@ -0,0 +1,159 @@
+package label
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf16"
+)
+
+// ptPerMM PDF 的长度单位为 1/72 英寸
+const ptPerMM = 72 / 25.4
+
+// pdfCanvas 将绘图操作写为PDF内容流。中文使用 Adobe 亚洲字体包中的 STSong-Light，
+// 文件中不嵌入字体：Acrobat/Reader 需安装简体中文字体包，其他阅读器需能替换 Adobe-GB1 字体，
+// 否则文字无法显示；象形图为矢量图形，不依赖字体
+type pdfCanvas struct {
+	buf        *bytes.Buffer
+	pageHeight float64 // 毫米，用于翻转y轴
+}
+
+func (c pdfCanvas) pt(x, y float64) (float64, float64) {
+	return x * ptPerMM, (c.pageHeight - y) * ptPerMM
+}
+
+func (c pdfCanvas) rect(x, y, w, h float64) {
+	px, py := c.pt(x, y+h)
+	fmt.Fprintf(c.buf, "0.8 G 0.3 w %.2f %.2f %.2f %.2f re S\n", px, py, w*ptPerMM, h*ptPerMM)
+}
+
+// pdfColors 象形图颜色对应的RGB值
+var pdfColors = map[string]string{colorRed: "1 0 0", colorBlack: "0 0 0", colorWhite: "1 1 1"}
+
+// circleK 用四段三次贝塞尔曲线近似圆时控制点的比例
+const circleK = 0.5523
+
+func (c pdfCanvas) pictogram(x, y, size float64, shapes []shape, _ string) {
+	// 变换到象形图坐标系，y 轴向下
+	scale := size / pictogramBox * ptPerMM
+	px, py := c.pt(x, y)
+	fmt.Fprintf(c.buf, "q %.4f 0 0 %.4f %.2f %.2f cm 1 J 1 j\n", scale, -scale, px, py)
+	for _, sh := range shapes {
+		rgb := pdfColors[sh.color]
+		if sh.stroke > 0 {
+			fmt.Fprintf(c.buf, "%s RG %g w ", rgb, sh.stroke)
+		} else {
+			fmt.Fprintf(c.buf, "%s rg ", rgb)
+		}
+
+		if sh.radius > 0 {
+			cx, cy, r := sh.points[0].x, sh.points[0].y, sh.radius
+			k := r * circleK
+			fmt.Fprintf(c.buf, "%g %g m %g %g %g %g %g %g c %g %g %g %g %g %g c %g %g %g %g %g %g c %g %g %g %g %g %g c ",
+				cx+r, cy,
+				cx+r, cy+k, cx+k, cy+r, cx, cy+r,
+				cx-k, cy+r, cx-r, cy+k, cx-r, cy,
+				cx-r, cy-k, cx-k, cy-r, cx, cy-r,
+				cx+k, cy-r, cx+r, cy-k, cx+r, cy)
+		} else {
+			for i, p := range sh.points {
+				op := "l"
+				if i == 0 {
+					op = "m"
+				}
+				fmt.Fprintf(c.buf, "%g %g %s ", p.x, p.y, op)
+			}
+		}
+
+		switch {
+		case sh.stroke > 0 && (sh.closed || sh.radius > 0):
+			c.buf.WriteString("s\n")
+		case sh.stroke > 0:
+			c.buf.WriteString("S\n")
+		default:
+			c.buf.WriteString("f\n")
+		}
+	}
+	c.buf.WriteString("Q\n")
+}
+
+func (c pdfCanvas) text(x, y, size float64, s string, bold, center bool) {
+	if center {
+		x -= textWidth(s, size) / 2
+	}
+	px, py := c.pt(x, y)
+	mode := 0
+	if bold {
+		// 内置字体没有粗体，以描边加粗
+		mode = 2
+	}
+	fmt.Fprintf(c.buf, "BT 0 g 0 G %d Tr %.2f w /F1 %.2f Tf %.2f %.2f Td <%s> Tj ET\n",
+		mode, size*ptPerMM*0.03, size*ptPerMM, px, py, ucs2Hex(s))
+}
+
+// ucs2Hex 按 UniGB-UCS2-H 编码为十六进制字符串，BMP以外的字符替换为问号
+func ucs2Hex(s string) string {
+	var buf bytes.Buffer
+	for _, r := range s {
+		if r > 0xFFFF {
+			r = '?'
+		}
+		for _, u := range utf16.Encode([]rune{r}) {
+			fmt.Fprintf(&buf, "%04X", u)
+		}
+	}
+	return buf.String()
+}
+
+// PDF 将标签排版为PDF，每页一页
+func PDF(labels []Label, layout Layout) []byte {
+	var out bytes.Buffer
+	var offsets []int
+	obj := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	pages := paginate(labels, layout)
+	if len(pages) == 0 {
+		pages = [][]Label{nil}
+	}
+
+	// 1 目录，2 页面树，3-5 字体，之后每页依次为页面和内容流
+	const firstPage = 6
+	kids := ""
+	for i := range pages {
+		kids += fmt.Sprintf("%d 0 R ", firstPage+2*i)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids, len(pages)))
+	obj("<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [4 0 R] >>")
+	obj("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light " +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> " +
+		"/FontDescriptor 5 0 R /DW 1000 /W [1 95 500] >>")
+	obj("<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] " +
+		"/ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+
+	width, height := layout.PageWidth*ptPerMM, layout.PageHeight*ptPerMM
+	for i, page := range pages {
+		var content bytes.Buffer
+		c := pdfCanvas{buf: &content, pageHeight: layout.PageHeight}
+		for j, l := range page {
+			x, y := layout.position(j)
+			drawLabel(c, l, x, y, layout.LabelWidth, layout.LabelHeight)
+		}
+
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", width, height, firstPage+2*i+1))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
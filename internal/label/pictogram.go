@@ -0,0 +1,165 @@
+package label
+
+import "math"
+
+// pictogramBox 象形图图形的坐标范围，原点在左上角，菱形的四个顶点在各边中点
+const pictogramBox = 100.0
+
+// 象形图中的颜色
+const (
+	colorRed   = "red"
+	colorBlack = "black"
+	colorWhite = "white"
+)
+
+// point 象形图坐标系中的点
+type point struct{ x, y float64 }
+
+// shape 象形图中的一个图形：多边形、折线或圆，stroke 大于 0 时只描边，否则填充
+type shape struct {
+	points []point // 多边形或折线的顶点；圆只有圆心一个点
+	radius float64 // 大于 0 时为圆
+	stroke float64 // 描边宽度
+	closed bool    // 描边时是否闭合
+	color  string
+}
+
+// 以下函数按 x1, y1, x2, y2... 的顺序给出坐标
+func points(xy []float64) []point {
+	pts := make([]point, 0, len(xy)/2)
+	for i := 0; i+1 < len(xy); i += 2 {
+		pts = append(pts, point{xy[i], xy[i+1]})
+	}
+	return pts
+}
+
+// fill 填充的多边形
+func fill(color string, xy ...float64) shape {
+	return shape{points: points(xy), color: color}
+}
+
+// line 黑色折线
+func line(width float64, xy ...float64) shape {
+	return shape{points: points(xy), stroke: width, color: colorBlack}
+}
+
+// dot 填充的圆
+func dot(color string, cx, cy, r float64) shape {
+	return shape{points: []point{{cx, cy}}, radius: r, color: color}
+}
+
+// ring 黑色圆环
+func ring(width, cx, cy, r float64) shape {
+	return shape{points: []point{{cx, cy}}, radius: r, stroke: width, color: colorBlack}
+}
+
+// star 以 (cx, cy) 为中心的 n 角星
+func star(color string, cx, cy, outer, inner float64, n int) shape {
+	var xy []float64
+	for i := 0; i < 2*n; i++ {
+		r := outer
+		if i%2 == 1 {
+			r = inner
+		}
+		angle := math.Pi*float64(i)/float64(n) - math.Pi/2
+		xy = append(xy, cx+r*math.Cos(angle), cy+r*math.Sin(angle))
+	}
+	return fill(color, xy...)
+}
+
+// border 白底红色菱形边框，所有象形图共用
+var border = []shape{
+	fill(colorWhite, 50, 4, 96, 50, 50, 96, 4, 50),
+	{points: points([]float64{50, 4, 96, 50, 50, 96, 4, 50}), stroke: 7, closed: true, color: colorRed},
+}
+
+// flame 火焰，GHS02 和 GHS03 共用
+var flame = fill(colorBlack,
+	50, 24, 55, 35, 61, 31, 63, 43, 67, 52, 65, 61, 58, 68, 50, 70,
+	42, 68, 35, 61, 33, 52, 37, 43, 42, 48, 43, 37, 47, 40)
+
+// baseBar 火焰下方的横条
+var baseBar = fill(colorBlack, 34, 72, 66, 72, 66, 76, 34, 76)
+
+// pictograms GHS 象形图的简化矢量图形，按《全球化学品统一分类和标签制度》的图样绘制：
+// 红色菱形边框，白底黑色符号
+var pictograms = map[string][]shape{
+	// 爆炸的炸弹
+	"GHS01": {
+		dot(colorBlack, 42, 60, 12),
+		line(3, 50, 51, 56, 45),
+		star(colorBlack, 61, 39, 11, 4, 8),
+		fill(colorBlack, 28, 40, 33, 37, 33, 43),
+		fill(colorBlack, 66, 60, 72, 59, 69, 65),
+		fill(colorBlack, 44, 30, 48, 27, 49, 32),
+	},
+	// 火焰
+	"GHS02": {flame, baseBar},
+	// 圆圈上方火焰
+	"GHS03": {flame, dot(colorWhite, 50, 58, 12), ring(5, 50, 58, 9), baseBar},
+	// 气瓶
+	"GHS04": {
+		fill(colorBlack, 34, 56, 63, 41, 69, 53, 40, 68),
+		dot(colorBlack, 37, 62, 6.7),
+		dot(colorBlack, 66, 47, 6.7),
+		fill(colorBlack, 70, 42, 75, 39, 77, 43, 72, 46),
+	},
+	// 腐蚀：液体滴在金属和手上
+	"GHS05": {
+		fill(colorBlack, 32, 35, 37, 31, 47, 46, 42, 50),
+		dot(colorBlack, 44, 54, 2),
+		dot(colorBlack, 45, 59, 1.6),
+		fill(colorBlack, 29, 63, 46, 63, 46, 70, 29, 70),
+		dot(colorWhite, 37.5, 63, 3),
+		fill(colorBlack, 68, 35, 63, 31, 53, 46, 58, 50),
+		dot(colorBlack, 56, 54, 2),
+		dot(colorBlack, 55, 59, 1.6),
+		fill(colorBlack, 54, 63, 71, 63, 71, 70, 54, 70),
+		dot(colorWhite, 62.5, 63, 3),
+		fill(colorBlack, 31, 75, 69, 75, 50, 92),
+	},
+	// 骷髅和交叉骨
+	"GHS06": {
+		line(6, 32, 70, 68, 50),
+		line(6, 32, 50, 68, 70),
+		dot(colorBlack, 30, 67, 3.5), dot(colorBlack, 33, 73, 3.5),
+		dot(colorBlack, 70, 53, 3.5), dot(colorBlack, 67, 47, 3.5),
+		dot(colorBlack, 30, 53, 3.5), dot(colorBlack, 33, 47, 3.5),
+		dot(colorBlack, 70, 67, 3.5), dot(colorBlack, 67, 73, 3.5),
+		dot(colorBlack, 50, 38, 13),
+		fill(colorBlack, 42, 46, 58, 46, 57, 57, 43, 57),
+		dot(colorWhite, 45, 38, 3.6),
+		dot(colorWhite, 55, 38, 3.6),
+		fill(colorWhite, 50, 43, 48.5, 47, 51.5, 47),
+	},
+	// 感叹号
+	"GHS07": {
+		fill(colorBlack, 45.5, 26, 54.5, 26, 52.5, 60, 47.5, 60),
+		dot(colorBlack, 50, 68, 4.5),
+	},
+	// 健康危害：胸前有星形的人体
+	"GHS08": {
+		dot(colorBlack, 50, 31, 7),
+		fill(colorBlack, 38, 41, 62, 41, 68, 52, 64, 76, 36, 76, 32, 52),
+		star(colorWhite, 50, 58, 11, 4, 8),
+	},
+	// 环境：枯树和死鱼
+	"GHS09": {
+		line(3, 36, 72, 36, 34),
+		line(2, 36, 48, 28, 42),
+		line(2, 36, 44, 44, 37),
+		line(2, 36, 56, 44, 51),
+		line(2, 36, 40, 30, 33),
+		line(3, 32, 74, 68, 74),
+		fill(colorBlack, 46, 66, 52, 62, 62, 61, 70, 64, 62, 68, 52, 69),
+		fill(colorBlack, 69, 64, 76, 59, 76, 69),
+		dot(colorWhite, 51, 64.5, 1.2),
+	},
+}
+
+// pictogramShapes 返回象形图的全部图形，未知代码时只有边框
+func pictogramShapes(code string) ([]shape, bool) {
+	symbol, ok := pictograms[code]
+	shapes := append(append([]shape(nil), border...), symbol...)
+	return shapes, ok
+}
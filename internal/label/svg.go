@@ -0,0 +1,75 @@
+package label
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// svgCanvas 将绘图操作写为SVG元素，用户单位为毫米
+type svgCanvas struct {
+	buf *bytes.Buffer
+}
+
+func (c svgCanvas) rect(x, y, w, h float64) {
+	fmt.Fprintf(c.buf, `<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="none" stroke="#cccccc" stroke-width="0.1"/>`+"\n", x, y, w, h)
+}
+
+func (c svgCanvas) pictogram(x, y, size float64, shapes []shape, title string) {
+	fmt.Fprintf(c.buf, `<g class="pictogram" transform="translate(%.2f %.2f) scale(%.4f)"><title>%s</title>`+"\n",
+		x, y, size/pictogramBox, html.EscapeString(title))
+	for _, sh := range shapes {
+		paint := fmt.Sprintf(`fill="%s"`, sh.color)
+		if sh.stroke > 0 {
+			paint = fmt.Sprintf(`fill="none" stroke="%s" stroke-width="%g" stroke-linecap="round" stroke-linejoin="round"`, sh.color, sh.stroke)
+		}
+		switch {
+		case sh.radius > 0:
+			fmt.Fprintf(c.buf, `<circle cx="%g" cy="%g" r="%g" %s/>`+"\n", sh.points[0].x, sh.points[0].y, sh.radius, paint)
+		default:
+			element := "polygon"
+			if sh.stroke > 0 && !sh.closed {
+				element = "polyline"
+			}
+			var pts []string
+			for _, p := range sh.points {
+				pts = append(pts, fmt.Sprintf("%g,%g", p.x, p.y))
+			}
+			fmt.Fprintf(c.buf, `<%s points="%s" %s/>`+"\n", element, strings.Join(pts, " "), paint)
+		}
+	}
+	c.buf.WriteString("</g>\n")
+}
+
+func (c svgCanvas) text(x, y, size float64, s string, bold, center bool) {
+	attrs := ""
+	if bold {
+		attrs += ` font-weight="bold"`
+	}
+	if center {
+		attrs += ` text-anchor="middle"`
+	}
+	fmt.Fprintf(c.buf, `<text x="%.2f" y="%.2f" font-size="%.2f"%s>%s</text>`+"\n", x, y, size, attrs, html.EscapeString(s))
+}
+
+// SVG 将标签排版为SVG，每页一个文件
+func SVG(labels []Label, layout Layout) [][]byte {
+	var pages [][]byte
+	for _, page := range paginate(labels, layout) {
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%gmm" height="%gmm" viewBox="0 0 %g %g">`+"\n",
+			layout.PageWidth, layout.PageHeight, layout.PageWidth, layout.PageHeight)
+		buf.WriteString(`<g font-family="SimSun, 'Noto Sans CJK SC', 'Source Han Sans SC', sans-serif">` + "\n")
+
+		c := svgCanvas{buf: &buf}
+		for i, l := range page {
+			x, y := layout.position(i)
+			drawLabel(c, l, x, y, layout.LabelWidth, layout.LabelHeight)
+		}
+
+		buf.WriteString("</g>\n</svg>\n")
+		pages = append(pages, buf.Bytes())
+	}
+	return pages
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteTo(t *testing.T) {
+	r := &Registry{}
+	requests := r.NewCounter("cas_provider_requests_total", "各数据源的查询次数", "provider", "result")
+	latency := r.NewHistogram("cas_write_duration_seconds", "写入耗时", []float64{0.1, 1}, "writer")
+	total := r.NewCounter("cas_rows_total", "处理的行数")
+
+	requests.Inc("ichemistry", "found")
+	requests.Inc("ichemistry", "found")
+	requests.Inc("chem\"src", "error")
+	latency.Observe(0.05, "excel")
+	latency.Observe(0.5, "excel")
+	latency.Observe(3, "excel")
+	total.Add(2)
+
+	var b strings.Builder
+	if _, err := r.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP cas_provider_requests_total 各数据源的查询次数
+# TYPE cas_provider_requests_total counter
+cas_provider_requests_total{provider="chem\"src",result="error"} 1
+cas_provider_requests_total{provider="ichemistry",result="found"} 2
+# HELP cas_rows_total 处理的行数
+# TYPE cas_rows_total counter
+cas_rows_total 2
+# HELP cas_write_duration_seconds 写入耗时
+# TYPE cas_write_duration_seconds histogram
+cas_write_duration_seconds_bucket{writer="excel",le="0.1"} 1
+cas_write_duration_seconds_bucket{writer="excel",le="1"} 2
+cas_write_duration_seconds_bucket{writer="excel",le="+Inf"} 3
+cas_write_duration_seconds_sum{writer="excel"} 3.55
+cas_write_duration_seconds_count{writer="excel"} 3
+`
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := requests.Value("ichemistry", "found"); got != 2 {
+		t.Errorf("Value = %v, want 2", got)
+	}
+	if got := latency.Count("excel"); got != 3 {
+		t.Errorf("Count = %v, want 3", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r := &Registry{}
+	r.NewCounter("cas_cache_lookups_total", "缓存查询次数", "result").Inc("hit")
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), `cas_cache_lookups_total{result="hit"} 1`) {
+		t.Errorf("body:\n%s", rec.Body.String())
+	}
+}
+
+func TestDuplicateRegistration(t *testing.T) {
+	r := &Registry{}
+	r.NewCounter("x_total", "")
+	defer func() {
+		if recover() == nil {
+			t.Error("重复注册应 panic")
+		}
+	}()
+	r.NewCounter("x_total", "")
+}
@@ -0,0 +1,213 @@
+// Package progress 跟踪批量任务的进度，估算剩余时间。输出到终端时显示进度条，
+// 否则按固定间隔通过 slog 输出进度日志
+package progress
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"cas.mod/internal/i18n"
+)
+
+// 默认参数
+const (
+	DefaultInterval = 30 * time.Second       // 非终端时输出进度日志的间隔
+	redrawInterval  = 200 * time.Millisecond // 终端中进度条的最短刷新间隔
+	barWidth        = 30
+)
+
+// Snapshot 某一时刻的进度
+type Snapshot struct {
+	Total     int
+	Done      int
+	Succeeded int
+	Failed    int
+	Elapsed   time.Duration
+	Rate      float64       // 每秒处理的行数
+	ETA       time.Duration // 预计剩余时间，尚无法估算时为 -1
+}
+
+// String 如 "120/8713 (1.4%) 成功 117 失败 3 | 2.1 行/秒 | 已用 57s 剩余 1h8m12s"
+func (s Snapshot) String() string {
+	percent := 100.0
+	if s.Total > 0 {
+		percent = float64(s.Done) / float64(s.Total) * 100
+	}
+	eta := i18n.T("未知")
+	if s.ETA >= 0 {
+		eta = s.ETA.Round(time.Second).String()
+	}
+	return i18n.Sprintf("%d/%d (%.1f%%) 成功 %d 失败 %d | %.1f 行/秒 | 已用 %s 剩余 %s",
+		s.Done, s.Total, percent, s.Succeeded, s.Failed, s.Rate, s.Elapsed.Round(time.Second), eta)
+}
+
+// attrs 进度日志的字段，剩余时间无法估算时不输出 eta
+func (s Snapshot) attrs() []any {
+	attrs := []any{
+		"done", s.Done, "total", s.Total, "succeeded", s.Succeeded, "failed", s.Failed,
+		"rate", math.Round(s.Rate*10) / 10, "elapsed", s.Elapsed.Round(time.Second).String(),
+	}
+	if s.ETA >= 0 {
+		attrs = append(attrs, "eta", s.ETA.Round(time.Second).String())
+	}
+	return attrs
+}
+
+// Tracker 批量任务的进度，可以在多个协程中使用
+type Tracker struct {
+	Interval time.Duration // 非终端时输出进度日志的间隔，为 0 时使用 DefaultInterval
+
+	total  int
+	out    io.Writer
+	logger *slog.Logger // 非终端时输出进度日志
+	tty    bool
+	now    func() time.Time
+
+	mu        sync.Mutex
+	start     time.Time
+	last      time.Time // 上次输出的时间
+	succeeded int
+	failed    int
+	shown     bool // 终端中进度条当前是否显示
+}
+
+// New 创建进度跟踪，out 为终端时显示进度条，否则用 slog 的默认日志输出进度
+func New(total int, out io.Writer) *Tracker {
+	t := &Tracker{total: total, out: out, logger: slog.Default(), now: time.Now}
+	if f, ok := out.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			t.tty = true
+		}
+	}
+	t.start = t.now()
+	t.last = t.start
+	return t
+}
+
+// Success 记录一行处理成功
+func (t *Tracker) Success() {
+	t.add(true)
+}
+
+// Failure 记录一行处理失败
+func (t *Tracker) Failure() {
+	t.add(false)
+}
+
+// Add 按 ok 记录一行的结果
+func (t *Tracker) Add(ok bool) {
+	t.add(ok)
+}
+
+func (t *Tracker) add(ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ok {
+		t.succeeded++
+	} else {
+		t.failed++
+	}
+
+	now := t.now()
+	if t.tty {
+		if now.Sub(t.last) >= redrawInterval {
+			t.last = now
+			t.draw()
+		}
+		return
+	}
+	interval := t.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	if now.Sub(t.last) >= interval {
+		t.last = now
+		t.logger.Info("进度", t.snapshot().attrs()...)
+	}
+}
+
+// Snapshot 返回当前进度
+func (t *Tracker) Snapshot() Snapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.snapshot()
+}
+
+func (t *Tracker) snapshot() Snapshot {
+	s := Snapshot{
+		Total:     t.total,
+		Done:      t.succeeded + t.failed,
+		Succeeded: t.succeeded,
+		Failed:    t.failed,
+		Elapsed:   t.now().Sub(t.start),
+		ETA:       -1,
+	}
+	if s.Elapsed > 0 {
+		s.Rate = float64(s.Done) / s.Elapsed.Seconds()
+	}
+	if s.Done >= s.Total {
+		s.ETA = 0
+	} else if s.Rate > 0 {
+		s.ETA = time.Duration(float64(s.Total-s.Done) / s.Rate * float64(time.Second))
+	}
+	return s
+}
+
+// draw 在当前行重绘进度条，调用方须持有锁
+func (t *Tracker) draw() {
+	s := t.snapshot()
+	filled := barWidth
+	if s.Total > 0 {
+		filled = s.Done * barWidth / s.Total
+	}
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled)
+	fmt.Fprintf(t.out, "\r\033[K[%s] %s", bar, s)
+	t.shown = true
+}
+
+// Finish 输出最终进度。终端中保留进度条并换行
+func (t *Tracker) Finish() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.tty {
+		t.draw()
+		fmt.Fprintln(t.out)
+		t.shown = false
+		return
+	}
+	t.logger.Info("完成", t.snapshot().attrs()...)
+}
+
+// LogWriter 包装日志输出：终端中先清除进度条，写完日志后重绘，
+// 使逐行日志显示在进度条上方；非终端时原样写入 w
+func (t *Tracker) LogWriter(w io.Writer) io.Writer {
+	if !t.tty {
+		return w
+	}
+	return &logWriter{t: t, w: w}
+}
+
+type logWriter struct {
+	t *Tracker
+	w io.Writer
+}
+
+func (lw *logWriter) Write(p []byte) (int, error) {
+	lw.t.mu.Lock()
+	defer lw.t.mu.Unlock()
+	if lw.t.shown {
+		fmt.Fprint(lw.t.out, "\r\033[K")
+	}
+	n, err := lw.w.Write(p)
+	if bytes.HasSuffix(p, []byte("\n")) {
+		lw.t.draw()
+	}
+	return n, err
+}
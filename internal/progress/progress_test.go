@@ -0,0 +1,98 @@
+package progress
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock 每次调用前进 step
+type fakeClock struct {
+	t    time.Time
+	step time.Duration
+}
+
+func (c *fakeClock) now() time.Time {
+	c.t = c.t.Add(c.step)
+	return c.t
+}
+
+func TestSnapshot(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	tracker := New(10, &bytes.Buffer{})
+	tracker.now, tracker.start = clock.now, clock.t
+
+	if s := tracker.Snapshot(); s.ETA != -1 || s.Done != 0 {
+		t.Errorf("开始时 %+v", s)
+	}
+
+	clock.step = 0
+	for i := 0; i < 4; i++ {
+		tracker.Success()
+	}
+	tracker.Failure()
+	clock.t = clock.t.Add(10 * time.Second)
+
+	s := tracker.Snapshot()
+	if s.Done != 5 || s.Succeeded != 4 || s.Failed != 1 || s.Rate != 0.5 || s.ETA != 10*time.Second {
+		t.Errorf("Snapshot = %+v", s)
+	}
+	if got, want := s.String(), "5/10 (50.0%) 成功 4 失败 1 | 0.5 行/秒 | 已用 10s 剩余 10s"; got != want {
+		t.Errorf("String = %q, want %q", got, want)
+	}
+}
+
+func TestLogMode(t *testing.T) {
+	var out bytes.Buffer
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), step: 4 * time.Second}
+	tracker := New(6, &out)
+	tracker.now, tracker.start, tracker.last = clock.now, clock.t, clock.t
+	tracker.Interval = 10 * time.Second
+	tracker.logger = slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	for i := 0; i < 6; i++ {
+		tracker.Add(i != 2)
+	}
+	tracker.Finish()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	// 每次 Add 前进4秒，第3、6次调用时距上次输出超过10秒
+	if len(lines) != 3 ||
+		lines[0] != "level=INFO msg=进度 done=3 total=6 succeeded=2 failed=1 rate=0.2 elapsed=16s eta=16s" ||
+		lines[2] != "level=INFO msg=完成 done=6 total=6 succeeded=5 failed=1 rate=0.2 elapsed=36s eta=0s" {
+		t.Errorf("输出:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "\r") {
+		t.Error("非终端时不应输出进度条")
+	}
+}
+
+func TestTerminalMode(t *testing.T) {
+	var out, logs bytes.Buffer
+	tracker := New(2, &out)
+	tracker.tty = true
+	w := tracker.LogWriter(&logs)
+
+	tracker.Success()
+	w.Write([]byte("第 2 行 写入\n"))
+	tracker.Finish()
+
+	if !strings.Contains(out.String(), "\r\033[K[===============               ] 1/2") {
+		t.Errorf("进度条:\n%q", out.String())
+	}
+	if !strings.HasSuffix(out.String(), "\n") {
+		t.Error("结束后应换行")
+	}
+	if logs.String() != "第 2 行 写入\n" {
+		t.Errorf("日志 = %q", logs.String())
+	}
+}
@@ -0,0 +1,285 @@
+package sds
+
+import (
+	"strconv"
+	"strings"
+)
+
+// token 内容流中的一个记号
+type token struct {
+	kind  byte    // 'n' 数字，'/' 名称，'s' 字符串，'[' 数组，'o' 操作符
+	num   float64 // 数字
+	text  string  // 名称或操作符
+	str   []byte  // 字符串的原始字节
+	items []token // 数组元素
+}
+
+// lexer 内容流的词法分析
+type lexer struct {
+	data []byte
+	pos  int
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch {
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0:
+			l.pos++
+		default:
+			return
+		}
+	}
+}
+
+// next 返回下一个记号，到达末尾时返回 false
+func (l *lexer) next() (token, bool) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return token{}, false
+	}
+
+	c := l.data[l.pos]
+	switch {
+	case c == '(':
+		return token{kind: 's', str: l.literal()}, true
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		// 标记内容的属性字典，只需跳过
+		l.pos += balanced(string(l.data[l.pos:]), "<<", ">>")
+		return l.next()
+	case c == '<':
+		return token{kind: 's', str: l.hex()}, true
+	case c == '[':
+		l.pos++
+		arr := token{kind: '['}
+		for {
+			l.skipSpace()
+			if l.pos >= len(l.data) {
+				return arr, true
+			}
+			if l.data[l.pos] == ']' {
+				l.pos++
+				return arr, true
+			}
+			item, ok := l.next()
+			if !ok {
+				return arr, true
+			}
+			arr.items = append(arr.items, item)
+		}
+	case c == ']' || c == '>' || c == ')' || c == '{' || c == '}':
+		l.pos++
+		return l.next()
+	case c == '/':
+		start := l.pos + 1
+		l.pos++
+		for l.pos < len(l.data) && !isDelimiter(l.data[l.pos]) {
+			l.pos++
+		}
+		return token{kind: '/', text: string(l.data[start:l.pos])}, true
+	}
+
+	start := l.pos
+	for l.pos < len(l.data) && !isDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	if l.pos == start {
+		l.pos++
+	}
+	word := string(l.data[start:l.pos])
+	if n, err := strconv.ParseFloat(word, 64); err == nil {
+		return token{kind: 'n', num: n}, true
+	}
+	if word == "BI" {
+		l.skipInlineImage()
+		return l.next()
+	}
+	return token{kind: 'o', text: word}, true
+}
+
+// literal 读取 (...) 字符串，处理转义和嵌套括号
+func (l *lexer) literal() []byte {
+	var out []byte
+	depth := 0
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+			if depth == 1 {
+				continue
+			}
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r', '\n':
+				// 续行
+				if e == '\r' && l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(v)
+				} else {
+					c = e
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// hex 读取 <...> 十六进制字符串
+func (l *lexer) hex() []byte {
+	l.pos++
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if c := l.data[l.pos]; strings.IndexByte("0123456789abcdefABCDEF", c) >= 0 {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	for i := range out {
+		v, _ := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		out[i] = byte(v)
+	}
+	return out
+}
+
+// skipInlineImage 跳过 BI ... ID <二进制> EI
+func (l *lexer) skipInlineImage() {
+	for l.pos+2 < len(l.data) {
+		if l.data[l.pos] == 'E' && l.data[l.pos+1] == 'I' && isDelimiter(l.data[l.pos-1]) &&
+			(l.pos+2 == len(l.data) || isDelimiter(l.data[l.pos+2])) {
+			l.pos += 2
+			return
+		}
+		l.pos++
+	}
+	l.pos = len(l.data)
+}
+
+// kerningSpace TJ 数组中超过该值（千分之一字号）的负间距视为空格
+const kerningSpace = -250
+
+// extractText 执行内容流中的文字操作符，换行由文本位置的纵向变化决定
+func extractText(out *strings.Builder, content []byte, fonts map[string]*pdfFont) {
+	lx := &lexer{data: content}
+	var operands []token
+	var font *pdfFont
+	var textY float64 // 当前文本行的纵坐标，BT 时复位
+	var lineY float64 // 输出中当前行的纵坐标
+	var leading float64
+	hasLine := false
+
+	newline := func() {
+		s := out.String()
+		if len(s) > 0 && !strings.HasSuffix(s, "\n") {
+			out.WriteByte('\n')
+		}
+	}
+	show := func(t token) {
+		if t.kind == 's' {
+			out.WriteString(font.decode(t.str))
+		}
+	}
+	moveTo := func(y float64) {
+		if hasLine && y != lineY {
+			newline()
+		}
+		lineY, hasLine = y, true
+	}
+	nextLine := func() {
+		textY -= leading
+		newline()
+		lineY, hasLine = textY, true
+	}
+
+	for {
+		t, ok := lx.next()
+		if !ok {
+			return
+		}
+		if t.kind != 'o' {
+			operands = append(operands, t)
+			continue
+		}
+
+		arg := func(i int) token {
+			if i < len(operands) {
+				return operands[len(operands)-1-i]
+			}
+			return token{}
+		}
+		switch t.text {
+		case "Tf":
+			if name := arg(1); name.kind == '/' {
+				font = fonts[name.text]
+			}
+		case "BT":
+			textY = 0
+		case "TL":
+			leading = arg(0).num
+		case "Td", "TD":
+			if t.text == "TD" {
+				leading = -arg(0).num
+			}
+			textY += arg(0).num
+			moveTo(textY)
+		case "Tm":
+			textY = arg(0).num
+			moveTo(textY)
+		case "T*":
+			nextLine()
+		case "Tj":
+			show(arg(0))
+		case "'", "\"":
+			nextLine()
+			show(arg(0))
+		case "TJ":
+			for _, item := range arg(0).items {
+				if item.kind == 'n' && item.num < kerningSpace {
+					out.WriteByte(' ')
+				}
+				show(item)
+			}
+		}
+		operands = operands[:0]
+	}
+}
@@ -0,0 +1,524 @@
+package sds
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"cas.mod/internal/i18n"
+)
+
+// 只实现提取SDS文字所需的PDF子集：间接对象、对象流、FlateDecode、页面树、
+// 字体的 ToUnicode 映射和 UCS2/UTF16 预定义编码。加密和扫描件不支持。
+
+// 限制不可信文件解码后的大小，防止小文件展开为巨大的流或映射
+const (
+	maxDecodedSize = 64 << 20 // 整个文件中所有流解码后的总字节数
+	maxCMapEntries = 1 << 16  // 一个 ToUnicode CMap 的映射数
+)
+
+// tooLargeError 流解码后超过 maxDecodedSize，出现时停止解析整个文件
+type tooLargeError struct{}
+
+func (tooLargeError) Error() string {
+	return i18n.Sprintf("PDF流解压后超过 %d MB", maxDecodedSize>>20)
+}
+
+var errTooLarge error = tooLargeError{}
+
+// pdfObject 一个间接对象
+type pdfObject struct {
+	dict   string // 字典或对象的原文
+	stream []byte // 解码后的流，没有流或过滤器不支持时为 nil
+}
+
+// pdfFile 解析后的PDF文件
+type pdfFile struct {
+	objects map[int]*pdfObject
+	fonts   map[int]*pdfFont
+}
+
+var (
+	objHeader  = regexp.MustCompile(`(\d+)\s+\d+\s+obj\b`)
+	refPattern = regexp.MustCompile(`^(\d+)\s+\d+\s+R$`)
+	refsInText = regexp.MustCompile(`(\d+)\s+\d+\s+R\b`)
+	namedRefs  = regexp.MustCompile(`/([^\s/<>\[\]()]+)\s+(\d+)\s+\d+\s+R\b`)
+	intPattern = regexp.MustCompile(`/(N|First)\s+(\d+)`)
+	leadingRef = regexp.MustCompile(`^\d+\s+\d+\s+R\b`)
+	catalog    = regexp.MustCompile(`/Type\s*/Catalog\b`)
+)
+
+// parsePDF 按 "N G obj" 扫描所有对象，不依赖交叉引用表，损坏的文件也能尽量读取。
+// 返回第一个解压错误，解压后超过 maxDecodedSize 时停止解析
+func parsePDF(data []byte) (*pdfFile, error) {
+	f := &pdfFile{objects: make(map[int]*pdfObject), fonts: make(map[int]*pdfFont)}
+
+	var firstErr error
+	budget := int64(maxDecodedSize)
+	locs := objHeader.FindAllSubmatchIndex(data, -1)
+	for i, loc := range locs {
+		num, _ := strconv.Atoi(string(data[loc[2]:loc[3]]))
+		end := len(data)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		obj, err := parseObject(data[loc[1]:end], budget)
+		if err == errTooLarge {
+			return nil, err
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		budget -= int64(len(obj.stream))
+		f.objects[num] = obj
+	}
+
+	// 对象流中的对象，文件中直接出现的同号对象优先
+	for _, obj := range f.objects {
+		if obj.stream == nil || !strings.Contains(obj.dict, "/ObjStm") {
+			continue
+		}
+		f.expandObjectStream(obj)
+	}
+	return f, firstErr
+}
+
+// parseObject 拆分对象的字典和流，流最多解压 limit 字节
+func parseObject(body []byte, limit int64) (*pdfObject, error) {
+	obj := &pdfObject{}
+	si := bytes.Index(body, []byte("stream"))
+	ei := bytes.Index(body, []byte("endobj"))
+	if si < 0 || (ei >= 0 && ei < si) {
+		if ei >= 0 {
+			body = body[:ei]
+		}
+		obj.dict = strings.TrimSpace(string(body))
+		return obj, nil
+	}
+
+	obj.dict = strings.TrimSpace(string(body[:si]))
+	start := si + len("stream")
+	if start < len(body) && body[start] == '\r' {
+		start++
+	}
+	if start < len(body) && body[start] == '\n' {
+		start++
+	}
+	end := bytes.LastIndex(body, []byte("endstream"))
+	if end < start {
+		end = len(body)
+	}
+	raw := bytes.TrimRight(body[start:end], "\r\n")
+	var err error
+	obj.stream, err = decodeStream(obj.dict, raw, limit)
+	return obj, err
+}
+
+// decodeStream 按 /Filter 解码，只支持不带过滤器和 FlateDecode。
+// 解压后超过 limit 字节时返回 errTooLarge；流末尾损坏时返回已解压的部分和错误
+func decodeStream(dict string, raw []byte, limit int64) ([]byte, error) {
+	filter := dictValue(dict, "Filter")
+	switch {
+	case filter == "":
+		return raw, nil
+	case strings.Trim(filter, "[] ") == "/FlateDecode":
+		r, err := zlib.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, i18n.Errorf("PDF流解压失败: %v", err)
+		}
+		data, err := io.ReadAll(io.LimitReader(r, limit+1))
+		if int64(len(data)) > limit {
+			return nil, errTooLarge
+		}
+		if err != nil {
+			return data, i18n.Errorf("PDF流解压失败: %v", err)
+		}
+		return data, nil
+	}
+	return nil, nil
+}
+
+// expandObjectStream 读取对象流中的对象
+func (f *pdfFile) expandObjectStream(obj *pdfObject) {
+	n, first := -1, -1
+	for _, m := range intPattern.FindAllStringSubmatch(obj.dict, -1) {
+		v, _ := strconv.Atoi(m[2])
+		if m[1] == "N" {
+			n = v
+		} else {
+			first = v
+		}
+	}
+	if n <= 0 || first < 0 || first > len(obj.stream) {
+		return
+	}
+
+	header := strings.Fields(string(obj.stream[:first]))
+	if len(header) < 2*n {
+		return
+	}
+	for i := 0; i < n; i++ {
+		num, err1 := strconv.Atoi(header[2*i])
+		off, err2 := strconv.Atoi(header[2*i+1])
+		// 偏移量来自文件，可能为负数或超出流的长度
+		if err1 != nil || err2 != nil || off < 0 || off > len(obj.stream) {
+			return
+		}
+		start, end := first+off, len(obj.stream)
+		if i+1 < n {
+			if next, err := strconv.Atoi(header[2*i+3]); err == nil && next >= 0 && next <= len(obj.stream) {
+				end = first + next
+			}
+		}
+		if start < first || start > end || end > len(obj.stream) {
+			return
+		}
+		if _, ok := f.objects[num]; !ok {
+			f.objects[num] = &pdfObject{dict: strings.TrimSpace(string(obj.stream[start:end]))}
+		}
+	}
+}
+
+// isDelimiter PDF 的分隔符和空白
+func isDelimiter(c byte) bool {
+	return strings.IndexByte(" \t\r\n\f\x00()<>[]{}/%", c) >= 0
+}
+
+// dictValue 返回字典中键对应的值的原文：嵌套字典、数组、字符串、引用或单个记号
+func dictValue(dict, key string) string {
+	name := "/" + key
+	for from := 0; ; {
+		i := strings.Index(dict[from:], name)
+		if i < 0 {
+			return ""
+		}
+		i += from
+		end := i + len(name)
+		from = end
+		if end < len(dict) && !isDelimiter(dict[end]) {
+			continue
+		}
+		return readValue(strings.TrimLeft(dict[end:], " \t\r\n"))
+	}
+}
+
+// readValue 读取字符串开头的一个值
+func readValue(s string) string {
+	switch {
+	case strings.HasPrefix(s, "<<"):
+		return s[:balanced(s, "<<", ">>")]
+	case strings.HasPrefix(s, "["):
+		return s[:balanced(s, "[", "]")]
+	case strings.HasPrefix(s, "("):
+		return s[:balanced(s, "(", ")")]
+	}
+
+	// 整数后接 "G R" 时为引用，否则为单个记号
+	if m := leadingRef.FindString(s); m != "" {
+		return m
+	}
+	end := 1
+	for end < len(s) && !isDelimiter(s[end]) {
+		end++
+	}
+	if end > len(s) {
+		return s
+	}
+	return s[:end]
+}
+
+// balanced 返回与开头的 open 配对的 close 之后的位置，找不到时返回字符串长度
+func balanced(s, open, close string) int {
+	depth := 0
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], open):
+			depth++
+			i += len(open)
+		case strings.HasPrefix(s[i:], close):
+			depth--
+			i += len(close)
+			if depth == 0 {
+				return i
+			}
+		default:
+			i++
+		}
+	}
+	return len(s)
+}
+
+// ref 解析 "N G R" 形式的引用
+func ref(v string) (int, bool) {
+	m := refPattern.FindStringSubmatch(strings.TrimSpace(v))
+	if m == nil {
+		return 0, false
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n, true
+}
+
+// resolve 值为引用时返回被引用对象的原文
+func (f *pdfFile) resolve(v string) string {
+	if n, ok := ref(v); ok {
+		if obj := f.objects[n]; obj != nil {
+			return obj.dict
+		}
+		return ""
+	}
+	return v
+}
+
+// pdfPage 一页的内容流和字体
+type pdfPage struct {
+	content []byte
+	fonts   map[string]*pdfFont
+}
+
+// pages 按页面树顺序返回页面；找不到页面树时按对象编号顺序返回所有含文字的流
+func (f *pdfFile) pages() []pdfPage {
+	for _, obj := range f.objects {
+		if catalog.MatchString(obj.dict) {
+			if root, ok := ref(dictValue(obj.dict, "Pages")); ok {
+				var pages []pdfPage
+				f.walk(root, "", &pages, make(map[int]bool))
+				if len(pages) > 0 {
+					return pages
+				}
+			}
+		}
+	}
+
+	var nums []int
+	for n, obj := range f.objects {
+		if obj.stream != nil && bytes.Contains(obj.stream, []byte("BT")) {
+			nums = append(nums, n)
+		}
+	}
+	sort.Ints(nums)
+	var pages []pdfPage
+	for _, n := range nums {
+		pages = append(pages, pdfPage{content: f.objects[n].stream})
+	}
+	return pages
+}
+
+// walk 递归遍历页面树，资源字典可从上级继承
+func (f *pdfFile) walk(n int, resources string, pages *[]pdfPage, seen map[int]bool) {
+	obj := f.objects[n]
+	if obj == nil || seen[n] {
+		return
+	}
+	seen[n] = true
+
+	if res := f.resolve(dictValue(obj.dict, "Resources")); res != "" {
+		resources = res
+	}
+
+	if kids := dictValue(obj.dict, "Kids"); kids != "" {
+		for _, m := range refsInText.FindAllStringSubmatch(kids, -1) {
+			kid, _ := strconv.Atoi(m[1])
+			f.walk(kid, resources, pages, seen)
+		}
+		return
+	}
+
+	var content bytes.Buffer
+	contents := dictValue(obj.dict, "Contents")
+	for _, m := range refsInText.FindAllStringSubmatch(contents, -1) {
+		num, _ := strconv.Atoi(m[1])
+		if c := f.objects[num]; c != nil && c.stream != nil {
+			content.Write(c.stream)
+			content.WriteByte('\n')
+		}
+	}
+	*pages = append(*pages, pdfPage{content: content.Bytes(), fonts: f.pageFonts(resources)})
+}
+
+// pageFonts 读取资源字典中的字体
+func (f *pdfFile) pageFonts(resources string) map[string]*pdfFont {
+	fonts := make(map[string]*pdfFont)
+	fontDict := f.resolve(dictValue(resources, "Font"))
+	for _, m := range namedRefs.FindAllStringSubmatch(fontDict, -1) {
+		num, _ := strconv.Atoi(m[2])
+		fonts[m[1]] = f.font(num)
+	}
+	return fonts
+}
+
+// font 读取字体对象，按对象编号缓存
+func (f *pdfFile) font(n int) *pdfFont {
+	if font, ok := f.fonts[n]; ok {
+		return font
+	}
+	font := &pdfFont{codeBytes: 1}
+	f.fonts[n] = font
+
+	obj := f.objects[n]
+	if obj == nil {
+		return font
+	}
+	if strings.Contains(dictValue(obj.dict, "Subtype"), "Type0") {
+		font.codeBytes = 2
+	}
+	encoding := dictValue(obj.dict, "Encoding")
+	font.utf16 = strings.Contains(encoding, "UCS2") || strings.Contains(encoding, "UTF16")
+	if num, ok := ref(dictValue(obj.dict, "ToUnicode")); ok {
+		if cm := f.objects[num]; cm != nil && cm.stream != nil {
+			font.cmap, font.codeBytes = parseCMap(string(cm.stream), font.codeBytes)
+		}
+	}
+	return font
+}
+
+// pdfFont 把字符串中的字节解码为文字所需的信息
+type pdfFont struct {
+	codeBytes int               // 每个字符编码的字节数
+	utf16     bool              // UniGB-UCS2-H 等按UTF-16BE编码的预定义CMap
+	cmap      map[uint32]string // ToUnicode 映射
+}
+
+var (
+	codespacePattern = regexp.MustCompile(`begincodespacerange([\s\S]*?)endcodespacerange`)
+	bfcharPattern    = regexp.MustCompile(`beginbfchar([\s\S]*?)endbfchar`)
+	bfrangePattern   = regexp.MustCompile(`beginbfrange([\s\S]*?)endbfrange`)
+	hexPattern       = regexp.MustCompile(`<([0-9A-Fa-f]*)>`)
+	charPair         = regexp.MustCompile(`<([0-9A-Fa-f]+)>\s*<([0-9A-Fa-f]*)>`)
+	rangeEntry       = regexp.MustCompile(`<([0-9A-Fa-f]+)>\s*<([0-9A-Fa-f]+)>\s*(<[0-9A-Fa-f]*>|\[[^\]]*\])`)
+)
+
+// parseCMap 解析 ToUnicode CMap，返回映射和编码字节数
+func parseCMap(text string, codeBytes int) (map[uint32]string, int) {
+	if m := codespacePattern.FindStringSubmatch(text); m != nil {
+		if h := hexPattern.FindStringSubmatch(m[1]); h != nil && len(h[1]) >= 2 {
+			codeBytes = len(h[1]) / 2
+		}
+	}
+
+	cmap := make(map[uint32]string)
+	for _, block := range bfcharPattern.FindAllStringSubmatch(text, -1) {
+		for _, p := range charPair.FindAllStringSubmatch(block[1], -1) {
+			cmap[hexCode(p[1])] = utf16Hex(p[2])
+		}
+	}
+	// 映射总数超过 maxCMapEntries 后忽略其余的范围
+	for _, block := range bfrangePattern.FindAllStringSubmatch(text, -1) {
+		for _, e := range rangeEntry.FindAllStringSubmatch(block[1], -1) {
+			lo, hi := hexCode(e[1]), hexCode(e[2])
+			if hi < lo || hi-lo > 0xFFFF || len(cmap)+int(hi-lo) >= maxCMapEntries {
+				continue
+			}
+			if strings.HasPrefix(e[3], "[") {
+				for i, dst := range hexPattern.FindAllStringSubmatch(e[3], -1) {
+					cmap[lo+uint32(i)] = utf16Hex(dst[1])
+				}
+				continue
+			}
+			units := utf16Units(strings.Trim(e[3], "<>"))
+			if len(units) == 0 {
+				continue
+			}
+			// 按偏移量循环，hi 为 0xFFFFFFFF 时 code++ 会回绕而无法结束
+			for off := uint32(0); off <= hi-lo; off++ {
+				shifted := append([]uint16(nil), units...)
+				shifted[len(shifted)-1] += uint16(off)
+				cmap[lo+off] = string(utf16.Decode(shifted))
+			}
+		}
+	}
+	return cmap, codeBytes
+}
+
+// hexCode 将十六进制编码转换为整数
+func hexCode(h string) uint32 {
+	v, _ := strconv.ParseUint(h, 16, 32)
+	return uint32(v)
+}
+
+// utf16Units 将十六进制的UTF-16BE转换为码元
+func utf16Units(h string) []uint16 {
+	var units []uint16
+	for i := 0; i+4 <= len(h); i += 4 {
+		v, _ := strconv.ParseUint(h[i:i+4], 16, 16)
+		units = append(units, uint16(v))
+	}
+	return units
+}
+
+// utf16Hex 将十六进制的UTF-16BE转换为文字
+func utf16Hex(h string) string {
+	return string(utf16.Decode(utf16Units(h)))
+}
+
+// decode 将字符串中的字节解码为文字
+func (font *pdfFont) decode(b []byte) string {
+	if font == nil {
+		return latin1(b)
+	}
+	if font.cmap != nil {
+		var sb strings.Builder
+		for i := 0; i+font.codeBytes <= len(b); i += font.codeBytes {
+			var code uint32
+			for _, c := range b[i : i+font.codeBytes] {
+				code = code<<8 | uint32(c)
+			}
+			if s, ok := font.cmap[code]; ok {
+				sb.WriteString(s)
+			} else if font.codeBytes == 1 {
+				sb.WriteByte(b[i])
+			}
+		}
+		return sb.String()
+	}
+	if font.utf16 {
+		units := make([]uint16, 0, len(b)/2)
+		for i := 0; i+1 < len(b); i += 2 {
+			units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+	return latin1(b)
+}
+
+// latin1 单字节编码按 Latin-1 近似 WinAnsiEncoding
+func latin1(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// PDFText 提取PDF中的文字，按页面顺序输出，每个文本行一行
+func PDFText(data []byte) (string, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("%PDF")) {
+		return "", i18n.Errorf("不是PDF文件")
+	}
+	f, decodeErr := parsePDF(data)
+	if f == nil {
+		return "", decodeErr
+	}
+	if strings.Contains(string(data), "/Encrypt") {
+		return "", i18n.Errorf("不支持加密的PDF")
+	}
+
+	var out strings.Builder
+	for _, page := range f.pages() {
+		extractText(&out, page.content, page.fonts)
+		out.WriteString("\n")
+	}
+
+	text := strings.TrimSpace(out.String())
+	if text == "" && decodeErr != nil {
+		return "", decodeErr
+	}
+	if text == "" {
+		return "", i18n.Errorf("PDF中没有可提取的文字，可能是扫描件")
+	}
+	return text, nil
+}
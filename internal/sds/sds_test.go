@@ -0,0 +1,203 @@
+package sds
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPDFText(t *testing.T) {
+	for _, path := range []string{"testdata/64-17-5.pdf", "../standin/fixtures/msds/7664-93-9.pdf"} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		text, err := PDFText(data)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		doc := Parse(text)
+		for _, n := range []int{SectionFireFighting, SectionStorage, SectionProperties} {
+			if doc.Section(n) == "" {
+				t.Errorf("%s: 第%d部分为空\n%s", path, n, text)
+			}
+		}
+	}
+}
+
+func TestPDFTextChinese(t *testing.T) {
+	text, err := ReadFile("testdata/64-17-5.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := Parse(text)
+	if got := doc.CAS(); got != "64-17-5" {
+		t.Errorf("CAS = %q", got)
+	}
+	if got, want := doc.Section(SectionProperties), "外观与性状：无色液体，有酒香。\n相对密度(水=1)：0.79"; got != want {
+		t.Errorf("第九部分 = %q, want %q", got, want)
+	}
+	if got, want := doc.Storage(), "储存注意事项：储存于阴凉、通风的库房。远离火种、热源。"; got != want {
+		t.Errorf("Storage = %q, want %q", got, want)
+	}
+	if got := doc.Section(SectionDisposal); got != "废弃处置方法：用焚烧法处置。" {
+		t.Errorf("第十三部分 = %q", got)
+	}
+}
+
+func TestPDFTextErrors(t *testing.T) {
+	if _, err := PDFText([]byte("not a pdf")); err == nil {
+		t.Error("非PDF文件应返回错误")
+	}
+	if _, err := PDFText([]byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n")); err == nil {
+		t.Error("没有文字的PDF应返回错误")
+	}
+}
+
+func TestPDFTextMalformedObjectStream(t *testing.T) {
+	for _, header := range []string{"5 -9 ", "5 0 6 -20 ", "5 99999999999999999999 ", "5 9223372036854775807 "} {
+		body := header + "<< /Type /Page >>"
+		pdf := "%PDF-1.5\n1 0 obj\n<< /Type /ObjStm /N " + strconv.Itoa(strings.Count(header, " ")/2) +
+			" /First " + strconv.Itoa(len(header)) + " /Length " + strconv.Itoa(len(body)) +
+			" >>\nstream\n" + body + "\nendstream\nendobj\n"
+		// 只要求不 panic，没有文字时返回错误
+		if _, err := PDFText([]byte(pdf)); err == nil {
+			t.Errorf("%q: 应返回错误", header)
+		}
+	}
+}
+
+func TestParseCMapRangeAtLimit(t *testing.T) {
+	// 范围终点为 0xFFFFFFFF 时计数不能回绕
+	cmap, _ := parseCMap("1 beginbfrange\n<FFFFFFFE> <FFFFFFFF> <0041>\nendbfrange", 1)
+	if cmap[0xFFFFFFFE] != "A" || cmap[0xFFFFFFFF] != "B" || len(cmap) != 2 {
+		t.Errorf("cmap = %v", cmap)
+	}
+}
+
+func TestParseCMapTotalLimit(t *testing.T) {
+	// 200 个各含 65536 个编码的范围，只有几KB，展开后有一千多万个映射
+	var b strings.Builder
+	b.WriteString("200 beginbfrange\n")
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&b, "<%04X0000> <%04XFFFF> <0041>\n", i, i)
+	}
+	b.WriteString("endbfrange")
+	cmap, _ := parseCMap(b.String(), 4)
+	if len(cmap) > maxCMapEntries {
+		t.Errorf("映射数 %d 超过 %d", len(cmap), maxCMapEntries)
+	}
+}
+
+// flatePDF 只有一个 FlateDecode 流的PDF
+func flatePDF(stream []byte) []byte {
+	return []byte("%PDF-1.4\n1 0 obj\n<< /Filter /FlateDecode /Length " + strconv.Itoa(len(stream)) +
+		" >>\nstream\n" + string(stream) + "\nendstream\nendobj\n")
+}
+
+func TestPDFTextZlibBomb(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := zlib.NewWriterLevel(&buf, zlib.BestCompression)
+	zeros := make([]byte, 1<<20)
+	for i := 0; i < maxDecodedSize>>20+1; i++ {
+		w.Write(zeros)
+	}
+	w.Close()
+
+	_, err := PDFText(flatePDF(buf.Bytes()))
+	if err != errTooLarge {
+		t.Errorf("err = %v, want %v", err, errTooLarge)
+	}
+}
+
+func TestPDFTextCorruptStream(t *testing.T) {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write([]byte("BT (text) Tj ET"))
+	w.Close()
+	// 流末尾截断时保留已解压的文字
+	if text, err := PDFText(flatePDF(buf.Bytes()[:buf.Len()-4])); err != nil || text != "text" {
+		t.Errorf("截断的流: %q %v", text, err)
+	}
+	// 无法解压的流返回解压错误，而不是 "可能是扫描件"
+	_, err := PDFText(flatePDF([]byte("x\x9c\xff\xff\xff\xff")))
+	if err == nil || !strings.Contains(err.Error(), "解压") {
+		t.Errorf("损坏的流: %v", err)
+	}
+}
+
+// FuzzPDFText 几乎每个变异都会覆盖新的分支，默认的最小化会占满时间，
+// 建议加 -fuzzminimizetime=0 运行
+func FuzzPDFText(f *testing.F) {
+	for _, path := range []string{"testdata/64-17-5.pdf", "../standin/fixtures/msds/7664-93-9.pdf"} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		PDFText(data)
+	})
+}
+
+func TestParse(t *testing.T) {
+	text, err := ReadFile("testdata/108-88-3.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := Parse(text)
+	if got := doc.CAS(); got != "108-88-3" {
+		t.Errorf("CAS = %q", got)
+	}
+	// "See section 8." 不是标题
+	if got := doc.Section(SectionStorage); !strings.Contains(got, "See section 8.") {
+		t.Errorf("第七部分 = %q", got)
+	}
+	if got, want := doc.Storage(), "Conditions for safe storage: keep container tightly closed in a cool place."; got != want {
+		t.Errorf("Storage = %q, want %q", got, want)
+	}
+	if got := doc.Section(SectionFireFighting); got != "Use foam, dry chemical or CO2." {
+		t.Errorf("第五部分 = %q", got)
+	}
+}
+
+func TestReadFileGBK(t *testing.T) {
+	text, err := ReadFile("testdata/67-64-1.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := Parse(text)
+	if doc.CAS() != "67-64-1" || doc.Section(SectionProperties) != "无色透明易流动液体。" {
+		t.Errorf("GBK解码失败: %q", text)
+	}
+}
+
+func TestHeadingNumber(t *testing.T) {
+	cases := map[string]int{
+		"SECTION 9: Physical and chemical properties": 9,
+		"第十六部分 其他信息":                                  16,
+		"第 3 部分 成分/组成信息":                              3,
+		"9. 理化特性":                                     9,
+		"13 Disposal considerations":                  13,
+		"9. Keep away from heat":                      0,
+		"1.84 g/cm3":                                  0,
+	}
+	for line, want := range cases {
+		if got := headingNumber(line); got != want {
+			t.Errorf("headingNumber(%q) = %d, want %d", line, got, want)
+		}
+	}
+}
+
+func TestValidCAS(t *testing.T) {
+	for cas, want := range map[string]bool{"7664-93-9": true, "64-17-5": true, "64-17-6": false, "1.84-1": false} {
+		if got := ValidCAS(cas); got != want {
+			t.Errorf("ValidCAS(%q) = %v", cas, got)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+// Package sds 读取供应商提供的安全技术说明书(SDS/MSDS)，按GB/T 16483和GHS的16部分结构拆分
+package sds
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"cas.mod/internal/i18n"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// 常用部分的编号
+const (
+	SectionIdentification = 1
+	SectionComposition    = 3
+	SectionFireFighting   = 5
+	SectionStorage        = 7
+	SectionProperties     = 9
+	SectionStability      = 10
+	SectionDisposal       = 13
+)
+
+// sectionKeywords 各部分标题中的关键词，用于识别 "9. 理化特性" 这类只有编号的标题
+var sectionKeywords = [17][]string{
+	1:  {"identification", "标识", "鉴别"},
+	2:  {"hazard", "危险性"},
+	3:  {"composition", "成分", "组成"},
+	4:  {"first aid", "first-aid", "急救"},
+	5:  {"fire", "消防", "灭火"},
+	6:  {"accidental", "release", "泄漏"},
+	7:  {"handling", "storage", "操作", "储存", "贮存"},
+	8:  {"exposure", "protection", "接触控制", "个体防护"},
+	9:  {"physical", "理化"},
+	10: {"stability", "reactivity", "稳定性"},
+	11: {"toxicolog", "毒理"},
+	12: {"ecolog", "生态"},
+	13: {"disposal", "废弃"},
+	14: {"transport", "运输"},
+	15: {"regulatory", "法规"},
+	16: {"other", "其他"},
+}
+
+var (
+	// sectionHeading "SECTION 9"、"Section 9:" 形式的标题
+	sectionHeading = regexp.MustCompile(`(?i)^section\s*(\d{1,2})\b`)
+	// partHeading "第九部分"、"第 9 部分" 形式的标题
+	partHeading = regexp.MustCompile(`^第\s*([一二三四五六七八九十]{1,3}|\d{1,2})\s*部分`)
+	// numberedHeading "9. 理化特性"、"9 Physical and chemical properties" 形式的标题，须含对应关键词
+	numberedHeading = regexp.MustCompile(`^(\d{1,2})\s*[.、:：)]?\s*(\D.*)$`)
+)
+
+// chineseNumber 将 "十六" 等中文数字转换为整数
+func chineseNumber(s string) int {
+	digits := map[rune]int{'一': 1, '二': 2, '三': 3, '四': 4, '五': 5, '六': 6, '七': 7, '八': 8, '九': 9}
+	n, tens := 0, false
+	for _, r := range s {
+		if r == '十' {
+			tens = true
+			if n == 0 {
+				n = 1
+			}
+			n *= 10
+			continue
+		}
+		n += digits[r]
+	}
+	if tens && n == 0 {
+		n = 10
+	}
+	return n
+}
+
+// headingNumber 判断一行是否为部分标题，返回部分编号
+func headingNumber(line string) int {
+	if m := sectionHeading.FindStringSubmatch(line); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n
+	}
+	if m := partHeading.FindStringSubmatch(line); m != nil {
+		if n, err := strconv.Atoi(m[1]); err == nil {
+			return n
+		}
+		return chineseNumber(m[1])
+	}
+	if m := numberedHeading.FindStringSubmatch(line); m != nil && utf8.RuneCountInString(line) <= 60 {
+		n, _ := strconv.Atoi(m[1])
+		if n < 1 || n > 16 {
+			return 0
+		}
+		title := strings.ToLower(m[2])
+		for _, kw := range sectionKeywords[n] {
+			if strings.Contains(title, kw) {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+// Document 按16部分拆分的SDS
+type Document struct {
+	Sections [17]string // 下标为部分编号，0 为第一部分之前的内容
+}
+
+// Parse 按部分标题拆分SDS文字。标题编号须递增，正文中引用其他部分的行不会被误认为标题
+func Parse(text string) Document {
+	var doc Document
+	var parts [17][]string
+	current := 0
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if n := headingNumber(line); n > current && n <= 16 {
+			current = n
+			continue
+		}
+		parts[current] = append(parts[current], line)
+	}
+	for i, lines := range parts {
+		doc.Sections[i] = strings.Join(lines, "\n")
+	}
+	return doc
+}
+
+// Section 返回指定部分的文字，编号超出范围时返回空字符串
+func (d Document) Section(n int) string {
+	if n < 0 || n >= len(d.Sections) {
+		return ""
+	}
+	return d.Sections[n]
+}
+
+// storageMarkers 第7部分中储存小节的开头
+var storageMarkers = []string{"储存注意事项", "储存条件", "贮存注意事项", "conditions for safe storage", "storage"}
+
+// Storage 第7部分中的储存小节，找不到小节标题时返回整个第7部分
+func (d Document) Storage() string {
+	section := d.Section(SectionStorage)
+	lines := strings.Split(section, "\n")
+	for i, line := range lines {
+		lower := strings.ToLower(line)
+		for _, marker := range storageMarkers {
+			if strings.HasPrefix(lower, marker) {
+				return strings.Join(lines[i:], "\n")
+			}
+		}
+	}
+	return section
+}
+
+// casPattern CAS号
+var casPattern = regexp.MustCompile(`\b(\d{2,7})-(\d{2})-(\d)\b`)
+
+// ValidCAS 校验CAS号的校验位
+func ValidCAS(cas string) bool {
+	m := casPattern.FindStringSubmatch(cas)
+	if m == nil || m[0] != cas {
+		return false
+	}
+	digits := m[1] + m[2]
+	sum := 0
+	for i := range digits {
+		sum += int(digits[len(digits)-1-i]-'0') * (i + 1)
+	}
+	return sum%10 == int(m[3][0]-'0')
+}
+
+// FindCAS 返回文字中第一个校验正确的CAS号
+func FindCAS(text string) string {
+	for _, m := range casPattern.FindAllString(text, -1) {
+		if ValidCAS(m) {
+			return m
+		}
+	}
+	return ""
+}
+
+// CAS 返回SDS所述化学品的CAS号，依次查找第1部分、第3部分和全文
+func (d Document) CAS() string {
+	for _, text := range []string{d.Section(SectionIdentification), d.Section(SectionComposition), strings.Join(d.Sections[:], "\n")} {
+		if cas := FindCAS(text); cas != "" {
+			return cas
+		}
+	}
+	return ""
+}
+
+// ReadFile 读取SDS文件的文字：.pdf 提取文字，其余按文本读取，不是有效UTF-8时按GBK解码
+func ReadFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".pdf") {
+		text, err := PDFText(data)
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", path, err)
+		}
+		return text, nil
+	}
+	if utf8.Valid(data) {
+		return strings.TrimPrefix(string(data), "\uFEFF"), nil
+	}
+	text, err := simplifiedchinese.GBK.NewDecoder().String(string(data))
+	if err != nil {
+		return "", i18n.Errorf("%s: 无法识别的编码: %v", path, err)
+	}
+	return text, nil
+}
@@ -0,0 +1,162 @@
+// Package server 提供CAS号查询的本地HTTP JSON接口
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+
+	"cas.mod/internal/app"
+	"cas.mod/internal/i18n"
+	"cas.mod/internal/sds"
+)
+
+// 默认参数
+const (
+	DefaultMaxBatch = 500 // 单次批量查询的CAS号上限
+	defaultWorkers  = 4   // 批量查询的并发数
+)
+
+// Server 查询接口，Provider 一般为带缓存的数据源查询链
+type Server struct {
+	Provider app.Provider
+	MaxBatch int // 为 0 时使用 DefaultMaxBatch
+	Workers  int // 为 0 时使用默认并发数
+}
+
+// BatchRequest POST /batch 的请求体
+type BatchRequest struct {
+	CAS []string `json:"cas"`
+}
+
+// BatchResult 批量查询中一个CAS号的结果
+type BatchResult struct {
+	CAS   string            `json:"cas"`
+	Info  *app.ChemicalInfo `json:"info,omitempty"`
+	Error string            `json:"error,omitempty"`
+}
+
+// Handler 返回注册了全部接口的路由
+//
+//	GET  /health     服务状态
+//	GET  /cas/{cas}  查询单个CAS号，返回 ChemicalInfo
+//	POST /batch      批量查询，请求体为 {"cas": [...]} 或CAS号数组
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.health)
+	mux.HandleFunc("GET /cas/{cas}", s.lookup)
+	mux.HandleFunc("POST /batch", s.batch)
+	return mux
+}
+
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "providers": s.Provider.Name()})
+}
+
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) {
+	cas := strings.TrimSpace(r.PathValue("cas"))
+	if !sds.ValidCAS(cas) {
+		writeError(w, http.StatusBadRequest, i18n.Errorf("CAS号格式或校验位错误: %s", cas))
+		return
+	}
+
+	info, err := s.Provider.Lookup(cas)
+	switch {
+	case errors.Is(err, app.ErrNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case err != nil:
+		slog.Warn("查询失败", "cas", cas, "err", err)
+		writeError(w, http.StatusBadGateway, err)
+	default:
+		writeJSON(w, http.StatusOK, info)
+	}
+}
+
+func (s *Server) batch(w http.ResponseWriter, r *http.Request) {
+	maxBatch := s.MaxBatch
+	if maxBatch <= 0 {
+		maxBatch = DefaultMaxBatch
+	}
+
+	numbers, err := decodeBatch(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(numbers) > maxBatch {
+		writeError(w, http.StatusRequestEntityTooLarge, i18n.Errorf("单次最多查询 %d 个CAS号，收到 %d 个", maxBatch, len(numbers)))
+		return
+	}
+
+	workers := s.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	results := make([]BatchResult, len(numbers))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = s.lookupOne(numbers[i])
+			}
+		}()
+	}
+	for i := range numbers {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	writeJSON(w, http.StatusOK, map[string][]BatchResult{"results": results})
+}
+
+// decodeBatch 解析批量请求，接受 {"cas": [...]} 或直接的数组
+func decodeBatch(w http.ResponseWriter, r *http.Request) ([]string, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&raw); err != nil {
+		return nil, i18n.Errorf("请求体不是有效的JSON: %v", err)
+	}
+	var req BatchRequest
+	if err := json.Unmarshal(raw, &req.CAS); err != nil {
+		if err := json.Unmarshal(raw, &req); err != nil {
+			return nil, i18n.Errorf("请求体应为 {\"cas\": [...]} 或CAS号数组: %v", err)
+		}
+	}
+	return req.CAS, nil
+}
+
+func (s *Server) lookupOne(cas string) BatchResult {
+	cas = strings.TrimSpace(cas)
+	result := BatchResult{CAS: cas}
+	if !sds.ValidCAS(cas) {
+		result.Error = i18n.Sprintf("CAS号格式或校验位错误: %s", cas)
+		return result
+	}
+	info, err := s.Provider.Lookup(cas)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Info = info
+	return result
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		slog.Error("写入响应失败", "err", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"cas.mod/internal/app"
+	"cas.mod/internal/standin"
+)
+
+// countingProvider 记录每个CAS号的实际查询次数
+type countingProvider struct {
+	app.Provider
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (cp *countingProvider) Lookup(cas string) (*app.ChemicalInfo, error) {
+	cp.mu.Lock()
+	cp.calls[cas]++
+	cp.mu.Unlock()
+	if cas == "7440-23-5" {
+		return nil, errors.New("连接超时")
+	}
+	return cp.Provider.Lookup(cas)
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, *countingProvider) {
+	site := standin.NewServer()
+	t.Cleanup(site.Close)
+
+	counting := &countingProvider{
+		Provider: &app.IchemistryProvider{BaseURL: site.URL, Client: site.Client()},
+		calls:    make(map[string]int),
+	}
+	srv := &Server{Provider: &app.CachedProvider{Provider: counting}, MaxBatch: 3}
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	return ts, counting
+}
+
+func TestLookup(t *testing.T) {
+	ts, counting := newTestServer(t)
+
+	tests := []struct {
+		cas    string
+		status int
+	}{
+		{"7664-93-9", http.StatusOK},
+		{"7664-93-9", http.StatusOK}, // 缓存
+		{"7664-93-8", http.StatusBadRequest},
+		{"67-64-1", http.StatusNotFound},
+		{"67-64-1", http.StatusNotFound}, // 未找到也缓存
+		{"7440-23-5", http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(ts.URL + "/cas/" + tt.cas)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body map[string]any
+		json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: 状态码 %d, want %d (%v)", tt.cas, resp.StatusCode, tt.status, body)
+		}
+		if tt.status == http.StatusOK && body["ChemicalFormula"] != "H2SO4" {
+			t.Errorf("%s: %v", tt.cas, body)
+		}
+	}
+	if counting.calls["7664-93-9"] != 1 || counting.calls["67-64-1"] != 1 {
+		t.Errorf("查询次数 = %v", counting.calls)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	ts, _ := newTestServer(t)
+
+	resp, err := http.Post(ts.URL+"/batch", "application/json", strings.NewReader(`{"cas": ["7664-93-9", "bad", "1002-16-0"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body struct{ Results []BatchResult }
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Results) != 3 {
+		t.Fatalf("Results = %+v", body.Results)
+	}
+	if r := body.Results[0]; r.CAS != "7664-93-9" || r.Info == nil || r.Info.ChemicalFormula != "H2SO4" {
+		t.Errorf("Results[0] = %+v", r)
+	}
+	if r := body.Results[1]; r.Info != nil || r.Error == "" {
+		t.Errorf("Results[1] = %+v", r)
+	}
+	if r := body.Results[2]; r.Info == nil || r.Info.ChemicalFormula != "C5H11NO3" {
+		t.Errorf("Results[2] = %+v", r)
+	}
+
+	for body, status := range map[string]int{
+		`["7664-93-9"]`:        http.StatusOK,
+		`{"cas": "7664-93-9"}`: http.StatusBadRequest,
+		`not json`:             http.StatusBadRequest,
+		`["64-17-5","64-17-5","64-17-5","64-17-5"]`: http.StatusRequestEntityTooLarge,
+	} {
+		resp, err := http.Post(ts.URL+"/batch", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != status {
+			t.Errorf("%s: 状态码 %d, want %d", body, resp.StatusCode, status)
+		}
+	}
+}
+
+func TestHealth(t *testing.T) {
+	ts, _ := newTestServer(t)
+	resp, err := http.Get(ts.URL + "/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body map[string]string
+	json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != http.StatusOK || body["status"] != "ok" || body["providers"] != "ichemistry" {
+		t.Errorf("health = %d %v", resp.StatusCode, body)
+	}
+	resp, err = http.Get(ts.URL + "/cas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("未注册的路径应返回 404，得到 %d", resp.StatusCode)
+	}
+}
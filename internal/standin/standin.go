@@ -0,0 +1,114 @@
+// Package standin 提供各数据源网站的本地替身，按CAS号返回录制的HTML页面，
+// 使解析器和批量流程可以在没有网络的环境下运行和测试。
+package standin
+
+import (
+	"embed"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+// 各数据源在 fixtures 下的目录名
+const (
+	Ichemistry = "ichemistry" // www.ichemistry.cn 详情页，GBK编码
+	Chemsrc    = "chemsrc"    // www.chemsrc.com 详情页
+	Search     = "search"     // search.ichemistry.cn 搜索结果页
+)
+
+// imageHost 录制页面中结构式图片的主机，替身返回页面时改写为自身地址
+const imageHost = "http://img.ichemistry.cn"
+
+// Fixture 读取录制的页面（UTF-8），不存在时返回错误
+func Fixture(provider, cas string) (string, error) {
+	data, err := fixtures.ReadFile(fmt.Sprintf("fixtures/%s/%s.html", provider, cas))
+	if err != nil {
+		return "", fmt.Errorf("没有 %s 的 %s 页面: %v", provider, cas, err)
+	}
+	return string(data), nil
+}
+
+// Handler 返回模拟各数据源的路由：
+//
+//	/chemistry/{cas}.htm  www.ichemistry.cn
+//	/cas/{cas}.html       www.chemsrc.com
+//	/?keys={cas}          search.ichemistry.cn
+//	/structure/{file}     img.ichemistry.cn 结构式图片
+//	/msds/{file}          www.ichemistry.cn 安全技术说明书
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /chemistry/{page}", func(w http.ResponseWriter, r *http.Request) {
+		cas, ok := strings.CutSuffix(r.PathValue("page"), ".htm")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		serveFixture(w, r, Ichemistry, cas, true)
+	})
+	mux.HandleFunc("GET /cas/{page}", func(w http.ResponseWriter, r *http.Request) {
+		cas, ok := strings.CutSuffix(r.PathValue("page"), ".html")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		serveFixture(w, r, Chemsrc, cas, false)
+	})
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		serveFixture(w, r, Search, r.URL.Query().Get("keys"), false)
+	})
+	mux.HandleFunc("GET /structure/{file}", func(w http.ResponseWriter, r *http.Request) {
+		serveFile(w, r, "images", r.PathValue("file"))
+	})
+	mux.HandleFunc("GET /msds/{file}", func(w http.ResponseWriter, r *http.Request) {
+		serveFile(w, r, "msds", r.PathValue("file"))
+	})
+	return mux
+}
+
+// serveFile 返回 fixtures 下指定目录中的文件
+func serveFile(w http.ResponseWriter, r *http.Request, dir, file string) {
+	data, err := fixtures.ReadFile("fixtures/" + dir + "/" + file)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", mime.TypeByExtension(path.Ext(file)))
+	w.Write(data)
+}
+
+// NewServer 启动本地替身服务器，使用完毕后需调用 Close
+func NewServer() *httptest.Server {
+	return httptest.NewServer(Handler())
+}
+
+// serveFixture 返回录制的页面，gbk 为 true 时按真实站点的编码输出
+func serveFixture(w http.ResponseWriter, r *http.Request, provider, cas string, gbk bool) {
+	page, err := Fixture(provider, cas)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	page = strings.ReplaceAll(page, imageHost, "http://"+r.Host)
+
+	if !gbk {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(page))
+		return
+	}
+
+	encoded, err := simplifiedchinese.GBK.NewEncoder().String(page)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=gb2312")
+	w.Write([]byte(encoded))
+}
@@ -0,0 +1,57 @@
+package standin
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestHandler(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	tests := []struct {
+		path   string
+		status int
+		gbk    bool
+		want   string
+	}{
+		{path: "/chemistry/7664-93-9.htm", status: http.StatusOK, gbk: true, want: "分子式"},
+		{path: "/cas/64-17-5.html", status: http.StatusOK, want: "密度"},
+		{path: "/?keys=7664-93-9&onlymy=0&types=2&tz=1", status: http.StatusOK, want: "container-right"},
+		{path: "/chemistry/99999-99-9.htm", status: http.StatusNotFound},
+		{path: "/chemistry/7664-93-9.html", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Get(server.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Fatalf("状态码 = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var body io.Reader = resp.Body
+			if tt.gbk {
+				body = simplifiedchinese.GBK.NewDecoder().Reader(resp.Body)
+			}
+			data, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(data), tt.want) {
+				t.Errorf("页面中没有 %q", tt.want)
+			}
+		})
+	}
+}
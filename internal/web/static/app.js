@@ -0,0 +1,121 @@
+// 默认勾选的列
+const defaultColumns = ['化学式'];
+
+const stateText = {
+  queued: '排队中',
+  running: '查询中',
+  done: '已完成',
+  failed: '失败',
+};
+
+// 界面文字的译文，键为中文原文
+let messages = {};
+
+function $(id) {
+  return document.getElementById(id);
+}
+
+// t 返回 key 的译文，依次用 args 替换其中的 %s、%d
+function t(key, ...args) {
+  let text = messages[key] || key;
+  for (const arg of args) {
+    text = text.replace(/%[sd]/, arg);
+  }
+  return text;
+}
+
+async function loadMessages() {
+  const resp = await fetch('api/messages');
+  const body = await resp.json();
+  messages = body.messages;
+  document.documentElement.lang = body.lang;
+  for (const el of document.querySelectorAll('[data-i18n]')) {
+    el.textContent = t(el.dataset.i18n);
+  }
+}
+
+async function loadColumns() {
+  const resp = await fetch('api/columns');
+  const columns = await resp.json();
+  const box = $('columns');
+  for (const column of columns) {
+    const label = document.createElement('label');
+    const input = document.createElement('input');
+    input.type = 'checkbox';
+    input.name = 'column';
+    input.value = column;
+    input.checked = defaultColumns.includes(column);
+    label.append(input, ' ', column);
+    box.append(label);
+  }
+}
+
+function showError(el, message) {
+  el.textContent = message;
+  el.hidden = !message;
+}
+
+async function submit(event) {
+  event.preventDefault();
+  showError($('form-error'), '');
+  const resp = await fetch('api/jobs', { method: 'POST', body: new FormData($('form')) });
+  const body = await resp.json();
+  if (!resp.ok) {
+    showError($('form-error'), body.error);
+    return;
+  }
+  $('upload').hidden = true;
+  $('job').hidden = false;
+  $('job-name').textContent = body.name;
+  poll(body.id);
+}
+
+async function poll(id) {
+  const resp = await fetch('api/jobs/' + id);
+  const job = await resp.json();
+  if (!resp.ok) {
+    showError($('job-error'), job.error);
+    return;
+  }
+
+  const p = job.progress;
+  $('bar').max = Math.max(p.Total, 1);
+  $('bar').value = p.Done;
+  $('job-state').textContent = t('%s: %d / %d 行，失败 %d 行，写入 %d 个单元格',
+    stateText[job.state] ? t(stateText[job.state]) : job.state, p.Done, p.Total, p.Failed, p.Written);
+
+  if (job.state === 'failed') {
+    showError($('job-error'), job.error);
+    return;
+  }
+  if (job.state !== 'done') {
+    setTimeout(() => poll(id), 1000);
+    return;
+  }
+
+  $('download-link').href = 'api/jobs/' + id + '/download';
+  $('download').hidden = false;
+  renderDiff(job.changes || [], job.conflicts || []);
+}
+
+function row(cells) {
+  const tr = document.createElement('tr');
+  for (const text of cells) {
+    const td = document.createElement('td');
+    td.textContent = text;
+    tr.append(td);
+  }
+  return tr;
+}
+
+function renderDiff(changes, conflicts) {
+  $('changes-title').textContent = t('已写入 %d 个单元格', changes.length);
+  $('conflicts-title').textContent = t('已有值未覆盖 %d 个单元格', conflicts.length);
+  $('changes').replaceChildren(...changes.map(c => row([c.row, c.column, c.new, c.provider || c.source || ''])));
+  $('conflicts').replaceChildren(...conflicts.map(c => row([c.row, c.column, c.old || '', c.new])));
+  $('diff').hidden = false;
+}
+
+$('form').addEventListener('submit', submit);
+loadMessages();
+loadColumns();
@@ -0,0 +1,424 @@
+// Package web 提供上传工作表、选择填充列、查看进度和差异并下载结果的网页界面，
+// 静态资源嵌入在程序中
+package web
+
+import (
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"cas.mod/internal/app"
+	"cas.mod/internal/i18n"
+)
+
+//go:embed static
+var static embed.FS
+
+// 上传和任务的限制
+const (
+	maxUploadSize = 50 << 20       // 上传文件的大小上限
+	jobRetention  = 24 * time.Hour // 完成的任务及其文件的保留时间
+)
+
+// 任务状态
+const (
+	StateQueued  = "queued"
+	StateRunning = "running"
+	StateDone    = "done"
+	StateFailed  = "failed"
+)
+
+// UI 网页界面。任务依次执行，同一时间只查询一个工作表
+type UI struct {
+	Provider    app.Provider
+	ProviderFor func(columns []string) app.Provider // 按任务选择的列返回数据源，为空时使用 Provider
+	Dir         string                              // 上传文件和结果的保存目录，为空时使用系统临时目录
+	Provenance  app.ProvenanceOptions               // 自动填充值的来源标记
+
+	once  sync.Once
+	queue chan *job
+	mu    sync.Mutex
+	jobs  map[string]*job
+}
+
+// job 一次上传及其填充任务
+type job struct {
+	ID      string
+	Name    string   // 上传的文件名
+	Columns []string // 选择填充的列
+	Created time.Time
+	dir     string
+	input   string
+	output  string
+
+	mu        sync.Mutex
+	state     string
+	progress  app.EnrichProgress
+	changes   []app.CellUpdate
+	conflicts []app.CellUpdate
+	err       string
+	finished  time.Time
+}
+
+// jobStatus GET /api/jobs/{id} 的响应
+type jobStatus struct {
+	ID        string             `json:"id"`
+	Name      string             `json:"name"`
+	Columns   []string           `json:"columns"`
+	State     string             `json:"state"`
+	Progress  app.EnrichProgress `json:"progress"`
+	Changes   []cellChange       `json:"changes,omitempty"`
+	Conflicts []cellChange       `json:"conflicts,omitempty"`
+	Error     string             `json:"error,omitempty"`
+}
+
+// cellChange 差异中的一个单元格
+type cellChange struct {
+	Row      int    `json:"row"`
+	Column   string `json:"column"`
+	Old      string `json:"old,omitempty"`
+	New      string `json:"new"`
+	Source   string `json:"source,omitempty"`
+	Provider string `json:"provider,omitempty"`
+}
+
+// Handler 返回网页界面和任务接口的路由
+//
+//	GET  /                        页面
+//	GET  /api/columns             可以选择的列
+//	GET  /api/messages            界面文字的当前语言译文
+//	POST /api/jobs                上传工作表，表单字段 file 和 column (可多个)
+//	GET  /api/jobs/{id}           任务进度，完成后包含差异
+//	GET  /api/jobs/{id}/download  下载填充后的工作表
+func (ui *UI) Handler() http.Handler {
+	ui.once.Do(ui.start)
+
+	mux := http.NewServeMux()
+	assets, _ := fs.Sub(static, "static")
+	mux.Handle("GET /", http.FileServerFS(assets))
+	mux.HandleFunc("GET /api/columns", ui.columns)
+	mux.HandleFunc("GET /api/messages", ui.messages)
+	mux.HandleFunc("POST /api/jobs", ui.create)
+	mux.HandleFunc("GET /api/jobs/{id}", ui.status)
+	mux.HandleFunc("GET /api/jobs/{id}/download", ui.download)
+	return mux
+}
+
+// start 启动依次执行任务的后台协程
+func (ui *UI) start() {
+	ui.jobs = make(map[string]*job)
+	ui.queue = make(chan *job, 64)
+	go func() {
+		for j := range ui.queue {
+			ui.run(j)
+		}
+	}()
+}
+
+func (ui *UI) columns(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, app.EnrichColumns)
+}
+
+// uiMessages 网页界面中的文字，index.html 的 data-i18n 属性和 app.js 的 t() 以此为键
+var uiMessages = []string{
+	"试剂信息填充",
+	"选择工作表 (.xlsx)",
+	"要填充的列",
+	"上传并开始",
+	"下载填充后的工作表",
+	"已写入 %d 个单元格",
+	"已有值未覆盖 %d 个单元格",
+	"行", "列", "新值", "来源", "当前值", "查询到的值",
+	"处理另一个文件",
+	"排队中", "查询中", "已完成", "失败",
+	"%s: %d / %d 行，失败 %d 行，写入 %d 个单元格",
+}
+
+// messages 返回界面文字在当前语言下的译文
+func (ui *UI) messages(w http.ResponseWriter, r *http.Request) {
+	messages := make(map[string]string, len(uiMessages))
+	for _, key := range uiMessages {
+		messages[key] = i18n.T(key)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"lang": i18n.Current().String(), "messages": messages})
+}
+
+func (ui *UI) create(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		writeError(w, http.StatusBadRequest, i18n.Errorf("读取上传文件失败: %v", err))
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, i18n.Errorf("缺少上传文件: %v", err))
+		return
+	}
+	defer file.Close()
+	if !strings.EqualFold(filepath.Ext(header.Filename), ".xlsx") {
+		writeError(w, http.StatusBadRequest, i18n.Errorf("只支持 .xlsx 文件: %s", header.Filename))
+		return
+	}
+
+	columns, err := selectedColumns(r.MultipartForm.Value["column"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ui.cleanup()
+	j, err := ui.newJob(filepath.Base(header.Filename), columns, file)
+	if err != nil {
+		slog.Error("保存上传文件失败", "err", err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	select {
+	case ui.queue <- j:
+	default:
+		ui.remove(j.ID)
+		writeError(w, http.StatusServiceUnavailable, i18n.Errorf("排队的任务过多，请稍后再试"))
+		return
+	}
+	writeJSON(w, http.StatusAccepted, j.status(false))
+}
+
+// selectedColumns 校验选择的列，只接受 EnrichColumns 中的列
+func selectedColumns(values []string) ([]string, error) {
+	allowed := make(map[string]bool, len(app.EnrichColumns))
+	for _, c := range app.EnrichColumns {
+		allowed[c] = true
+	}
+	var columns []string
+	seen := make(map[string]bool)
+	for _, v := range values {
+		if !allowed[v] {
+			return nil, i18n.Errorf("不支持填充列: %s", v)
+		}
+		if !seen[v] {
+			seen[v] = true
+			columns = append(columns, v)
+		}
+	}
+	if len(columns) == 0 {
+		return nil, i18n.Errorf("请至少选择一列")
+	}
+	return columns, nil
+}
+
+// newJob 将上传文件保存到任务目录
+func (ui *UI) newJob(name string, columns []string, upload io.Reader) (*job, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	base := ui.Dir
+	if base == "" {
+		base = filepath.Join(os.TempDir(), "cas-web")
+	}
+	j := &job{
+		ID:      hex.EncodeToString(id),
+		Name:    name,
+		Columns: columns,
+		Created: time.Now(),
+		state:   StateQueued,
+	}
+	j.dir = filepath.Join(base, j.ID)
+	if err := os.MkdirAll(j.dir, 0o755); err != nil {
+		return nil, err
+	}
+	j.input = filepath.Join(j.dir, "upload.xlsx")
+	j.output = app.EnrichedPath(j.input)
+
+	out, err := os.Create(j.input)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(out, upload); err != nil {
+		out.Close()
+		os.RemoveAll(j.dir)
+		return nil, err
+	}
+	if err := out.Close(); err != nil {
+		os.RemoveAll(j.dir)
+		return nil, err
+	}
+
+	ui.mu.Lock()
+	ui.jobs[j.ID] = j
+	ui.mu.Unlock()
+	return j, nil
+}
+
+// cleanup 删除完成超过保留时间的任务
+func (ui *UI) cleanup() {
+	ui.mu.Lock()
+	var expired []string
+	for id, j := range ui.jobs {
+		j.mu.Lock()
+		if !j.finished.IsZero() && time.Since(j.finished) > jobRetention {
+			expired = append(expired, id)
+		}
+		j.mu.Unlock()
+	}
+	ui.mu.Unlock()
+	for _, id := range expired {
+		ui.remove(id)
+	}
+}
+
+func (ui *UI) remove(id string) {
+	ui.mu.Lock()
+	j, ok := ui.jobs[id]
+	delete(ui.jobs, id)
+	ui.mu.Unlock()
+	if ok {
+		os.RemoveAll(j.dir)
+	}
+}
+
+// run 执行填充任务，结果写入副本，上传的文件保持不变
+func (ui *UI) run(j *job) {
+	j.set(func() { j.state = StateRunning })
+	fail := func(err error) {
+		slog.Error("任务失败", "job", j.ID, "file", j.Name, "err", err)
+		j.set(func() { j.state, j.err, j.finished = StateFailed, err.Error(), time.Now() })
+	}
+
+	processor := &app.ExcelProcessor{FilePath: j.input}
+	sheet, headers, records, err := processor.HeadersAndRecords()
+	if err != nil {
+		fail(err)
+		return
+	}
+	if err := app.CopyFile(j.input, j.output); err != nil {
+		fail(i18n.Errorf("创建输出文件失败: %v", err))
+		return
+	}
+
+	rw := &app.RecordingWriter{ExcelWriter: &app.ExcelWriter{FilePath: j.output, Provenance: ui.Provenance}}
+	provider := ui.Provider
+	if ui.ProviderFor != nil {
+		provider = ui.ProviderFor(j.Columns)
+	}
+	_, err = app.Enrich(sheet, headers, records, provider, j.Columns, rw, func(p app.EnrichProgress) {
+		j.set(func() { j.progress = p })
+	})
+	if cerr := rw.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	slog.Info("任务完成", "job", j.ID, "file", j.Name, "written", len(rw.Changes), "conflicts", len(rw.Conflicts()))
+	j.set(func() {
+		j.state, j.finished = StateDone, time.Now()
+		j.changes, j.conflicts = rw.Changes, rw.Conflicts()
+	})
+}
+
+func (j *job) set(f func()) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	f()
+}
+
+// status 返回任务状态，diff 为 true 时包含差异
+func (j *job) status(diff bool) jobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	s := jobStatus{
+		ID:       j.ID,
+		Name:     j.Name,
+		Columns:  j.Columns,
+		State:    j.state,
+		Progress: j.progress,
+		Error:    j.err,
+	}
+	if diff {
+		s.Changes = cellChanges(j.changes)
+		s.Conflicts = cellChanges(j.conflicts)
+	}
+	return s
+}
+
+func cellChanges(updates []app.CellUpdate) []cellChange {
+	changes := make([]cellChange, len(updates))
+	for i, u := range updates {
+		changes[i] = cellChange{Row: u.Row, Column: u.Column, Old: u.OldValue, New: u.NewValue, Source: u.Source, Provider: u.Provider}
+	}
+	return changes
+}
+
+func (ui *UI) job(w http.ResponseWriter, r *http.Request) *job {
+	ui.mu.Lock()
+	j, ok := ui.jobs[r.PathValue("id")]
+	ui.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, i18n.Errorf("任务不存在或已过期"))
+		return nil
+	}
+	return j
+}
+
+func (ui *UI) status(w http.ResponseWriter, r *http.Request) {
+	if j := ui.job(w, r); j != nil {
+		writeJSON(w, http.StatusOK, j.status(true))
+	}
+}
+
+func (ui *UI) download(w http.ResponseWriter, r *http.Request) {
+	j := ui.job(w, r)
+	if j == nil {
+		return
+	}
+	if s := j.status(false); s.State != StateDone {
+		writeError(w, http.StatusConflict, i18n.Errorf("任务尚未完成: %s", s.State))
+		return
+	}
+	name := app.EnrichedPath(j.Name)
+	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename*=UTF-8''%s", pathEscape(name)))
+	http.ServeFile(w, r, j.output)
+}
+
+// pathEscape 按 RFC 5987 编码下载文件名
+func pathEscape(s string) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("!#$&+-.^_`|~", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		slog.Error("写入响应失败", "err", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,265 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"cas.mod/internal/app"
+	"cas.mod/internal/i18n"
+	"cas.mod/internal/standin"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/language"
+)
+
+// workbook 生成只有常用名称、CAS号、化学式三列的工作表
+func workbook(t *testing.T) []byte {
+	t.Helper()
+	return namedWorkbook(t, "Sheet1")
+}
+
+// namedWorkbook 与 workbook 相同，第一个工作表名为 sheet
+func namedWorkbook(t *testing.T, sheet string) []byte {
+	t.Helper()
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		t.Fatal(err)
+	}
+	rows := [][]interface{}{
+		{"常用名称", "CAS号", "化学式"},
+		{"硫酸", "7664-93-9", ""},
+		{"亮氨酸", "1002-16-0", "已有值"},
+		{"未知", "67-64-1", ""},
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func upload(t *testing.T, url, name string, data []byte, columns ...string) *http.Response {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, _ := mw.CreateFormFile("file", name)
+	fw.Write(data)
+	for _, c := range columns {
+		mw.WriteField("column", c)
+	}
+	mw.Close()
+	resp, err := http.Post(url+"/api/jobs", mw.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+// waitJob 等待任务结束，返回最后的状态
+func waitJob(t *testing.T, url, id string) jobStatus {
+	t.Helper()
+	var status jobStatus
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		resp, err := http.Get(url + "/api/jobs/" + id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		status = jobStatus{}
+		json.NewDecoder(resp.Body).Decode(&status)
+		resp.Body.Close()
+		if status.State == StateDone || status.State == StateFailed || time.Now().After(deadline) {
+			return status
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestUI(t *testing.T) {
+	site := standin.NewServer()
+	defer site.Close()
+
+	ui := &UI{
+		Provider: &app.IchemistryProvider{BaseURL: site.URL, Client: site.Client()},
+		Dir:      t.TempDir(),
+	}
+	ts := httptest.NewServer(ui.Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var page bytes.Buffer
+	page.ReadFrom(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(page.String(), "试剂信息填充") {
+		t.Errorf("首页内容不正确: %.200s", page.String())
+	}
+
+	resp = upload(t, ts.URL, "库存.xlsx", workbook(t), app.FieldFormula, app.FieldSMILES)
+	var created jobStatus
+	json.NewDecoder(resp.Body).Decode(&created)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("上传状态码 %d: %+v", resp.StatusCode, created)
+	}
+
+	status := waitJob(t, ts.URL, created.ID)
+	if status.State != StateDone {
+		t.Fatalf("任务状态 = %+v", status)
+	}
+	if p := status.Progress; p.Total != 3 || p.Done != 3 || p.Failed != 1 {
+		t.Errorf("进度 = %+v", p)
+	}
+	if len(status.Changes) == 0 || status.Changes[0] != (cellChange{Row: 2, Column: app.FieldFormula, New: "H2SO4", Source: site.URL + "/chemistry/7664-93-9.htm", Provider: "ichemistry"}) {
+		t.Errorf("差异 = %+v", status.Changes)
+	}
+	if len(status.Conflicts) != 1 || status.Conflicts[0].Old != "已有值" {
+		t.Errorf("冲突 = %+v", status.Conflicts)
+	}
+
+	resp, err = http.Get(ts.URL + "/api/jobs/" + created.ID + "/download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get("Content-Disposition"); !strings.Contains(got, "%E5%BA%93%E5%AD%98.enriched.xlsx") {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	f, err := excelize.OpenReader(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for cell, want := range map[string]string{"C2": "H2SO4", "C3": "已有值", "D1": app.FieldSMILES} {
+		if got, _ := f.GetCellValue("Sheet1", cell); got != want {
+			t.Errorf("%s = %q, want %q", cell, got, want)
+		}
+	}
+}
+
+func TestUIMessages(t *testing.T) {
+	defer i18n.Set(i18n.Current())
+	i18n.Set(language.English)
+
+	ts := httptest.NewServer((&UI{Dir: t.TempDir()}).Handler())
+	defer ts.Close()
+	resp, err := http.Get(ts.URL + "/api/messages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body struct {
+		Lang     string
+		Messages map[string]string
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	resp.Body.Close()
+	if body.Lang != "en" {
+		t.Errorf("lang = %q", body.Lang)
+	}
+	for _, key := range uiMessages {
+		if body.Messages[key] == key {
+			t.Errorf("缺少英文译文: %q", key)
+		}
+	}
+
+	// 页面和脚本中用到的文字都要列在 uiMessages 中
+	index, _ := static.ReadFile("static/index.html")
+	script, _ := static.ReadFile("static/app.js")
+	var used [][]byte
+	for _, m := range regexp.MustCompile(`data-i18n="([^"]+)"`).FindAllSubmatch(index, -1) {
+		used = append(used, m[1])
+	}
+	for _, m := range regexp.MustCompile(`\bt\('([^']+)'|: '(\p{Han}[^']*)',`).FindAllSubmatch(script, -1) {
+		used = append(used, append(m[1], m[2]...))
+	}
+	if len(used) < len(uiMessages) {
+		t.Errorf("只找到 %d 处界面文字", len(used))
+	}
+	for _, key := range used {
+		if !slices.Contains(uiMessages, string(key)) {
+			t.Errorf("%q 不在 uiMessages 中", key)
+		}
+	}
+}
+
+func TestUISheetName(t *testing.T) {
+	site := standin.NewServer()
+	defer site.Close()
+
+	ui := &UI{
+		Provider: &app.IchemistryProvider{BaseURL: site.URL, Client: site.Client()},
+		Dir:      t.TempDir(),
+	}
+	ts := httptest.NewServer(ui.Handler())
+	defer ts.Close()
+
+	// 第一个工作表不叫 Sheet1 时写回同一个工作表，不新建 Sheet1
+	resp := upload(t, ts.URL, "supplier.xlsx", namedWorkbook(t, "Sheet 1"), app.FieldFormula, app.FieldSMILES)
+	var created jobStatus
+	json.NewDecoder(resp.Body).Decode(&created)
+	resp.Body.Close()
+	if status := waitJob(t, ts.URL, created.ID); status.State != StateDone {
+		t.Fatalf("任务状态 = %+v", status)
+	}
+
+	resp, err := http.Get(ts.URL + "/api/jobs/" + created.ID + "/download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	f, err := excelize.OpenReader(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if sheets := f.GetSheetList(); len(sheets) != 1 || sheets[0] != "Sheet 1" {
+		t.Errorf("工作表 = %v", sheets)
+	}
+	for cell, want := range map[string]string{"C2": "H2SO4", "D1": app.FieldSMILES} {
+		if got, _ := f.GetCellValue("Sheet 1", cell); got != want {
+			t.Errorf("%s = %q, want %q", cell, got, want)
+		}
+	}
+}
+
+func TestUIRejects(t *testing.T) {
+	ui := &UI{Provider: &app.IchemistryProvider{}, Dir: t.TempDir()}
+	ts := httptest.NewServer(ui.Handler())
+	defer ts.Close()
+
+	for name, resp := range map[string]*http.Response{
+		"不支持的列":  upload(t, ts.URL, "a.xlsx", []byte("x"), "常用名称"),
+		"没有选择列":  upload(t, ts.URL, "a.xlsx", []byte("x")),
+		"不是xlsx": upload(t, ts.URL, "a.csv", []byte("x"), app.FieldFormula),
+	} {
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: 状态码 %d", name, resp.StatusCode)
+		}
+	}
+
+	resp, err := http.Get(ts.URL + "/api/jobs/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("不存在的任务: 状态码 %d", resp.StatusCode)
+	}
+}
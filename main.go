@@ -1,8 +1,11 @@
 package main
 
-import "cas.mod/cmd"
+import (
+	"os"
+
+	"cas.mod/cmd"
+)
 
 func main() {
-	cmd.ChemicalRun()
-	// cmd.DensityRun()
+	cmd.Execute(os.Args[1:])
 }